@@ -2,125 +2,333 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
-	"net"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+
 	apiserver "github.com/dcm-project/kubevirt-service-provider/internal/api_server"
 	"github.com/dcm-project/kubevirt-service-provider/internal/config"
 	"github.com/dcm-project/kubevirt-service-provider/internal/events"
 	handlers "github.com/dcm-project/kubevirt-service-provider/internal/handlers/v1alpha1"
 	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+	"github.com/dcm-project/kubevirt-service-provider/internal/logging"
 	"github.com/dcm-project/kubevirt-service-provider/internal/monitor"
 	"github.com/dcm-project/kubevirt-service-provider/internal/registration"
+	"github.com/dcm-project/kubevirt-service-provider/internal/shutdown"
+	"github.com/dcm-project/kubevirt-service-provider/internal/version"
 )
 
 func main() {
-	cfg, err := config.Load()
+	configFile := flag.String("config", os.Getenv(config.ConfigFileEnv),
+		"YAML file of settings keyed by environment variable name; the environment takes precedence")
+	flag.Parse()
+
+	cfg, err := config.LoadFile(*configFile)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	listener, err := net.Listen("tcp", cfg.ProviderConfig.ListenAddress)
+	logger, err := logging.New(cfg.LogConfig.Level, cfg.LogConfig.Format)
 	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	defer func() { _ = logger.Sync() }()
+	zap.ReplaceGlobals(logger)
+	// Route logs of dependencies still using the standard library through zap
+	defer zap.RedirectStdLog(logger)()
+
+	listener, err := apiserver.Listen(cfg.ProviderConfig.ListenAddress)
+	if err != nil {
+		zap.S().Fatalf("Failed to listen: %v", err)
 	}
 
 	// Create registrar (registration happens after server is ready)
-	registrar, err := registration.NewRegistrar(cfg.ProviderConfig, cfg.ServiceProviderManagerConfig)
+	registrar, err := registration.NewRegistrar(cfg.ProviderConfig, cfg.ServiceProviderManagerConfig,
+		registration.SetInitialBackoff(cfg.ServiceProviderManagerConfig.RegistrationBackoff),
+		registration.SetMaxAttempts(cfg.ServiceProviderManagerConfig.RegistrationMaxAttempts),
+	)
 	if err != nil {
-		log.Fatalf("Failed to create DCM registrar: %v", err)
+		zap.S().Fatalf("Failed to create DCM registrar: %v", err)
 	}
 
-	// Initialize KubeVirt client
-	kubevirtClient, err := kubevirt.NewClient(cfg.KubernetesConfig)
+	// Listen for shutdown signals before anything that may wait, such as the
+	// startup retry below
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// The handler and the monitor share the API connections of one factory
+	clientFactory, err := kubevirt.NewClientFactory(cfg.KubernetesConfig)
 	if err != nil {
-		log.Fatalf("Failed to create KubeVirt client: %v", err)
+		zap.S().Fatalf("Failed to create KubeVirt client factory: %v", err)
 	}
 
-	// Initialize mapper
-	mapper := kubevirt.NewMapper(cfg.KubernetesConfig.Namespace)
+	// Initialize KubeVirt client, optionally answering health probes while it
+	// is retried so the process is not reported dead during a cluster blip
+	var stopStarting func()
+	if cfg.KubernetesConfig.StartupDegraded {
+		stopStarting, err = apiserver.ServeStarting(listener, "Waiting for the KubeVirt client")
+		if err != nil {
+			zap.S().Warnw("Cannot serve health probes during startup", "error", err)
+		}
+	}
+	kubevirtClient, err := kubevirt.NewClientWithRetry(ctx, clientFactory, cfg.KubernetesConfig.Namespace,
+		cfg.KubernetesConfig.StartupAttempts, cfg.KubernetesConfig.StartupBackoff)
+	if stopStarting != nil {
+		stopStarting()
+	}
+	if err != nil && ctx.Err() != nil {
+		zap.S().Info("Shutdown signal received while creating the KubeVirt client")
+		return
+	}
+	if err != nil {
+		zap.S().Fatalf("Failed to create KubeVirt client: %v", err)
+	}
+
+	// Initialize mapper from settings config.Validate has already checked
+	k := cfg.KubernetesConfig
+	storageGranularity := must(resource.ParseQuantity(k.StorageGranularity))
+	var minBootDiskCapacity resource.Quantity
+	if k.MinBootDiskCapacity != "" {
+		minBootDiskCapacity = must(resource.ParseQuantity(k.MinBootDiskCapacity))
+	}
+	passthroughMigrationPolicy := must(kubevirt.ParsePassthroughMigrationPolicy(k.PassthroughMigrationPolicy))
+	sshKeyPropagation := must(kubevirt.ParseSSHKeyPropagation(k.SSHKeyPropagation))
+	sshKeySecretLayout := must(kubevirt.ParseSSHKeySecretLayout(k.SSHKeySecretLayout))
+	serialChannels := must(kubevirt.ParseSerialChannels(k.SerialChannels))
+	interfaceModels := must(kubevirt.ParseInterfaceModels(k.InterfaceModels))
+	portServiceType := must(kubevirt.ParseServiceType(k.PortServiceType))
+	runStrategy := must(kubevirt.ParseRunStrategy(k.RunStrategy))
+	tpmMode := must(kubevirt.ParseTPMMode(k.TPM))
+	var cloudInitBase map[string]interface{}
+	if cfg.KubernetesConfig.CloudInitBaseFile != "" {
+		data, err := os.ReadFile(cfg.KubernetesConfig.CloudInitBaseFile)
+		if err != nil {
+			zap.S().Fatalf("Failed to read cloud-init base: %v", err)
+		}
+		if cloudInitBase, err = kubevirt.ParseCloudInitBase(data); err != nil {
+			zap.S().Fatalf("Invalid cloud-init base %s: %v", cfg.KubernetesConfig.CloudInitBaseFile, err)
+		}
+	}
+	mapper := kubevirt.NewMapper(cfg.KubernetesConfig.Namespace,
+		kubevirt.SetStorageGranularity(storageGranularity),
+		kubevirt.SetCloudInitFromSecret(cfg.KubernetesConfig.CloudInitFromSecret),
+		kubevirt.SetCloudInitBase(cloudInitBase),
+		kubevirt.SetPassthroughMigrationPolicy(passthroughMigrationPolicy),
+		kubevirt.SetSSHKeyPropagation(sshKeyPropagation),
+		kubevirt.SetDefaultGuestOS(cfg.KubernetesConfig.DefaultGuestOS),
+		kubevirt.SetRequireGuestOS(cfg.KubernetesConfig.RequireGuestOS),
+		kubevirt.SetRunStrategy(runStrategy),
+		kubevirt.SetMachineType(cfg.KubernetesConfig.MachineType),
+		kubevirt.SetScratchDiskRatio(cfg.KubernetesConfig.ScratchDiskRatio),
+		kubevirt.SetMemoryRequestRatio(cfg.KubernetesConfig.MemoryRequestRatio),
+		kubevirt.SetSubdomain(cfg.KubernetesConfig.Subdomain),
+		kubevirt.SetSchedulerName(cfg.KubernetesConfig.SchedulerName),
+		kubevirt.SetMinBootDiskCapacity(minBootDiskCapacity, cfg.KubernetesConfig.StrictBootDiskCapacity),
+		kubevirt.SetSSHKeyLimits(cfg.KubernetesConfig.MaxSSHKeys, cfg.KubernetesConfig.MaxSSHKeyBytes),
+		kubevirt.SetSSHKeySecretLayout(sshKeySecretLayout),
+		kubevirt.SetPortServiceType(portServiceType),
+		kubevirt.SetSSHTargetPort(int32(cfg.KubernetesConfig.SSHTargetPort)),
+		kubevirt.SetAccessMetadata(cfg.KubernetesConfig.AccessLabels, cfg.KubernetesConfig.AccessAnnotations),
+		kubevirt.SetMaintenanceReady(cfg.KubernetesConfig.MaintenanceReady),
+		kubevirt.SetSerialChannels(serialChannels),
+		kubevirt.SetInterfaceModels(interfaceModels),
+		kubevirt.SetTPM(tpmMode, cfg.KubernetesConfig.VMStateStorageClass),
+		kubevirt.SetNodePool(kubevirt.NodePool{
+			RuntimeClass: cfg.KubernetesConfig.NodePoolRuntimeClass,
+			NodeSelector: cfg.KubernetesConfig.NodePoolSelector,
+			Resources:    cfg.KubernetesConfig.NodePoolResources,
+		}),
+	)
 
 	// Initialize event monitoring if enabled
+	var publisher events.Publisher
 	var monitorService *monitor.Service
 	if cfg.EventConfig.Enabled {
-		log.Printf("Initializing event monitoring service")
+		zap.S().Info("Initializing event monitoring service")
 
-		// Initialize NATS publisher
+		// Initialize the event publisher for the configured backend. Events are
+		// attributed to this provider instance unless a source is configured.
+		eventSource := cfg.EventConfig.Source
+		if eventSource == "" {
+			eventSource = "kubevirt." + cfg.ProviderConfig.ID
+		}
 		publisherConfig := events.PublisherConfig{
-			NATSURL:      cfg.NATSConfig.URL,
-			Subject:      cfg.NATSConfig.Subject,
-			MaxReconnect: cfg.NATSConfig.MaxReconnect,
+			Backend: cfg.EventConfig.Backend,
+			Source:  eventSource,
+			NATS: events.NATSPublisherConfig{
+				NATSURL:          cfg.NATSConfig.URL,
+				Subject:          cfg.NATSConfig.Subject,
+				JetStream:        cfg.NATSConfig.JetStream,
+				Stream:           cfg.NATSConfig.Stream,
+				Timeout:          cfg.NATSConfig.Timeout,
+				BufferSize:       cfg.NATSConfig.BufferSize,
+				TLS:              natsTLSConfig(cfg.NATSConfig),
+				User:             cfg.NATSConfig.User,
+				Password:         cfg.NATSConfig.Password,
+				Token:            cfg.NATSConfig.Token,
+				CredsFile:        cfg.NATSConfig.CredsFile,
+				MaxReconnect:     cfg.NATSConfig.MaxReconnect,
+				ReconnectWait:    cfg.NATSConfig.ReconnectWait,
+				MaxReconnectWait: cfg.NATSConfig.MaxReconnectWait,
+			},
+			Kafka: events.KafkaPublisherConfig{
+				RESTProxyURL: cfg.KafkaConfig.RESTProxyURL,
+				Token:        cfg.KafkaConfig.RESTProxyToken,
+				Topic:        cfg.KafkaConfig.Topic,
+				Timeout:      cfg.KafkaConfig.Timeout,
+				MaxAttempts:  cfg.KafkaConfig.MaxAttempts,
+				RetryBackoff: cfg.KafkaConfig.RetryBackoff,
+			},
 		}
-		publisher, err := events.NewPublisher(publisherConfig)
+		publisher, err = events.NewPublisher(publisherConfig)
 		if err != nil {
-			log.Fatalf("Failed to create event publisher: %v", err)
+			zap.S().Fatalf("Failed to create event publisher: %v", err)
 		}
 
 		// Initialize monitoring service
+		if _, err := labels.Parse(cfg.EventConfig.LabelSelector); err != nil {
+			zap.S().Fatalf("Invalid event label selector: %v", err)
+		}
 		monitorConfig := monitor.MonitorConfig{
-			Namespace:    cfg.KubernetesConfig.Namespace,
-			ResyncPeriod: cfg.EventConfig.ResyncPeriod,
+			Namespace:             cfg.KubernetesConfig.Namespace,
+			ResyncPeriod:          cfg.EventConfig.ResyncPeriod,
+			Workers:               cfg.EventConfig.Workers,
+			LabelSelector:         cfg.EventConfig.LabelSelector,
+			MaxTrackedVMs:         cfg.EventConfig.MaxTrackedVMs,
+			ReportEndpoints:       cfg.EventConfig.ReportEndpoints,
+			PersistConnectionInfo: cfg.EventConfig.PersistConnectionInfo,
+			ReadyEvents:           cfg.EventConfig.ReadyEvents,
 		}
-		monitorService = monitor.NewMonitorService(kubevirtClient.DynamicClient(), publisher, monitorConfig)
+		monitorService = monitor.NewMonitorService(clientFactory.DynamicClient(), publisher, monitorConfig)
+
+		zap.S().Info("Event monitoring service initialized")
+	}
 
-		log.Printf("Event monitoring service initialized")
+	policy, err := kubevirt.NewPolicy(cfg.PolicyConfig)
+	if err != nil {
+		zap.S().Fatalf("Invalid create policy: %v", err)
 	}
 
 	// Create handler with dependencies
-	handler := handlers.NewKubevirtHandler(kubevirtClient, mapper)
+	handlerOpts := []handlers.HandlerOption{
+		handlers.SetPolicy(policy),
+		handlers.SetCapabilities(kubevirt.NewCapabilities(mapper, policy)),
+		handlers.SetAllowDuplicateHostnames(cfg.KubernetesConfig.AllowDuplicateHostnames),
+	}
+	if cfg.KubernetesConfig.ProvenanceAnnotations {
+		handlerOpts = append(handlerOpts, handlers.SetProvenance(&kubevirt.Provenance{
+			ProviderVersion: version.Version,
+			SchemaVersion:   cfg.ProviderConfig.SchemaVersion,
+		}))
+	}
+	handler := handlers.NewKubevirtHandler(kubevirtClient, mapper, handlerOpts...)
 
 	srv := apiserver.New(cfg, listener, handler).WithOnReady(func(ctx context.Context) {
 		registrar.Start(ctx)
+		registrar.StartHeartbeat(ctx, cfg.ProviderConfig.HeartbeatInterval)
 	})
 
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
+	// Each stage gets its own context so that shutdown can stop them in order
+	serverCtx, stopServer := context.WithCancel(context.Background())
+	defer stopServer()
+	watchersCtx, stopWatchers := context.WithCancel(context.Background())
+	defer stopWatchers()
+
+	// Start the VM lookup cache; lookups fall back to live lists until it syncs
+	go func() {
+		if err := kubevirtClient.StartVMCache(watchersCtx); err != nil {
+			zap.S().Errorf("VM cache error: %v", err)
+		}
+	}()
 
 	// Start monitoring service if enabled
-	var wg sync.WaitGroup
+	monitorDone := make(chan struct{})
 	if monitorService != nil {
-		wg.Add(1)
 		go func() {
-			defer wg.Done()
-			log.Printf("Starting VM monitoring service")
-			if err := monitorService.Run(ctx); err != nil {
-				log.Printf("Monitoring service error: %v", err)
+			defer close(monitorDone)
+			zap.S().Info("Starting VM monitoring service")
+			if err := monitorService.Run(watchersCtx); err != nil {
+				zap.S().Errorf("Monitoring service error: %v", err)
 			}
 		}()
+	} else {
+		close(monitorDone)
 	}
 
-	log.Printf("Starting server on %s", listener.Addr().String())
+	zap.S().Infof("Starting server on %s", listener.Addr().String())
 
 	// Start server
-	wg.Add(1)
+	serverDone := make(chan struct{})
 	go func() {
-		defer wg.Done()
-		if err := srv.Run(ctx); err != nil {
-			log.Printf("Server error: %v", err)
+		defer close(serverDone)
+		if err := srv.Run(serverCtx); err != nil {
+			zap.S().Errorf("Server error: %v", err)
 		}
 	}()
 
 	// Wait for shutdown signal
-	<-ctx.Done()
-	log.Printf("Shutdown signal received, waiting for services to stop...")
+	select {
+	case <-ctx.Done():
+		zap.S().Info("Shutdown signal received, draining services...")
+	case <-serverDone:
+		zap.S().Warn("Server stopped unexpectedly, shutting down...")
+	}
 
-	// Wait for all services to stop gracefully
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	// Deregister first so DCM stops routing requests here, then stop accepting
+	// requests and finish in-flight ones, then stop the watchers that produce
+	// events, and only then close the event publisher
+	steps := []shutdown.Step{
+		{Name: "DCM registration", Stop: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, deregisterTimeout)
+			defer cancel()
+			return registrar.Deregister(ctx)
+		}},
+		{Name: "HTTP server", Stop: shutdown.WaitFor(stopServer, serverDone)},
+		{Name: "watchers", Stop: shutdown.WaitFor(stopWatchers, monitorDone)},
+	}
+	if publisher != nil {
+		steps = append(steps, shutdown.Step{Name: "event publisher", Stop: func(context.Context) error {
+			return publisher.Close()
+		}})
+	}
 
-	// Wait up to 10 seconds for graceful shutdown
-	select {
-	case <-done:
-		log.Printf("All services stopped gracefully")
-	case <-time.After(10 * time.Second):
-		log.Printf("Shutdown timeout exceeded")
+	if err := shutdown.Drain(cfg.ProviderConfig.ShutdownTimeout, steps...); err != nil {
+		zap.S().Errorf("Shutdown completed with errors: %v", err)
+		return
+	}
+	zap.S().Info("All services stopped gracefully")
+}
+
+// deregisterTimeout bounds the deregistration request on shutdown so that an
+// unreachable Service Provider Manager does not eat the drain budget
+const deregisterTimeout = 5 * time.Second
+
+// natsTLSConfig returns the TLS settings of the NATS connection, or nil when
+// none are configured
+func natsTLSConfig(cfg *config.NATSConfig) *events.NATSTLSConfig {
+	if cfg.TLSCAFile == "" && cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" && !cfg.TLSInsecureSkipVerify {
+		return nil
+	}
+	return &events.NATSTLSConfig{
+		CAFile:             cfg.TLSCAFile,
+		CertFile:           cfg.TLSCertFile,
+		KeyFile:            cfg.TLSKeyFile,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+}
+
+// must returns v, exiting on err; it unwraps parsed settings that
+// config.Validate has already checked
+func must[T any](v T, err error) T {
+	if err != nil {
+		zap.S().Fatalf("Invalid configuration: %v", err)
 	}
+	return v
 }
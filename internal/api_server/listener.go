@@ -0,0 +1,65 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// unixScheme prefixes listen addresses naming a unix domain socket
+const unixScheme = "unix://"
+
+// socketFileMode lets the owner and group of the process connect to the socket
+const socketFileMode = 0o660
+
+const staleSocketDialTimeout = time.Second
+
+// Listen listens on a TCP host:port, or on a unix domain socket when the
+// address has the form unix:///path/to.sock. A socket file left behind by a
+// previous run is replaced; the file is removed when the listener is closed.
+func Listen(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, unixScheme)
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	if path == "" {
+		return nil, fmt.Errorf("invalid listen address %q: missing socket path", address)
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketFileMode); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to set permissions of socket %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// removeStaleSocket removes a socket file nothing is listening on. Other files
+// and sockets still in use are left alone.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("socket %s is already in use", path)
+	}
+	return os.Remove(path)
+}
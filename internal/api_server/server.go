@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"time"
@@ -16,14 +15,21 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	nethttpmiddleware "github.com/oapi-codegen/nethttp-middleware"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
 )
 
-const gracefulShutdownTimeout = 5 * time.Second
+const defaultShutdownTimeout = 10 * time.Second
 
 const readinessProbeTimeout = 5 * time.Second
 
 const readinessProbeInterval = 50 * time.Millisecond
 
+// livenessPath answers 200 whenever the server is serving requests. Unlike the
+// health endpoint it does not depend on the cluster, so a failing namespace
+// check cannot keep the server from reporting itself ready to DCM.
+const livenessPath = "/livez"
+
 type Server struct {
 	cfg      *config.Config
 	listener net.Listener
@@ -41,7 +47,7 @@ func New(cfg *config.Config, listener net.Listener, handler server.StrictServerI
 
 // WithOnReady registers a callback invoked once the server is confirmed to be
 // serving HTTP requests. The server verifies readiness by polling its own
-// health endpoint before calling fn.
+// liveness endpoint before calling fn.
 func (s *Server) WithOnReady(fn func(context.Context)) *Server {
 	s.onReady = fn
 	return s
@@ -49,6 +55,7 @@ func (s *Server) WithOnReady(fn func(context.Context)) *Server {
 
 func (s *Server) Run(ctx context.Context) error {
 	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 
@@ -72,7 +79,7 @@ func (s *Server) Run(ctx context.Context) error {
 		},
 		SilenceServersWarning: true,
 		ErrorHandler: func(w http.ResponseWriter, message string, statusCode int) {
-			log.Printf("OpenAPI validation error (status %d): %s", statusCode, message)
+			zap.S().Infow("OpenAPI validation error", "status", statusCode, "message", message)
 			http.Error(w, message, statusCode)
 		},
 	}))
@@ -83,7 +90,16 @@ func (s *Server) Run(ctx context.Context) error {
 		baseURL,
 	)
 
-	srv := http.Server{Handler: router}
+	// Metrics and liveness are served outside the OpenAPI router so the request
+	// validator does not reject the undocumented paths.
+	root := chi.NewRouter()
+	root.Handle("/metrics", promhttp.Handler())
+	root.Get(livenessPath, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	root.Mount("/", router)
+
+	srv := http.Server{Handler: root}
 
 	serveCh := make(chan error, 1)
 	go func() {
@@ -94,13 +110,13 @@ func (s *Server) Run(ctx context.Context) error {
 	}()
 
 	if s.onReady != nil {
-		if err := s.waitForReady(ctx, s.listener.Addr().String()); err != nil {
-			log.Printf("Readiness probe failed, skipping onReady callback: %v", err)
+		if err := s.waitForReady(ctx, s.listener.Addr()); err != nil {
+			zap.S().Errorw("Readiness probe failed, skipping onReady callback", "error", err)
 		} else {
 			func() {
 				defer func() {
 					if r := recover(); r != nil {
-						log.Printf("onReady callback panicked: %v", r)
+						zap.S().Errorw("onReady callback panicked", "panic", r)
 					}
 				}()
 				s.onReady(ctx)
@@ -116,19 +132,38 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
-	ctxTimeout, cancel := context.WithTimeout(context.Background(), gracefulShutdownTimeout)
+	ctxTimeout, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout())
 	defer cancel()
 	srv.SetKeepAlivesEnabled(false)
 	if err := srv.Shutdown(ctxTimeout); err != nil {
-		log.Printf("Error during server shutdown: %v", err)
+		zap.S().Errorw("Error during server shutdown", "error", err)
 	}
 
 	return nil
 }
 
-func (s *Server) waitForReady(ctx context.Context, addr string) error {
-	url := fmt.Sprintf("http://%s/api/v1alpha1/vms/health", addr)
-	client := &http.Client{Timeout: 1 * time.Second}
+// shutdownTimeout returns the configured time allowed for in-flight requests to
+// complete once the server stops accepting new connections.
+func (s *Server) shutdownTimeout() time.Duration {
+	if s.cfg != nil && s.cfg.ProviderConfig != nil && s.cfg.ProviderConfig.ShutdownTimeout > 0 {
+		return s.cfg.ProviderConfig.ShutdownTimeout
+	}
+	return defaultShutdownTimeout
+}
+
+func (s *Server) waitForReady(ctx context.Context, addr net.Addr) error {
+	host := addr.String()
+	transport := &http.Transport{}
+	defer transport.CloseIdleConnections()
+	if addr.Network() == "unix" {
+		host = "localhost"
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", addr.String())
+		}
+	}
+	url := fmt.Sprintf("http://%s%s", host, livenessPath)
+	client := &http.Client{Timeout: 1 * time.Second, Transport: transport}
 
 	deadline := time.NewTimer(readinessProbeTimeout)
 	defer deadline.Stop()
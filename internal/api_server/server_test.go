@@ -0,0 +1,261 @@
+package apiserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/api/server"
+	"github.com/dcm-project/kubevirt-service-provider/internal/config"
+)
+
+func TestAPIServer(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "API Server Suite")
+}
+
+// blockingHandler implements server.StrictServerInterface. GetHealth blocks
+// until release is closed so tests can hold a request in flight.
+type blockingHandler struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (h *blockingHandler) ListVMs(_ context.Context, _ server.ListVMsRequestObject) (server.ListVMsResponseObject, error) {
+	return server.ListVMs200JSONResponse{}, nil
+}
+
+func (h *blockingHandler) CreateVM(_ context.Context, _ server.CreateVMRequestObject) (server.CreateVMResponseObject, error) {
+	return server.CreateVM201JSONResponse{}, nil
+}
+
+func (h *blockingHandler) GetHealth(_ context.Context, _ server.GetHealthRequestObject) (server.GetHealthResponseObject, error) {
+	close(h.entered)
+	<-h.release
+	status := "ok"
+	return server.GetHealth200JSONResponse{Status: &status}, nil
+}
+
+func (h *blockingHandler) GetCapabilities(_ context.Context, _ server.GetCapabilitiesRequestObject) (server.GetCapabilitiesResponseObject, error) {
+	return server.GetCapabilities200JSONResponse{}, nil
+}
+
+func (h *blockingHandler) DeleteVM(_ context.Context, _ server.DeleteVMRequestObject) (server.DeleteVMResponseObject, error) {
+	return server.DeleteVM204Response{}, nil
+}
+
+func (h *blockingHandler) GetVM(_ context.Context, _ server.GetVMRequestObject) (server.GetVMResponseObject, error) {
+	return server.GetVM200JSONResponse{}, nil
+}
+
+func (h *blockingHandler) GetVMUsage(_ context.Context, _ server.GetVMUsageRequestObject) (server.GetVMUsageResponseObject, error) {
+	return server.GetVMUsage200JSONResponse{}, nil
+}
+
+func (h *blockingHandler) StartVM(_ context.Context, _ server.StartVMRequestObject) (server.StartVMResponseObject, error) {
+	return server.StartVM204Response{}, nil
+}
+
+func (h *blockingHandler) StopVM(_ context.Context, _ server.StopVMRequestObject) (server.StopVMResponseObject, error) {
+	return server.StopVM204Response{}, nil
+}
+
+func (h *blockingHandler) RestartVM(_ context.Context, _ server.RestartVMRequestObject) (server.RestartVMResponseObject, error) {
+	return server.RestartVM204Response{}, nil
+}
+
+func (h *blockingHandler) ResizeVM(_ context.Context, _ server.ResizeVMRequestObject) (server.ResizeVMResponseObject, error) {
+	return server.ResizeVM200JSONResponse{}, nil
+}
+
+// unhealthyHandler answers health checks with 503, as the handler does while
+// the target namespace cannot be reached.
+type unhealthyHandler struct {
+	blockingHandler
+}
+
+func (h *unhealthyHandler) GetHealth(_ context.Context, _ server.GetHealthRequestObject) (server.GetHealthResponseObject, error) {
+	return server.GetHealth503ApplicationProblemPlusJSONResponse{}, nil
+}
+
+var _ = Describe("Server", func() {
+	Describe("Run", func() {
+		It("should let in-flight requests complete within the shutdown timeout", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			handler := &blockingHandler{entered: make(chan struct{}), release: make(chan struct{})}
+			cfg := &config.Config{ProviderConfig: &config.ProviderConfig{ShutdownTimeout: 2 * time.Second}}
+			srv := New(cfg, listener, handler)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- srv.Run(ctx)
+			}()
+
+			statusCh := make(chan int, 1)
+			go func() {
+				defer GinkgoRecover()
+				resp, err := http.Get("http://" + listener.Addr().String() + "/api/v1alpha1/vms/health")
+				Expect(err).NotTo(HaveOccurred())
+				_ = resp.Body.Close()
+				statusCh <- resp.StatusCode
+			}()
+
+			Eventually(handler.entered).Should(BeClosed())
+			cancel()
+			Consistently(runDone, 100*time.Millisecond).ShouldNot(Receive())
+
+			close(handler.release)
+			Eventually(statusCh).Should(Receive(Equal(http.StatusOK)))
+			Eventually(runDone).Should(Receive(BeNil()))
+		})
+
+		It("should call onReady while the namespace health check is failing", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			ready := make(chan struct{})
+			srv := New(&config.Config{}, listener, &unhealthyHandler{}).WithOnReady(func(context.Context) { close(ready) })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- srv.Run(ctx)
+			}()
+			Eventually(ready).Should(BeClosed())
+
+			resp, err := http.Get("http://" + listener.Addr().String() + "/api/v1alpha1/vms/health")
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+
+			cancel()
+			Eventually(runDone).Should(Receive(BeNil()))
+		})
+
+		It("should use the default shutdown timeout when none is configured", func() {
+			srv := New(&config.Config{}, nil, nil)
+			Expect(srv.shutdownTimeout()).To(Equal(defaultShutdownTimeout))
+		})
+	})
+
+	Describe("ServeStarting", func() {
+		It("should answer with 503 until stopped and then hand the listener over", func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			url := "http://" + listener.Addr().String() + "/api/v1alpha1/vms/health"
+
+			stop, err := ServeStarting(listener, "Waiting for the KubeVirt client")
+			Expect(err).NotTo(HaveOccurred())
+			resp, err := http.Get(url)
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(resp.Header.Get("Content-Type")).To(Equal("application/problem+json"))
+			stop()
+
+			handler := &blockingHandler{entered: make(chan struct{}), release: make(chan struct{})}
+			close(handler.release)
+			ctx, cancel := context.WithCancel(context.Background())
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- New(&config.Config{}, listener, handler).Run(ctx)
+			}()
+
+			client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+			resp, err = client.Get(url)
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			cancel()
+			Eventually(runDone).Should(Receive(BeNil()))
+		})
+	})
+
+	Describe("Listen", func() {
+		var socketPath string
+
+		BeforeEach(func() {
+			// Socket paths are limited to about 100 bytes, so avoid the long Ginkgo temp dirs
+			dir, err := os.MkdirTemp("", "kvsp")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, dir)
+			socketPath = filepath.Join(dir, "api.sock")
+		})
+
+		It("should serve requests over a unix socket and remove it on shutdown", func() {
+			listener, err := Listen("unix://" + socketPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			info, err := os.Stat(socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Mode() & os.ModeSocket).NotTo(BeZero())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(socketFileMode)))
+
+			handler := &blockingHandler{entered: make(chan struct{}), release: make(chan struct{})}
+			close(handler.release)
+			ready := make(chan struct{})
+			srv := New(&config.Config{}, listener, handler).WithOnReady(func(context.Context) { close(ready) })
+
+			ctx, cancel := context.WithCancel(context.Background())
+			runDone := make(chan error, 1)
+			go func() {
+				runDone <- srv.Run(ctx)
+			}()
+			Eventually(ready).Should(BeClosed())
+
+			client := &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socketPath)
+				},
+			}}
+			resp, err := client.Get("http://localhost/api/v1alpha1/vms")
+			Expect(err).NotTo(HaveOccurred())
+			_ = resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			cancel()
+			Eventually(runDone).Should(Receive(BeNil()))
+			_, err = os.Stat(socketPath)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+
+		It("should replace a socket left behind by a previous run", func() {
+			stale, err := net.Listen("unix", socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			stale.(*net.UnixListener).SetUnlinkOnClose(false)
+			Expect(stale.Close()).To(Succeed())
+
+			listener, err := Listen("unix://" + socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listener.Close()).To(Succeed())
+		})
+
+		It("should refuse a socket that is still in use", func() {
+			listener, err := Listen("unix://" + socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			defer listener.Close()
+
+			_, err = Listen("unix://" + socketPath)
+			Expect(err).To(MatchError(ContainSubstring("already in use")))
+		})
+
+		It("should refuse to replace a file that is not a socket", func() {
+			Expect(os.WriteFile(socketPath, []byte("data"), 0o600)).To(Succeed())
+
+			_, err := Listen("unix://" + socketPath)
+			Expect(err).To(MatchError(ContainSubstring("not a socket")))
+		})
+	})
+})
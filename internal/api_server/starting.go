@@ -0,0 +1,97 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/api/server"
+)
+
+// deadlineListener is implemented by TCP and unix domain socket listeners
+type deadlineListener interface {
+	net.Listener
+	SetDeadline(t time.Time) error
+}
+
+// ServeStarting answers every request on the listener, including health
+// probes, with 503 and the given detail while the provider is still setting up
+// its dependencies. The returned stop function waits for in-flight requests and
+// releases the listener, which stays open for the server that takes over.
+func ServeStarting(listener net.Listener, detail string) (stop func(), err error) {
+	dl, ok := listener.(deadlineListener)
+	if !ok {
+		return nil, fmt.Errorf("listener %T does not support handing over connections", listener)
+	}
+
+	status := http.StatusServiceUnavailable
+	body, err := json.Marshal(server.Error{
+		Type:   "about:blank",
+		Title:  "Service Unavailable",
+		Status: &status,
+		Detail: &detail,
+	})
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	})}
+
+	handOver := &handOverListener{deadlineListener: dl, done: make(chan struct{})}
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		if err := srv.Serve(handOver); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			zap.S().Errorw("Startup server error", "error", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			zap.S().Errorw("Error during startup server shutdown", "error", err)
+		}
+		<-served
+		_ = dl.SetDeadline(time.Time{})
+	}, nil
+}
+
+// handOverListener stops accepting connections when closed without closing
+// the underlying listener. Closing expires the listener's deadline to unblock
+// a pending Accept.
+type handOverListener struct {
+	deadlineListener
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *handOverListener) Accept() (net.Conn, error) {
+	conn, err := l.deadlineListener.Accept()
+	if err != nil {
+		select {
+		case <-l.done:
+			return nil, net.ErrClosed
+		default:
+		}
+	}
+	return conn, err
+}
+
+func (l *handOverListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		_ = l.deadlineListener.SetDeadline(time.Now())
+	})
+	return nil
+}
@@ -1,12 +1,14 @@
 package config
 
 import (
+	"os"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
 type ProviderConfig struct {
+	// ListenAddress is the TCP host:port to serve on, or unix:///path/to.sock for a unix domain socket
 	ListenAddress string `envconfig:"PROVIDER_LISTEN_ADDRESS" default:"0.0.0.0:8081"`
 	// Name is the name to register this provider as
 	Name string `envconfig:"PROVIDER_NAME" default:"kubevirt-provider"`
@@ -20,12 +22,34 @@ type ProviderConfig struct {
 	ID string `envconfig:"PROVIDER_ID" default:"c9243c71-5ae0-4ee2-8a28-a83b3cb38d98"`
 	// HTTPTimeout is the timeout for HTTP client requests
 	HTTPTimeout time.Duration `envconfig:"PROVIDER_HTTP_TIMEOUT" default:"30s"`
+	// ShutdownTimeout bounds the whole graceful shutdown, including draining in-flight requests
+	ShutdownTimeout time.Duration `envconfig:"PROVIDER_SHUTDOWN_TIMEOUT" default:"10s"`
+	// HeartbeatInterval is how often the provider re-registers to keep its entry fresh (0 disables)
+	HeartbeatInterval time.Duration `envconfig:"PROVIDER_HEARTBEAT_INTERVAL" default:"5m"`
+	// Operations are the operations advertised on registration (e.g. "CREATE,DELETE,READ"); empty omits them
+	Operations []string `envconfig:"PROVIDER_OPERATIONS"`
+	// Region is the region code advertised on registration
+	Region string `envconfig:"PROVIDER_REGION"`
+	// Zone is the availability zone advertised on registration
+	Zone string `envconfig:"PROVIDER_ZONE"`
 }
 
 // ServiceProviderManagerConfig holds configuration for registering with Service Provider Manager
 type ServiceProviderManagerConfig struct {
 	// Endpoint is the URL of the Service Manager API
 	Endpoint string `envconfig:"SERVICE_MANAGER_ENDPOINT" default:"http://localhost:8080/api/v1alpha1"`
+	// ConnectTimeout bounds establishing a connection, including the TLS handshake; requests as a whole are bounded by PROVIDER_HTTP_TIMEOUT
+	ConnectTimeout time.Duration `envconfig:"SERVICE_MANAGER_CONNECT_TIMEOUT" default:"5s"`
+	// KeepAlive is the TCP keep-alive period of connections (negative disables keep-alives)
+	KeepAlive time.Duration `envconfig:"SERVICE_MANAGER_KEEP_ALIVE" default:"30s"`
+	// IdleConnTimeout is how long an idle connection is kept for reuse
+	IdleConnTimeout time.Duration `envconfig:"SERVICE_MANAGER_IDLE_CONN_TIMEOUT" default:"90s"`
+	// MaxIdleConns is the number of idle connections kept for reuse
+	MaxIdleConns int `envconfig:"SERVICE_MANAGER_MAX_IDLE_CONNS" default:"2"`
+	// RegistrationBackoff is the initial delay between registration attempts, doubling up to a minute
+	RegistrationBackoff time.Duration `envconfig:"SERVICE_MANAGER_REGISTRATION_BACKOFF" default:"1s"`
+	// RegistrationMaxAttempts is the number of registration attempts before giving up (0 retries until shutdown)
+	RegistrationMaxAttempts int `envconfig:"SERVICE_MANAGER_REGISTRATION_MAX_ATTEMPTS" default:"0"`
 }
 
 // KubernetesConfig holds configuration for connecting to Kubernetes/KubeVirt
@@ -38,6 +62,88 @@ type KubernetesConfig struct {
 	Timeout time.Duration `envconfig:"KUBERNETES_TIMEOUT" default:"60s"`
 	// MaxRetries for failed operations
 	MaxRetries int `envconfig:"KUBERNETES_MAX_RETRIES" default:"3"`
+	// AccessAnnotations are extra annotations for the SSH key Secret and port Service of each VM
+	AccessAnnotations map[string]string `envconfig:"KUBERNETES_ACCESS_ANNOTATIONS"`
+	// AccessLabels are extra labels for the SSH key Secret and port Service of each VM
+	AccessLabels map[string]string `envconfig:"KUBERNETES_ACCESS_LABELS"`
+	// AllowDuplicateHostnames lets VMs share a hostname within a DNS subdomain instead of rejecting them with 409
+	AllowDuplicateHostnames bool `envconfig:"KUBERNETES_ALLOW_DUPLICATE_HOSTNAMES" default:"false"`
+	// CloudInitBaseFile is a cloud-config file merged into the cloud-init of every VM (empty disables it)
+	CloudInitBaseFile string `envconfig:"KUBERNETES_CLOUD_INIT_BASE_FILE"`
+	// CloudInitFromSecret stores cloud-init user data in a Secret instead of inlining it in the VM
+	CloudInitFromSecret bool `envconfig:"KUBERNETES_CLOUD_INIT_FROM_SECRET" default:"false"`
+	// CPUOverhead is the CPU KubeVirt's launcher pod adds to each VM, counted by capacity checks
+	CPUOverhead string `envconfig:"KUBERNETES_CPU_OVERHEAD" default:"100m"`
+	// DefaultGuestOS is the guest OS type used when a request omits it
+	DefaultGuestOS string `envconfig:"KUBERNETES_DEFAULT_GUEST_OS" default:"cirros"`
+	// GetCoalesceTTL is how long a VM fetched from the cluster is reused for repeated lookups (0 disables)
+	GetCoalesceTTL time.Duration `envconfig:"KUBERNETES_GET_COALESCE_TTL" default:"1s"`
+	// InterfaceModels are default NIC models per guest OS type (e.g. "windows:e1000e"); others use virtio
+	InterfaceModels map[string]string `envconfig:"KUBERNETES_INTERFACE_MODELS"`
+	// MachineType is the default emulated machine type of VMs (empty uses the cluster default)
+	MachineType string `envconfig:"KUBERNETES_MACHINE_TYPE" default:"q35"`
+	// MaintenanceReady makes VMs live migrate off drained nodes, requiring ReadWriteMany persistent storage
+	MaintenanceReady bool `envconfig:"KUBERNETES_MAINTENANCE_READY" default:"false"`
+	// MaxSSHKeyBytes is the largest total size of the SSH public keys of a VM (0 disables the limit)
+	MaxSSHKeyBytes int `envconfig:"KUBERNETES_MAX_SSH_KEY_BYTES" default:"16384"`
+	// MaxSSHKeys is the largest number of SSH public keys a VM may carry (0 disables the limit)
+	MaxSSHKeys int `envconfig:"KUBERNETES_MAX_SSH_KEYS" default:"16"`
+	// MemoryOverhead is the memory KubeVirt's launcher pod adds to each VM, counted by capacity checks
+	MemoryOverhead string `envconfig:"KUBERNETES_MEMORY_OVERHEAD" default:"256Mi"`
+	// MemoryRequestRatio is the fraction of the guest memory VM launcher pods request (below 1 overcommits memory)
+	MemoryRequestRatio float64 `envconfig:"KUBERNETES_MEMORY_REQUEST_RATIO" default:"1"`
+	// MinBootDiskCapacity is the smallest capacity of a data volume boot disk (empty disables it)
+	MinBootDiskCapacity string `envconfig:"KUBERNETES_MIN_BOOT_DISK_CAPACITY"`
+	// NamespaceCheckTTL is how long a namespace access check result is cached by health probes
+	NamespaceCheckTTL time.Duration `envconfig:"KUBERNETES_NAMESPACE_CHECK_TTL" default:"30s"`
+	// NodePoolRuntimeClass names a RuntimeClass whose node pool VMs are scheduled onto
+	NodePoolRuntimeClass string `envconfig:"KUBERNETES_NODE_POOL_RUNTIME_CLASS"`
+	// NodePoolSelector is a node selector applied to VMs (e.g. "pool:vms,zone:a")
+	NodePoolSelector map[string]string `envconfig:"KUBERNETES_NODE_POOL_SELECTOR"`
+	// NodePoolResources are extended resources requested by each VM (e.g. "devices.kubevirt.io/kvm:1")
+	NodePoolResources map[string]string `envconfig:"KUBERNETES_NODE_POOL_RESOURCES"`
+	// PassthroughMigrationPolicy handles host-passthrough CPUs on VMs requesting live migration: warn, block or host-model
+	PassthroughMigrationPolicy string `envconfig:"KUBERNETES_PASSTHROUGH_MIGRATION_POLICY" default:"warn"`
+	// PortServiceType is the type of Services publishing guest ports: NodePort, LoadBalancer or ClusterIP
+	PortServiceType string `envconfig:"KUBERNETES_PORT_SERVICE_TYPE" default:"NodePort"`
+	// ProvenanceAnnotations annotates VMs with the request ID, provider version and schema version they were created with
+	ProvenanceAnnotations bool `envconfig:"KUBERNETES_PROVENANCE_ANNOTATIONS" default:"true"`
+	// RequireGuestOS rejects requests that omit the guest OS instead of using DefaultGuestOS
+	RequireGuestOS bool `envconfig:"KUBERNETES_REQUIRE_GUEST_OS" default:"false"`
+	// RunStrategy is the default run strategy of new VMs: Always or Manual
+	RunStrategy string `envconfig:"KUBERNETES_RUN_STRATEGY" default:"Always"`
+	// ScratchDiskRatio attaches a scratch disk sized as this multiple of the VM memory (0 disables it)
+	ScratchDiskRatio float64 `envconfig:"KUBERNETES_SCRATCH_DISK_RATIO" default:"0"`
+	// SchedulerName is the scheduler VM launcher pods are scheduled by (empty uses the default scheduler)
+	SchedulerName string `envconfig:"KUBERNETES_SCHEDULER_NAME"`
+	// SerialChannels are virtio-serial channels attached to VMs (e.g. "org.qemu.guest_agent.0")
+	SerialChannels []string `envconfig:"KUBERNETES_SERIAL_CHANNELS"`
+	// SSHKeyPropagation is the default method for injecting SSH keys: nocloud or qemu-guest-agent
+	SSHKeyPropagation string `envconfig:"KUBERNETES_SSH_KEY_PROPAGATION" default:"nocloud"`
+	// SSHKeySecretLayout stores multiple SSH keys joined under one Secret entry or separately: joined or separate
+	SSHKeySecretLayout string `envconfig:"KUBERNETES_SSH_KEY_SECRET_LAYOUT" default:"joined"`
+	// SSHTargetPort is the guest port SSH listens on, which exposed port 22 is forwarded to
+	SSHTargetPort int `envconfig:"KUBERNETES_SSH_TARGET_PORT" default:"22"`
+	// StartupAttempts is how many times connecting to the cluster is tried at startup
+	StartupAttempts int `envconfig:"KUBERNETES_STARTUP_ATTEMPTS" default:"5"`
+	// StartupBackoff is the delay before the first retry, doubled after each attempt
+	StartupBackoff time.Duration `envconfig:"KUBERNETES_STARTUP_BACKOFF" default:"1s"`
+	// StartupDegraded answers health probes with 503 while the KubeVirt client is retried
+	StartupDegraded bool `envconfig:"KUBERNETES_STARTUP_DEGRADED" default:"false"`
+	// StorageGranularity is the allocation granularity disk capacities are rounded up to
+	StorageGranularity string `envconfig:"KUBERNETES_STORAGE_GRANULARITY" default:"1Gi"`
+	// StrictBootDiskCapacity rejects boot disks below MinBootDiskCapacity instead of raising them
+	StrictBootDiskCapacity bool `envconfig:"KUBERNETES_STRICT_BOOT_DISK_CAPACITY" default:"false"`
+	// Subdomain places VMs in a DNS subdomain governed by a headless Service; empty disables it
+	Subdomain string `envconfig:"KUBERNETES_SUBDOMAIN"`
+	// TPM is the virtual TPM of VMs: none, ephemeral or persistent
+	TPM string `envconfig:"KUBERNETES_TPM" default:"none"`
+	// VMCacheEnabled serves VM lookups by DCM instance ID from an indexed informer cache
+	VMCacheEnabled bool `envconfig:"KUBERNETES_VM_CACHE_ENABLED" default:"true"`
+	// VMCacheResyncPeriod for the VM informer cache
+	VMCacheResyncPeriod time.Duration `envconfig:"KUBERNETES_VM_CACHE_RESYNC_PERIOD" default:"10m"`
+	// VMStateStorageClass is the vmStateStorageClass configured in KubeVirt, required for persistent TPMs
+	VMStateStorageClass string `envconfig:"KUBERNETES_VM_STATE_STORAGE_CLASS"`
 }
 
 // NATSConfig holds configuration for NATS connection
@@ -46,10 +152,34 @@ type NATSConfig struct {
 	URL string `envconfig:"NATS_URL" default:"nats://localhost:4222"`
 	// MaxReconnect attempts (-1 for unlimited)
 	MaxReconnect int `envconfig:"NATS_MAX_RECONNECT" default:"-1"`
-	// ReconnectWait time between reconnect attempts
-	ReconnectWait time.Duration `envconfig:"NATS_RECONNECT_WAIT" default:"2s"`
+	// ReconnectWait is the initial time between reconnect attempts, doubled after each failed round
+	ReconnectWait time.Duration `envconfig:"NATS_RECONNECT_WAIT" default:"1s"`
+	// MaxReconnectWait caps the time between reconnect attempts
+	MaxReconnectWait time.Duration `envconfig:"NATS_MAX_RECONNECT_WAIT" default:"30s"`
 	// Subject is the JetStream subject for VM events
 	Subject string `envconfig:"NATS_SUBJECT" default:"dcm.vm"`
+	// JetStream persists events and waits for their acknowledgement; disable it to publish on core NATS
+	JetStream bool `envconfig:"NATS_JETSTREAM" default:"true"`
+	// Stream is created or updated to capture Subject (empty expects an existing stream)
+	Stream string `envconfig:"NATS_STREAM"`
+	// Timeout bounds each publish, including the JetStream acknowledgement
+	Timeout time.Duration `envconfig:"NATS_TIMEOUT" default:"5s"`
+	// BufferSize is the number of VMs whose latest event is replayed after a disconnection (0 drops them)
+	BufferSize int `envconfig:"NATS_BUFFER_SIZE" default:"1024"`
+	// User and Password authenticate to the server with a username and password
+	User     string `envconfig:"NATS_USER"`
+	Password string `envconfig:"NATS_PASSWORD"`
+	// Token authenticates to the server with a token
+	Token string `envconfig:"NATS_TOKEN"`
+	// CredsFile authenticates to the server with a user JWT and nkey seed
+	CredsFile string `envconfig:"NATS_CREDS_FILE"`
+	// TLSCAFile is a PEM bundle of CAs to verify the server with; setting any TLS option enables TLS
+	TLSCAFile string `envconfig:"NATS_TLS_CA_FILE"`
+	// TLSCertFile and TLSKeyFile are the client certificate and key for mutual TLS
+	TLSCertFile string `envconfig:"NATS_TLS_CERT_FILE"`
+	TLSKeyFile  string `envconfig:"NATS_TLS_KEY_FILE"`
+	// TLSInsecureSkipVerify accepts any server certificate; for testing only
+	TLSInsecureSkipVerify bool `envconfig:"NATS_TLS_INSECURE_SKIP_VERIFY" default:"false"`
 }
 
 // EventConfig holds configuration for event monitoring
@@ -58,6 +188,61 @@ type EventConfig struct {
 	Enabled bool `envconfig:"EVENTS_ENABLED" default:"true"`
 	// ResyncPeriod for Kubernetes informers
 	ResyncPeriod time.Duration `envconfig:"EVENTS_RESYNC_PERIOD" default:"30m"`
+	// Workers is the number of VM events published concurrently
+	Workers int `envconfig:"EVENTS_WORKERS" default:"4"`
+	// Backend is the event bus VM events are published to: nats or kafka
+	Backend string `envconfig:"EVENTS_BACKEND" default:"nats"`
+	// LabelSelector restricts the monitored VMIs (empty selects the DCM-managed ones)
+	LabelSelector string `envconfig:"EVENTS_LABEL_SELECTOR"`
+	// Source is the CloudEvent source of published events (empty derives it from the provider ID)
+	Source string `envconfig:"EVENTS_SOURCE"`
+	// MaxTrackedVMs bounds the VMs whose last published phase is remembered to skip duplicates
+	MaxTrackedVMs int `envconfig:"EVENTS_MAX_TRACKED_VMS" default:"10000"`
+	// ReportEndpoints adds the VM IPs and published ports to the Running event of a VM, republished when they change
+	ReportEndpoints bool `envconfig:"EVENTS_REPORT_ENDPOINTS" default:"false"`
+	// PersistConnectionInfo annotates VMs with their IP and SSH access details once they are running;
+	// it needs permission to patch VirtualMachines
+	PersistConnectionInfo bool `envconfig:"EVENTS_PERSIST_CONNECTION_INFO" default:"false"`
+	// ReadyEvents publishes a dcm.vm.ready event with the IP, SSH command and node port once a VM is reachable
+	ReadyEvents bool `envconfig:"EVENTS_READY_EVENTS" default:"false"`
+}
+
+// KafkaConfig holds configuration for publishing events to Kafka
+type KafkaConfig struct {
+	// RESTProxyURL is the Kafka REST Proxy records are produced through
+	RESTProxyURL string `envconfig:"KAFKA_REST_PROXY_URL" default:"http://localhost:8082"`
+	// RESTProxyToken is a bearer token authenticating to the REST proxy (empty sends none)
+	RESTProxyToken string `envconfig:"KAFKA_REST_PROXY_TOKEN"`
+	// Topic is the Kafka topic for VM events
+	Topic string `envconfig:"KAFKA_TOPIC" default:"dcm.vm"`
+	// Timeout bounds each produce request
+	Timeout time.Duration `envconfig:"KAFKA_TIMEOUT" default:"10s"`
+	// MaxAttempts bounds the produce requests made per event when the proxy is unreachable or failing
+	MaxAttempts int `envconfig:"KAFKA_MAX_ATTEMPTS" default:"3"`
+	// RetryBackoff is the delay before the first retry, doubled on each further retry
+	RetryBackoff time.Duration `envconfig:"KAFKA_RETRY_BACKOFF" default:"500ms"`
+}
+
+// PolicyConfig holds the limits enforced on VM create requests. Empty values are not enforced.
+type PolicyConfig struct {
+	// AllowedGuestOS lists the guest OS types VMs may use (e.g. "fedora,ubuntu")
+	AllowedGuestOS []string `envconfig:"POLICY_ALLOWED_GUEST_OS"`
+	// MaxVCPU is the largest vCPU count a VM may request
+	MaxVCPU int `envconfig:"POLICY_MAX_VCPU"`
+	// MaxMemory is the largest memory size a VM may request (e.g. "16Gi")
+	MaxMemory string `envconfig:"POLICY_MAX_MEMORY"`
+	// MaxDiskCapacity is the largest capacity a single disk may request (e.g. "500Gi")
+	MaxDiskCapacity string `envconfig:"POLICY_MAX_DISK_CAPACITY"`
+	// RequiredLabels lists the metadata labels every VM must carry
+	RequiredLabels []string `envconfig:"POLICY_REQUIRED_LABELS"`
+}
+
+// LogConfig holds configuration for the process logger
+type LogConfig struct {
+	// Level is the minimum level logged: debug, info, warn or error
+	Level string `envconfig:"DCM_LOG_LEVEL" default:"info"`
+	// Format is json for structured logs or console for human-readable ones
+	Format string `envconfig:"DCM_LOG_FORMAT" default:"json"`
 }
 
 type Config struct {
@@ -65,13 +250,39 @@ type Config struct {
 	ServiceProviderManagerConfig *ServiceProviderManagerConfig
 	KubernetesConfig            *KubernetesConfig
 	NATSConfig                  *NATSConfig
+	KafkaConfig                 *KafkaConfig
 	EventConfig                 *EventConfig
+	PolicyConfig                *PolicyConfig
+	LogConfig                   *LogConfig
 }
 
+// ConfigFileEnv names the environment variable holding the path of an
+// optional configuration file
+const ConfigFileEnv = "DCM_CONFIG_FILE"
+
+// Load reads the configuration from the environment, filled in by the file
+// named by DCM_CONFIG_FILE when it is set.
 func Load() (*Config, error) {
+	return LoadFile(os.Getenv(ConfigFileEnv))
+}
+
+// LoadFile reads the configuration from the environment, filled in by the
+// YAML file at path unless path is empty. The file maps environment variable
+// names to values; like a dotenv file, it only sets variables missing from
+// the environment, so the environment takes precedence.
+func LoadFile(path string) (*Config, error) {
+	if path != "" {
+		if err := applyFile(path); err != nil {
+			return nil, err
+		}
+	}
+
 	cfg := &Config{}
 	if err := envconfig.Process("", cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	setenv := func(key, value string) {
+		restoreEnv(key)
+		Expect(os.Setenv(key, value)).To(Succeed())
+	}
+
+	It("should default the Kubernetes settings", func() {
+		for _, key := range []string{"KUBERNETES_KUBECONFIG", "KUBERNETES_NAMESPACE", "KUBERNETES_TIMEOUT", "KUBERNETES_MAX_RETRIES"} {
+			restoreEnv(key)
+			Expect(os.Unsetenv(key)).To(Succeed())
+		}
+
+		cfg, err := Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.KubernetesConfig.Kubeconfig).To(BeEmpty())
+		Expect(cfg.KubernetesConfig.Namespace).To(Equal("default"))
+		Expect(cfg.KubernetesConfig.Timeout).To(Equal(60 * time.Second))
+		Expect(cfg.KubernetesConfig.MaxRetries).To(Equal(3))
+	})
+
+	It("should read the Kubernetes settings from the environment", func() {
+		setenv("KUBERNETES_KUBECONFIG", "/etc/dcm/kubeconfig")
+		setenv("KUBERNETES_NAMESPACE", "vms")
+		setenv("KUBERNETES_TIMEOUT", "15s")
+		setenv("KUBERNETES_MAX_RETRIES", "5")
+
+		cfg, err := Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.KubernetesConfig.Kubeconfig).To(Equal("/etc/dcm/kubeconfig"))
+		Expect(cfg.KubernetesConfig.Namespace).To(Equal("vms"))
+		Expect(cfg.KubernetesConfig.Timeout).To(Equal(15 * time.Second))
+		Expect(cfg.KubernetesConfig.MaxRetries).To(Equal(5))
+	})
+
+	It("should reject a malformed setting", func() {
+		setenv("KUBERNETES_MAX_RETRIES", "many")
+
+		_, err := Load()
+		Expect(err).To(MatchError(ContainSubstring("KUBERNETES_MAX_RETRIES")))
+	})
+
+	It("should reject a provider ID that is not a UUID", func() {
+		setenv("PROVIDER_ID", "kubevirt-1")
+
+		_, err := Load()
+		Expect(err).To(MatchError(ContainSubstring("invalid PROVIDER_ID")))
+	})
+})
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// applyFile sets the environment variables listed in a YAML config file that
+// are not already set. Lists are joined with commas and maps are written as
+// key:value pairs, as envconfig expects them.
+func applyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	known := envKeys(reflect.TypeOf(Config{}))
+	for key, value := range values {
+		if !known[key] {
+			return fmt.Errorf("config file %s: unknown setting %s", path, key)
+		}
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		s, err := envValue(value)
+		if err != nil {
+			return fmt.Errorf("config file %s: setting %s: %w", path, key, err)
+		}
+		if err := os.Setenv(key, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envKeys collects the environment variable names of the settings of a
+// config struct, descending into nested config structs
+func envKeys(t reflect.Type) map[string]bool {
+	keys := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if key := field.Tag.Get("envconfig"); key != "" {
+			keys[key] = true
+			continue
+		}
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			for key := range envKeys(ft) {
+				keys[key] = true
+			}
+		}
+	}
+	return keys
+}
+
+// envValue formats a YAML value the way envconfig parses it
+func envValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := envValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			s, err := envValue(item)
+			if err != nil {
+				return "", err
+			}
+			pairs = append(pairs, key+":"+s)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ","), nil
+	case float64:
+		// YAML numbers decode as float64; keep large integers free of exponents
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case string, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
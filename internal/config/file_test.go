@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadFile", func() {
+	var path string
+
+	writeFile := func(content string) {
+		path = filepath.Join(GinkgoT().TempDir(), "config.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+	}
+
+	It("should merge the file with the environment taking precedence", func() {
+		for _, key := range []string{"KUBERNETES_NAMESPACE", "KUBERNETES_TIMEOUT", "KUBERNETES_NODE_POOL_SELECTOR", "EVENTS_MAX_TRACKED_VMS", "PROVIDER_OPERATIONS"} {
+			restoreEnv(key)
+			Expect(os.Unsetenv(key)).To(Succeed())
+		}
+		writeFile(`
+KUBERNETES_NAMESPACE: vms
+KUBERNETES_TIMEOUT: 15s
+KUBERNETES_NODE_POOL_SELECTOR:
+  pool: vms
+  zone: a
+EVENTS_MAX_TRACKED_VMS: 2000000
+PROVIDER_OPERATIONS: [CREATE, READ]
+`)
+		Expect(os.Setenv("KUBERNETES_NAMESPACE", "override")).To(Succeed())
+
+		cfg, err := LoadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.KubernetesConfig.Namespace).To(Equal("override"))
+		Expect(cfg.KubernetesConfig.Timeout).To(Equal(15 * time.Second))
+		Expect(cfg.KubernetesConfig.NodePoolSelector).To(Equal(map[string]string{"pool": "vms", "zone": "a"}))
+		Expect(cfg.EventConfig.MaxTrackedVMs).To(Equal(2000000))
+		Expect(cfg.ProviderConfig.Operations).To(Equal([]string{"CREATE", "READ"}))
+	})
+
+	It("should read the file named by DCM_CONFIG_FILE", func() {
+		restoreEnv("KUBERNETES_MAX_RETRIES")
+		Expect(os.Unsetenv("KUBERNETES_MAX_RETRIES")).To(Succeed())
+		writeFile("KUBERNETES_MAX_RETRIES: 7\n")
+		restoreEnv(ConfigFileEnv)
+		Expect(os.Setenv(ConfigFileEnv, path)).To(Succeed())
+
+		cfg, err := Load()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.KubernetesConfig.MaxRetries).To(Equal(7))
+	})
+
+	It("should reject an unknown setting", func() {
+		writeFile("KUBERNETES_NAMESPCE: vms\n")
+
+		_, err := LoadFile(path)
+		Expect(err).To(MatchError(ContainSubstring("unknown setting KUBERNETES_NAMESPCE")))
+	})
+
+	It("should report a missing file", func() {
+		_, err := LoadFile(filepath.Join(GinkgoT().TempDir(), "missing.yaml"))
+		Expect(err).To(MatchError(ContainSubstring("failed to read config file")))
+	})
+})
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config Suite")
+}
+
+// restoreEnv restores an environment variable after the spec
+func restoreEnv(key string) {
+	previous, set := os.LookupEnv(key)
+	DeferCleanup(func() {
+		if set {
+			os.Setenv(key, previous)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
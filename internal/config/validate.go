@@ -0,0 +1,251 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// unixScheme prefixes listen addresses naming a unix domain socket
+const unixScheme = "unix://"
+
+// reservedKeyPrefix prefixes the labels and annotations DCM sets itself
+const reservedKeyPrefix = "dcm.project/"
+
+// Validate checks the settings that would otherwise only fail once they are
+// used, such as malformed addresses and URLs. Every problem found is listed
+// in the returned error, each naming the environment variable to fix.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if p := c.ProviderConfig; p != nil {
+		if _, err := uuid.Parse(p.ID); err != nil {
+			check(fmt.Errorf("invalid PROVIDER_ID %q: must be a UUID", p.ID))
+		}
+		check(validateListenAddress("PROVIDER_LISTEN_ADDRESS", p.ListenAddress))
+		check(validateHTTPURL("PROVIDER_ENDPOINT", p.Endpoint))
+		check(required("PROVIDER_NAME", p.Name))
+		check(positive("PROVIDER_HTTP_TIMEOUT", p.HTTPTimeout))
+		check(positive("PROVIDER_SHUTDOWN_TIMEOUT", p.ShutdownTimeout))
+		check(notNegative("PROVIDER_HEARTBEAT_INTERVAL", p.HeartbeatInterval))
+	}
+	if s := c.ServiceProviderManagerConfig; s != nil {
+		check(validateHTTPURL("SERVICE_MANAGER_ENDPOINT", s.Endpoint))
+		check(positive("SERVICE_MANAGER_REGISTRATION_BACKOFF", s.RegistrationBackoff))
+		if s.RegistrationMaxAttempts < 0 {
+			check(fmt.Errorf("invalid SERVICE_MANAGER_REGISTRATION_MAX_ATTEMPTS %d: must not be negative", s.RegistrationMaxAttempts))
+		}
+	}
+	if k := c.KubernetesConfig; k != nil {
+		check(required("KUBERNETES_NAMESPACE", k.Namespace))
+		check(positive("KUBERNETES_TIMEOUT", k.Timeout))
+		if k.MaxRetries < 0 {
+			check(fmt.Errorf("invalid KUBERNETES_MAX_RETRIES %d: must not be negative", k.MaxRetries))
+		}
+		validateKubernetes(k, check)
+	}
+	if e := c.EventConfig; e != nil && e.Enabled {
+		// The publisher ignores the case of the backend and defaults to NATS
+		if e.Backend != "" {
+			check(oneOfFold("EVENTS_BACKEND", e.Backend, "nats", "kafka"))
+		}
+		if n := c.NATSConfig; n != nil && (e.Backend == "" || strings.EqualFold(e.Backend, "nats")) {
+			check(validateURL("NATS_URL", n.URL, "nats", "tls", "ws", "wss"))
+		}
+		if k := c.KafkaConfig; k != nil && strings.EqualFold(e.Backend, "kafka") {
+			check(validateHTTPURL("KAFKA_REST_PROXY_URL", k.RESTProxyURL))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// required rejects an empty setting
+func required(key, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s is required", key)
+	}
+	return nil
+}
+
+// positive rejects a duration that is zero or negative
+func positive(key string, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("invalid %s %v: must be positive", key, d)
+	}
+	return nil
+}
+
+// notNegative rejects a negative duration
+func notNegative(key string, d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("invalid %s %v: must not be negative", key, d)
+	}
+	return nil
+}
+
+// validateListenAddress accepts a TCP host:port or a unix:///path/to.sock
+func validateListenAddress(key, address string) error {
+	if path, ok := strings.CutPrefix(address, unixScheme); ok {
+		if path == "" {
+			return fmt.Errorf("invalid %s %q: missing socket path", key, address)
+		}
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("invalid %s %q: must be host:port or %s/path/to.sock", key, address, unixScheme)
+	}
+	return nil
+}
+
+// validateHTTPURL accepts an absolute http or https URL
+func validateHTTPURL(key, value string) error {
+	return validateURL(key, value, "http", "https")
+}
+
+// validateURL accepts an absolute URL with a host and one of the given schemes
+func validateURL(key, value string, schemes ...string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			if u.Host == "" {
+				return fmt.Errorf("invalid %s %q: missing host", key, value)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q: scheme must be one of %s", key, value, strings.Join(schemes, ", "))
+}
+
+// validateKubernetes checks the settings shaping the VMs the mapper creates,
+// passing each problem to check
+func validateKubernetes(k *KubernetesConfig, check func(error)) {
+	check(quantity("KUBERNETES_STORAGE_GRANULARITY", k.StorageGranularity))
+	if k.CPUOverhead != "" {
+		check(nonNegativeQuantity("KUBERNETES_CPU_OVERHEAD", k.CPUOverhead))
+	}
+	if k.MemoryOverhead != "" {
+		check(nonNegativeQuantity("KUBERNETES_MEMORY_OVERHEAD", k.MemoryOverhead))
+	}
+	if k.MinBootDiskCapacity != "" {
+		check(quantity("KUBERNETES_MIN_BOOT_DISK_CAPACITY", k.MinBootDiskCapacity))
+	}
+	check(oneOf("KUBERNETES_PASSTHROUGH_MIGRATION_POLICY", k.PassthroughMigrationPolicy, "warn", "block", "host-model"))
+	check(oneOf("KUBERNETES_SSH_KEY_PROPAGATION", k.SSHKeyPropagation, "nocloud", "qemu-guest-agent"))
+	check(oneOf("KUBERNETES_SSH_KEY_SECRET_LAYOUT", k.SSHKeySecretLayout, "joined", "separate"))
+	for _, channel := range k.SerialChannels {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			check(oneOf("KUBERNETES_SERIAL_CHANNELS", channel, "org.qemu.guest_agent.0", "org.github.vhostmd.1"))
+		}
+	}
+	for guestOS, model := range k.InterfaceModels {
+		check(oneOfFold(fmt.Sprintf("KUBERNETES_INTERFACE_MODELS entry %s", guestOS), model,
+			"e1000", "e1000e", "igb", "ne2k_pci", "pcnet", "rtl8139", "virtio"))
+	}
+	check(oneOfFold("KUBERNETES_PORT_SERVICE_TYPE", k.PortServiceType, "NodePort", "LoadBalancer", "ClusterIP"))
+	if k.SSHTargetPort < 1 || k.SSHTargetPort > 65535 {
+		check(fmt.Errorf("invalid KUBERNETES_SSH_TARGET_PORT %d: must be between 1 and 65535", k.SSHTargetPort))
+	}
+	check(metadataKeys("KUBERNETES_ACCESS_LABELS", k.AccessLabels))
+	check(metadataKeys("KUBERNETES_ACCESS_ANNOTATIONS", k.AccessAnnotations))
+	for key, value := range k.AccessLabels {
+		if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+			check(fmt.Errorf("invalid KUBERNETES_ACCESS_LABELS value %q for %q: %s", value, key, strings.Join(msgs, "; ")))
+		}
+	}
+	check(oneOfFold("KUBERNETES_RUN_STRATEGY", k.RunStrategy, "Always", "Manual"))
+	if k.TPM != "" {
+		check(oneOfFold("KUBERNETES_TPM", k.TPM, "none", "ephemeral", "persistent"))
+	}
+	if strings.EqualFold(k.TPM, "persistent") && k.VMStateStorageClass == "" {
+		check(errors.New("invalid KUBERNETES_TPM persistent: requires KUBERNETES_VM_STATE_STORAGE_CLASS"))
+	}
+	if r := k.MemoryRequestRatio; r <= 0 || r > 1 || math.IsNaN(r) {
+		check(fmt.Errorf("invalid KUBERNETES_MEMORY_REQUEST_RATIO %v: must be above 0 and at most 1", r))
+	}
+	if k.ScratchDiskRatio < 0 || math.IsNaN(k.ScratchDiskRatio) {
+		check(fmt.Errorf("invalid KUBERNETES_SCRATCH_DISK_RATIO %v: must not be negative", k.ScratchDiskRatio))
+	}
+	if k.Subdomain != "" {
+		check(dnsName("KUBERNETES_SUBDOMAIN", k.Subdomain, validation.IsDNS1123Label))
+	}
+	if k.SchedulerName != "" {
+		check(dnsName("KUBERNETES_SCHEDULER_NAME", k.SchedulerName, validation.IsDNS1123Subdomain))
+	}
+}
+
+// quantity rejects a malformed resource quantity such as "1Gi"
+func quantity(key, value string) error {
+	if _, err := resource.ParseQuantity(value); err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	return nil
+}
+
+// nonNegativeQuantity rejects a malformed or negative resource quantity
+func nonNegativeQuantity(key, value string) error {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", key, value, err)
+	}
+	if q.Sign() < 0 {
+		return fmt.Errorf("invalid %s %q: must not be negative", key, value)
+	}
+	return nil
+}
+
+// oneOf rejects a value that is not exactly one of allowed
+func oneOf(key, value string, allowed ...string) error {
+	if !slices.Contains(allowed, value) {
+		return fmt.Errorf("invalid %s %q: must be one of %s", key, value, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// oneOfFold rejects a value that is not one of allowed, ignoring case
+func oneOfFold(key, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if strings.EqualFold(value, a) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s %q: must be one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// metadataKeys rejects label or annotation keys that are malformed or use the
+// prefix reserved for DCM
+func metadataKeys(key string, metadata map[string]string) error {
+	for k := range metadata {
+		if msgs := validation.IsQualifiedName(k); len(msgs) > 0 {
+			return fmt.Errorf("invalid %s key %q: %s", key, k, strings.Join(msgs, "; "))
+		}
+		if strings.HasPrefix(k, reservedKeyPrefix) {
+			return fmt.Errorf("invalid %s key %q: the %s prefix is reserved", key, k, reservedKeyPrefix)
+		}
+	}
+	return nil
+}
+
+// dnsName rejects a name failing the given Kubernetes DNS name rule
+func dnsName(key, value string, rule func(string) []string) error {
+	if msgs := rule(value); len(msgs) > 0 {
+		return fmt.Errorf("invalid %s %q: %s", key, value, strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var cfg *Config
+
+	BeforeEach(func() {
+		cfg = &Config{
+			ProviderConfig: &ProviderConfig{
+				ID:              "8a2e5a5c-4a53-4f3c-9b4e-0f3c1d2e7b11",
+				Name:            "kubevirt",
+				ListenAddress:   "0.0.0.0:8081",
+				Endpoint:        "http://localhost:8081",
+				HTTPTimeout:     10 * time.Second,
+				ShutdownTimeout: 30 * time.Second,
+			},
+			ServiceProviderManagerConfig: &ServiceProviderManagerConfig{
+				Endpoint:            "http://localhost:8080/api/v1alpha1",
+				RegistrationBackoff: time.Second,
+			},
+			KubernetesConfig: &KubernetesConfig{
+				Namespace:                  "default",
+				Timeout:                    time.Minute,
+				MaxRetries:                 3,
+				MemoryRequestRatio:         1,
+				PassthroughMigrationPolicy: "warn",
+				PortServiceType:            "NodePort",
+				RunStrategy:                "Always",
+				SSHKeyPropagation:          "nocloud",
+				SSHKeySecretLayout:         "joined",
+				SSHTargetPort:              22,
+				StorageGranularity:         "1Gi",
+				TPM:                        "none",
+			},
+			NATSConfig:  &NATSConfig{URL: "nats://localhost:4222"},
+			KafkaConfig: &KafkaConfig{RESTProxyURL: "http://localhost:8082"},
+			EventConfig: &EventConfig{Enabled: true, Backend: "nats"},
+		}
+	})
+
+	It("should accept a valid configuration", func() {
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("should accept a unix socket listen address", func() {
+		cfg.ProviderConfig.ListenAddress = "unix:///run/dcm/provider.sock"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	DescribeTable("should reject an invalid setting",
+		func(mutate func(*Config), key string) {
+			mutate(cfg)
+			Expect(cfg.Validate()).To(MatchError(ContainSubstring(key)))
+		},
+		Entry("provider ID not a UUID", func(c *Config) { c.ProviderConfig.ID = "kubevirt-1" }, "PROVIDER_ID"),
+		Entry("missing provider name", func(c *Config) { c.ProviderConfig.Name = "" }, "PROVIDER_NAME"),
+		Entry("listen address without port", func(c *Config) { c.ProviderConfig.ListenAddress = "localhost" }, "PROVIDER_LISTEN_ADDRESS"),
+		Entry("unix socket without path", func(c *Config) { c.ProviderConfig.ListenAddress = "unix://" }, "PROVIDER_LISTEN_ADDRESS"),
+		Entry("relative provider endpoint", func(c *Config) { c.ProviderConfig.Endpoint = "localhost:8081" }, "PROVIDER_ENDPOINT"),
+		Entry("zero HTTP timeout", func(c *Config) { c.ProviderConfig.HTTPTimeout = 0 }, "PROVIDER_HTTP_TIMEOUT"),
+		Entry("Service Manager endpoint without host", func(c *Config) { c.ServiceProviderManagerConfig.Endpoint = "http://" }, "SERVICE_MANAGER_ENDPOINT"),
+		Entry("zero registration backoff", func(c *Config) { c.ServiceProviderManagerConfig.RegistrationBackoff = 0 }, "SERVICE_MANAGER_REGISTRATION_BACKOFF"),
+		Entry("negative registration attempts", func(c *Config) { c.ServiceProviderManagerConfig.RegistrationMaxAttempts = -1 }, "SERVICE_MANAGER_REGISTRATION_MAX_ATTEMPTS"),
+		Entry("missing namespace", func(c *Config) { c.KubernetesConfig.Namespace = "" }, "KUBERNETES_NAMESPACE"),
+		Entry("negative retries", func(c *Config) { c.KubernetesConfig.MaxRetries = -1 }, "KUBERNETES_MAX_RETRIES"),
+		Entry("malformed storage granularity", func(c *Config) { c.KubernetesConfig.StorageGranularity = "1 GB" }, "KUBERNETES_STORAGE_GRANULARITY"),
+		Entry("malformed CPU overhead", func(c *Config) { c.KubernetesConfig.CPUOverhead = "lots" }, "KUBERNETES_CPU_OVERHEAD"),
+		Entry("negative memory overhead", func(c *Config) { c.KubernetesConfig.MemoryOverhead = "-256Mi" }, "KUBERNETES_MEMORY_OVERHEAD"),
+		Entry("malformed minimum boot disk capacity", func(c *Config) { c.KubernetesConfig.MinBootDiskCapacity = "ten" }, "KUBERNETES_MIN_BOOT_DISK_CAPACITY"),
+		Entry("unknown passthrough migration policy", func(c *Config) { c.KubernetesConfig.PassthroughMigrationPolicy = "ignore" }, "KUBERNETES_PASSTHROUGH_MIGRATION_POLICY"),
+		Entry("unknown SSH key propagation", func(c *Config) { c.KubernetesConfig.SSHKeyPropagation = "ssh" }, "KUBERNETES_SSH_KEY_PROPAGATION"),
+		Entry("unknown SSH key secret layout", func(c *Config) { c.KubernetesConfig.SSHKeySecretLayout = "split" }, "KUBERNETES_SSH_KEY_SECRET_LAYOUT"),
+		Entry("unknown serial channel", func(c *Config) { c.KubernetesConfig.SerialChannels = []string{"org.example.0"} }, "KUBERNETES_SERIAL_CHANNELS"),
+		Entry("unknown interface model", func(c *Config) { c.KubernetesConfig.InterfaceModels = map[string]string{"windows": "tulip"} }, "KUBERNETES_INTERFACE_MODELS"),
+		Entry("unknown port service type", func(c *Config) { c.KubernetesConfig.PortServiceType = "ExternalName" }, "KUBERNETES_PORT_SERVICE_TYPE"),
+		Entry("SSH target port out of range", func(c *Config) { c.KubernetesConfig.SSHTargetPort = 70000 }, "KUBERNETES_SSH_TARGET_PORT"),
+		Entry("reserved access label key", func(c *Config) { c.KubernetesConfig.AccessLabels = map[string]string{"dcm.project/team": "a"} }, "KUBERNETES_ACCESS_LABELS"),
+		Entry("invalid access label value", func(c *Config) { c.KubernetesConfig.AccessLabels = map[string]string{"team": "a b"} }, "KUBERNETES_ACCESS_LABELS"),
+		Entry("malformed access annotation key", func(c *Config) { c.KubernetesConfig.AccessAnnotations = map[string]string{"a b": "c"} }, "KUBERNETES_ACCESS_ANNOTATIONS"),
+		Entry("unknown run strategy", func(c *Config) { c.KubernetesConfig.RunStrategy = "Halted" }, "KUBERNETES_RUN_STRATEGY"),
+		Entry("unknown TPM mode", func(c *Config) { c.KubernetesConfig.TPM = "shared" }, "KUBERNETES_TPM"),
+		Entry("persistent TPM without VM state storage class", func(c *Config) { c.KubernetesConfig.TPM = "persistent" }, "KUBERNETES_VM_STATE_STORAGE_CLASS"),
+		Entry("zero memory request ratio", func(c *Config) { c.KubernetesConfig.MemoryRequestRatio = 0 }, "KUBERNETES_MEMORY_REQUEST_RATIO"),
+		Entry("negative scratch disk ratio", func(c *Config) { c.KubernetesConfig.ScratchDiskRatio = -1 }, "KUBERNETES_SCRATCH_DISK_RATIO"),
+		Entry("subdomain with dots", func(c *Config) { c.KubernetesConfig.Subdomain = "vms.example" }, "KUBERNETES_SUBDOMAIN"),
+		Entry("uppercase scheduler name", func(c *Config) { c.KubernetesConfig.SchedulerName = "Custom" }, "KUBERNETES_SCHEDULER_NAME"),
+		Entry("NATS URL with HTTP scheme", func(c *Config) { c.NATSConfig.URL = "http://localhost:4222" }, "NATS_URL"),
+		Entry("Kafka REST proxy URL with Kafka backend", func(c *Config) {
+			c.EventConfig.Backend = "kafka"
+			c.KafkaConfig.RESTProxyURL = "localhost:8082"
+		}, "KAFKA_REST_PROXY_URL"),
+		Entry("Kafka REST proxy URL with Kafka backend in another case", func(c *Config) {
+			c.EventConfig.Backend = "Kafka"
+			c.KafkaConfig.RESTProxyURL = "localhost:8082"
+		}, "KAFKA_REST_PROXY_URL"),
+		Entry("unknown event backend", func(c *Config) { c.EventConfig.Backend = "natss" }, "EVENTS_BACKEND"),
+	)
+
+	It("should skip the settings of an unused event backend", func() {
+		cfg.KafkaConfig.RESTProxyURL = "not a url"
+		Expect(cfg.Validate()).To(Succeed())
+
+		cfg.EventConfig.Enabled = false
+		cfg.NATSConfig.URL = "not a url"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("should accept settings in any case where the provider ignores it", func() {
+		cfg.KubernetesConfig.PortServiceType = "loadbalancer"
+		cfg.KubernetesConfig.RunStrategy = "manual"
+		cfg.KubernetesConfig.TPM = "Persistent"
+		cfg.KubernetesConfig.VMStateStorageClass = "local"
+		cfg.KubernetesConfig.InterfaceModels = map[string]string{"windows": "E1000E"}
+		cfg.EventConfig.Backend = "NATS"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("should list every problem found", func() {
+		cfg.ProviderConfig.ID = "kubevirt-1"
+		cfg.ProviderConfig.Endpoint = ""
+		cfg.KubernetesConfig.Namespace = ""
+		cfg.KubernetesConfig.RunStrategy = "Halted"
+		cfg.KubernetesConfig.SSHTargetPort = 0
+
+		err := cfg.Validate()
+		Expect(err).To(MatchError(ContainSubstring("PROVIDER_ID")))
+		Expect(err).To(MatchError(ContainSubstring("PROVIDER_ENDPOINT")))
+		Expect(err).To(MatchError(ContainSubstring("KUBERNETES_NAMESPACE")))
+		Expect(err).To(MatchError(ContainSubstring("KUBERNETES_RUN_STRATEGY")))
+		Expect(err).To(MatchError(ContainSubstring("KUBERNETES_SSH_TARGET_PORT")))
+	})
+})
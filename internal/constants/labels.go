@@ -8,6 +8,51 @@ const (
 	// DCMLabelInstanceID contains the DCM instance ID for a resource
 	DCMLabelInstanceID = "dcm.project/dcm-instance-id"
 
+	// DCMLabelSubdomain names the DNS subdomain a VM is placed in
+	DCMLabelSubdomain = "dcm.project/subdomain"
+
+	// DCMLabelSecretType tells apart the kinds of Secrets created for a VM
+	DCMLabelSecretType = "dcm.project/secret-type"
+
 	// DCMManagedByValue is the value used for the managed-by label
 	DCMManagedByValue = "dcm"
+
+	// DCMSecretTypeSSHKey labels Secrets holding a VM's SSH public keys
+	DCMSecretTypeSSHKey = "ssh-key"
+
+	// DCMSecretTypeCloudInit labels Secrets holding a VM's cloud-init user data
+	DCMSecretTypeCloudInit = "cloud-init"
+
+	// DCMAnnotationRuntimeClass names the RuntimeClass whose node pool a VM targets
+	DCMAnnotationRuntimeClass = "dcm.project/runtime-class"
+
+	// DCMAnnotationMaintenanceReady marks VMs that live migrate off nodes being drained
+	DCMAnnotationMaintenanceReady = "dcm.project/maintenance-ready"
+
+	// DCMAnnotationSerialChannels lists the virtio-serial channels of a VM, comma separated
+	DCMAnnotationSerialChannels = "dcm.project/serial-channels"
+
+	// DCMAnnotationSSHPublicKey records the SSH public keys injected into a VM
+	DCMAnnotationSSHPublicKey = "dcm.project/ssh-public-key"
+
+	// DCMAnnotationRequestID records the ID of the API request that created a VM
+	DCMAnnotationRequestID = "dcm.project/request-id"
+
+	// DCMAnnotationProviderVersion records the provider version that mapped a VM
+	DCMAnnotationProviderVersion = "dcm.project/provider-version"
+
+	// DCMAnnotationSchemaVersion records the catalog schema version a VM was mapped from
+	DCMAnnotationSchemaVersion = "dcm.project/schema-version"
+
+	// DCMAnnotationIPAddress records the primary IP of a running VM
+	DCMAnnotationIPAddress = "dcm.project/ip-address"
+
+	// DCMAnnotationSSHUser records the guest user a VM's SSH public keys are installed for
+	DCMAnnotationSSHUser = "dcm.project/ssh-user"
+
+	// DCMAnnotationSSHSecret names the Secret holding a VM's SSH public keys
+	DCMAnnotationSSHSecret = "dcm.project/ssh-secret"
+
+	// DCMAnnotationSSHNodePort records the node port SSH to a VM is published on
+	DCMAnnotationSSHNodePort = "dcm.project/ssh-node-port"
 )
\ No newline at end of file
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"slices"
+	"sync"
+)
+
+// eventBuffer holds the latest event of each type of each VM while the event
+// bus is unreachable. A newer event of a VM replaces the buffered one of the
+// same type and moves it to the back; once size events are buffered, the one
+// buffered longest is dropped. A single flush at a time publishes the buffered
+// events.
+type eventBuffer struct {
+	mu       sync.Mutex
+	size     int
+	order    []string
+	events   map[string]VMEvent
+	flushing bool
+}
+
+func newEventBuffer(size int) *eventBuffer {
+	return &eventBuffer{size: size, events: make(map[string]VMEvent, size)}
+}
+
+// add buffers an event, reporting whether an older event was dropped to make room
+func (b *eventBuffer) add(event VMEvent) (dropped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.addLocked(event)
+}
+
+func (b *eventBuffer) addLocked(event VMEvent) (dropped bool) {
+	key := bufferKey(event)
+	if _, ok := b.events[key]; ok {
+		b.order = slices.DeleteFunc(b.order, func(k string) bool { return k == key })
+	} else if len(b.order) >= b.size {
+		delete(b.events, b.order[0])
+		b.order = b.order[1:]
+		dropped = true
+	}
+	b.order = append(b.order, key)
+	b.events[key] = event
+	return dropped
+}
+
+// bufferKey identifies the events that replace each other in the buffer: those
+// of the same type for the same VM
+func bufferKey(event VMEvent) string {
+	return event.eventType() + "/" + event.Id
+}
+
+// startFlush claims the flush of the buffer, reporting false when another
+// flush is in progress
+func (b *eventBuffer) startFlush() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.flushing {
+		return false
+	}
+	b.flushing = true
+	return true
+}
+
+// drainFlush returns the buffered events, oldest first, and empties the
+// buffer. When none are left it ends the flush, so that an event buffered
+// meanwhile is never left behind.
+func (b *eventBuffer) drainFlush() []VMEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.order) == 0 {
+		b.flushing = false
+		return nil
+	}
+
+	events := make([]VMEvent, 0, len(b.order))
+	for _, key := range b.order {
+		events = append(events, b.events[key])
+	}
+	b.order = nil
+	clear(b.events)
+	return events
+}
+
+// endFlush ends a flush that stopped early, buffering again the events it
+// did not publish unless a newer event of the VM arrived
+func (b *eventBuffer) endFlush(pending []VMEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, event := range pending {
+		if _, ok := b.events[bufferKey(event)]; !ok {
+			b.addLocked(event)
+		}
+	}
+	b.flushing = false
+}
+
+// busy reports whether events are buffered or being flushed. Events published
+// meanwhile must go through the buffer, so that they are not overtaken by
+// older events of the same VM.
+func (b *eventBuffer) busy() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.flushing || len(b.order) > 0
+}
+
+// supersede drops the buffered event of the same type and VM as a published
+// event, unless the buffered one is newer
+func (b *eventBuffer) supersede(event VMEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := bufferKey(event)
+	buffered, ok := b.events[key]
+	if !ok || buffered.Timestamp.After(event.Timestamp) {
+		return
+	}
+	delete(b.events, key)
+	b.order = slices.DeleteFunc(b.order, func(k string) bool { return k == key })
+}
+
+// len returns the number of buffered events
+func (b *eventBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.order)
+}
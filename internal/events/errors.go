@@ -0,0 +1,16 @@
+package events
+
+import "errors"
+
+// Errors returned by the Publisher. Callers match them with errors.Is; the
+// underlying cause stays wrapped alongside them.
+var (
+	// ErrNotConnected is returned when there is no usable event bus connection
+	ErrNotConnected = errors.New("event bus connection not available")
+	// ErrMarshal is returned when an event cannot be encoded as a CloudEvent
+	ErrMarshal = errors.New("failed to marshal event")
+	// ErrPublishFailed is returned when the event bus rejects or fails to store an event
+	ErrPublishFailed = errors.New("failed to publish event")
+	// ErrFlushTimeout is returned when the event bus does not acknowledge an event in time
+	ErrFlushTimeout = errors.New("timed out waiting for event acknowledgement")
+)
@@ -0,0 +1,227 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// KafkaProducer writes records to a Kafka topic
+type KafkaProducer interface {
+	Produce(ctx context.Context, topic string, key, value []byte) error
+	Close() error
+}
+
+// KafkaPublisher publishes events to a Kafka topic, keyed by VM ID so that the
+// events of one VM land in the same partition and stay in order
+type KafkaPublisher struct {
+	producer KafkaProducer
+	topic    string
+	source   string
+	closed   atomic.Bool
+}
+
+// KafkaPublisherConfig contains configuration for the Kafka publisher
+type KafkaPublisherConfig struct {
+	// RESTProxyURL is the base URL of the Kafka REST Proxy records are produced through
+	RESTProxyURL string
+	// Token is sent as a bearer token on every produce request; empty sends none
+	Token string
+	Topic string
+	// Source is the CloudEvent source of published events; empty uses DefaultSource
+	Source  string
+	Timeout time.Duration
+	// MaxAttempts bounds the produce requests made per event; values below 1 mean a single attempt
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, doubled on each further retry
+	RetryBackoff time.Duration
+}
+
+// errNonRetryable marks produce failures that a retry cannot fix
+var errNonRetryable = errors.New("non-retryable")
+
+// NewKafkaPublisher creates a publisher producing to a Kafka topic through a
+// Kafka REST Proxy
+func NewKafkaPublisher(config KafkaPublisherConfig) (*KafkaPublisher, error) {
+	if config.Topic == "" {
+		return nil, fmt.Errorf("failed to create Kafka publisher: topic must not be empty")
+	}
+	u, err := url.Parse(config.RESTProxyURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("failed to create Kafka publisher: invalid REST proxy URL %q", config.RESTProxyURL)
+	}
+	if err := validateSource(config.Source); err != nil {
+		return nil, fmt.Errorf("failed to create Kafka publisher: %w", err)
+	}
+
+	zap.S().Infow("Publishing to Kafka", "topic", config.Topic, "proxy", u.Redacted())
+	p := newKafkaPublisher(&restProducer{
+		baseURL:      strings.TrimSuffix(u.String(), "/"),
+		client:       &http.Client{Timeout: config.Timeout},
+		token:        config.Token,
+		maxAttempts:  max(config.MaxAttempts, 1),
+		retryBackoff: config.RetryBackoff,
+	}, config.Topic)
+	p.source = config.Source
+	return p, nil
+}
+
+func newKafkaPublisher(producer KafkaProducer, topic string) *KafkaPublisher {
+	return &KafkaPublisher{producer: producer, topic: topic}
+}
+
+// PublishVMEvent publishes a VM phase change event to the Kafka topic
+func (p *KafkaPublisher) PublishVMEvent(ctx context.Context, vmEvent VMEvent) error {
+	if !p.IsConnected() {
+		return ErrNotConnected
+	}
+
+	eventData, err := newCloudEvent(vmEvent, p.source, p.topic)
+	if err != nil {
+		return err
+	}
+
+	if err := p.producer.Produce(ctx, p.topic, []byte(vmEvent.Id), eventData); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %w", ErrFlushTimeout, err)
+		}
+		return fmt.Errorf("%w to Kafka: %w", ErrPublishFailed, err)
+	}
+
+	zap.S().Debugw("Published VM event to Kafka", "vmID", vmEvent.Id, "topic", p.topic)
+	return nil
+}
+
+// Close stops publishing and closes the producer
+func (p *KafkaPublisher) Close() error {
+	if p.closed.Swap(true) || p.producer == nil {
+		return nil
+	}
+	return p.producer.Close()
+}
+
+// IsConnected returns whether the publisher has a producer and is not closed
+func (p *KafkaPublisher) IsConnected() bool {
+	return p.producer != nil && !p.closed.Load()
+}
+
+// restProducer produces records through the Kafka REST Proxy v2 API
+type restProducer struct {
+	baseURL      string
+	client       *http.Client
+	token        string
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+type restRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type restProduceRequest struct {
+	Records []restRecord `json:"records"`
+}
+
+type restProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// Produce writes a single record. The binary embedded format sends key and
+// value base64 encoded, which encoding/json does for byte slices. Network
+// errors and 5xx responses are retried with exponential backoff until the
+// attempts are exhausted; rejected requests and records are not.
+func (p *restProducer) Produce(ctx context.Context, topic string, key, value []byte) error {
+	body, err := json.Marshal(restProduceRequest{Records: []restRecord{{Key: key, Value: value}}})
+	if err != nil {
+		return err
+	}
+
+	backoff := p.retryBackoff
+	for attempt := 1; ; attempt++ {
+		err = p.produce(ctx, topic, body)
+		if err == nil || errors.Is(err, errNonRetryable) || ctx.Err() != nil {
+			return err
+		}
+		if attempt >= p.maxAttempts {
+			zap.S().Warnw("Giving up producing to Kafka", "topic", topic, "attempts", attempt, "error", err)
+			return err
+		}
+		zap.S().Warnw("Producing to Kafka failed, retrying",
+			"topic", topic, "attempt", attempt, "maxAttempts", p.maxAttempts, "backoff", backoff, "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// produce makes a single produce request
+func (p *restProducer) produce(ctx context.Context, topic string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/topics/"+url.PathEscape(topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.binary.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result restProduceResponse
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &result); err != nil && resp.StatusCode < 300 {
+			return fmt.Errorf("invalid REST proxy response: %w", err)
+		}
+	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("REST proxy returned %s: %s", resp.Status, result.Message)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("REST proxy returned %s: %s: %w", resp.Status, result.Message, errNonRetryable)
+	}
+	for _, offset := range result.Offsets {
+		if offset.ErrorCode != nil {
+			return fmt.Errorf("record rejected with error code %d: %s: %w", *offset.ErrorCode, offset.Error, errNonRetryable)
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections to the REST proxy
+func (p *restProducer) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
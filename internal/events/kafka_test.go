@@ -0,0 +1,292 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeProducer records produced records or fails with produceErr
+type fakeProducer struct {
+	mu         sync.Mutex
+	records    []producedRecord
+	produceErr error
+	closed     bool
+}
+
+type producedRecord struct {
+	topic string
+	key   []byte
+	value []byte
+}
+
+func (p *fakeProducer) Produce(_ context.Context, topic string, key, value []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.produceErr != nil {
+		return p.produceErr
+	}
+	p.records = append(p.records, producedRecord{topic: topic, key: key, value: value})
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	p.closed = true
+	return nil
+}
+
+var _ = Describe("KafkaPublisher", func() {
+	var (
+		producer *fakeProducer
+		p        *KafkaPublisher
+		event    VMEvent
+	)
+
+	BeforeEach(func() {
+		producer = &fakeProducer{}
+		p = newKafkaPublisher(producer, "dcm.vm")
+		event = VMEvent{Id: "vm-123", Status: "Running", Timestamp: time.Now()}
+	})
+
+	It("should produce the CloudEvent to the topic keyed by VM ID", func() {
+		Expect(p.PublishVMEvent(context.Background(), event)).To(Succeed())
+
+		Expect(producer.records).To(HaveLen(1))
+		record := producer.records[0]
+		Expect(record.topic).To(Equal("dcm.vm"))
+		Expect(string(record.key)).To(Equal("vm-123"))
+
+		var cloudEvent map[string]interface{}
+		Expect(json.Unmarshal(record.value, &cloudEvent)).To(Succeed())
+		Expect(cloudEvent).To(HaveKeyWithValue("type", "dcm.status.vm"))
+		Expect(cloudEvent).To(HaveKeyWithValue("subject", "dcm.vm"))
+		Expect(cloudEvent).To(HaveKeyWithValue("source", DefaultSource))
+		Expect(cloudEvent["data"]).To(HaveKeyWithValue("id", "vm-123"))
+		Expect(cloudEvent["data"]).To(HaveKeyWithValue("status", "Running"))
+	})
+
+	It("should publish a ready event with its type and connection details", func() {
+		event.Type = EventTypeReady
+		event.Connection = &Connection{IP: "10.244.0.12", SSHCommand: "ssh fedora@10.244.0.12", SSHNodePort: 30022}
+		Expect(p.PublishVMEvent(context.Background(), event)).To(Succeed())
+
+		var cloudEvent map[string]interface{}
+		Expect(json.Unmarshal(producer.records[0].value, &cloudEvent)).To(Succeed())
+		Expect(cloudEvent).To(HaveKeyWithValue("type", EventTypeReady))
+		Expect(cloudEvent["data"]).NotTo(HaveKey("type"))
+		Expect(cloudEvent["data"]).To(HaveKeyWithValue("connection", map[string]interface{}{
+			"ip":            "10.244.0.12",
+			"ssh_command":   "ssh fedora@10.244.0.12",
+			"ssh_node_port": float64(30022),
+		}))
+	})
+
+	DescribeTable("should return typed errors for each failure",
+		func(produceErr error, expected error) {
+			producer.produceErr = produceErr
+			err := p.PublishVMEvent(context.Background(), event)
+			Expect(errors.Is(err, expected)).To(BeTrue(), "got %v", err)
+			Expect(errors.Is(err, produceErr)).To(BeTrue())
+		},
+		Entry("rejected record", errors.New("record rejected"), ErrPublishFailed),
+		Entry("context deadline", context.DeadlineExceeded, ErrFlushTimeout),
+	)
+
+	It("should stop publishing once closed", func() {
+		Expect(p.Close()).To(Succeed())
+		Expect(producer.closed).To(BeTrue())
+		Expect(p.IsConnected()).To(BeFalse())
+		Expect(p.PublishVMEvent(context.Background(), event)).To(MatchError(ErrNotConnected))
+	})
+
+	Describe("REST proxy producer", func() {
+		It("should post the record to the topic", func() {
+			var (
+				path, contentType string
+				request           restProduceRequest
+			)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				contentType = r.Header.Get("Content-Type")
+				body, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(body, &request)
+				w.Header().Set("Content-Type", "application/vnd.kafka.v2+json")
+				_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`))
+			}))
+			defer ts.Close()
+
+			publisher, err := NewKafkaPublisher(KafkaPublisherConfig{RESTProxyURL: ts.URL + "/", Topic: "dcm.vm", Timeout: time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publisher.PublishVMEvent(context.Background(), event)).To(Succeed())
+
+			Expect(path).To(Equal("/topics/dcm.vm"))
+			Expect(contentType).To(Equal("application/vnd.kafka.binary.v2+json"))
+			Expect(request.Records).To(HaveLen(1))
+			Expect(string(request.Records[0].Key)).To(Equal("vm-123"))
+		})
+
+		It("should authenticate with the configured bearer token", func() {
+			var authorization []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = append(authorization, r.Header.Get("Authorization"))
+				_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":7}]}`))
+			}))
+			defer ts.Close()
+
+			publisher, err := NewKafkaPublisher(KafkaPublisherConfig{RESTProxyURL: ts.URL, Token: "s3cret", Topic: "dcm.vm", Timeout: time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publisher.PublishVMEvent(context.Background(), event)).To(Succeed())
+
+			publisher, err = NewKafkaPublisher(KafkaPublisherConfig{RESTProxyURL: ts.URL, Topic: "dcm.vm", Timeout: time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publisher.PublishVMEvent(context.Background(), event)).To(Succeed())
+
+			Expect(authorization).To(Equal([]string{"Bearer s3cret", ""}))
+		})
+
+		It("should publish with the source configured for the publisher", func() {
+			var request restProduceRequest
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(body, &request)
+				_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":7}]}`))
+			}))
+			defer ts.Close()
+
+			publisher, err := NewPublisher(PublisherConfig{
+				Backend: BackendKafka,
+				Source:  "kubevirt.cluster-a",
+				Kafka:   KafkaPublisherConfig{RESTProxyURL: ts.URL, Topic: "dcm.vm", Timeout: time.Second},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(publisher.PublishVMEvent(context.Background(), event)).To(Succeed())
+
+			Expect(request.Records).To(HaveLen(1))
+			var cloudEvent map[string]interface{}
+			Expect(json.Unmarshal(request.Records[0].Value, &cloudEvent)).To(Succeed())
+			Expect(cloudEvent).To(HaveKeyWithValue("source", "kubevirt.cluster-a"))
+		})
+
+		It("should reject an invalid source", func() {
+			_, err := NewKafkaPublisher(KafkaPublisherConfig{RESTProxyURL: "http://localhost:8082", Topic: "dcm.vm", Source: "%zz"})
+			Expect(err).To(MatchError(ContainSubstring("invalid event source")))
+		})
+
+		It("should fail when the proxy rejects the record", func() {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"Kafka error"}]}`))
+			}))
+			defer ts.Close()
+
+			publisher, err := NewKafkaPublisher(KafkaPublisherConfig{RESTProxyURL: ts.URL, Topic: "dcm.vm", Timeout: time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			err = publisher.PublishVMEvent(context.Background(), event)
+			Expect(err).To(MatchError(ErrPublishFailed))
+			Expect(err).To(MatchError(ContainSubstring("50002")))
+		})
+
+		It("should fail when the topic does not exist", func() {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error_code":40401,"message":"Topic not found."}`))
+			}))
+			defer ts.Close()
+
+			publisher, err := NewKafkaPublisher(KafkaPublisherConfig{RESTProxyURL: ts.URL, Topic: "missing", Timeout: time.Second})
+			Expect(err).NotTo(HaveOccurred())
+			err = publisher.PublishVMEvent(context.Background(), event)
+			Expect(err).To(MatchError(ErrPublishFailed))
+			Expect(err).To(MatchError(ContainSubstring("Topic not found")))
+		})
+
+		Context("with retries", func() {
+			var (
+				calls     atomic.Int32
+				failures  int32
+				status    int
+				publisher *KafkaPublisher
+			)
+
+			BeforeEach(func() {
+				calls.Store(0)
+				ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if calls.Add(1) <= failures {
+						w.WriteHeader(status)
+						_, _ = w.Write([]byte(`{"error_code":50301,"message":"Kafka unavailable."}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":7}]}`))
+				}))
+				DeferCleanup(ts.Close)
+
+				var err error
+				publisher, err = NewKafkaPublisher(KafkaPublisherConfig{
+					RESTProxyURL: ts.URL,
+					Topic:        "dcm.vm",
+					Timeout:      time.Second,
+					MaxAttempts:  3,
+					RetryBackoff: time.Millisecond,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should retry server errors until the record is produced", func() {
+				failures, status = 2, http.StatusServiceUnavailable
+				Expect(publisher.PublishVMEvent(context.Background(), event)).To(Succeed())
+				Expect(calls.Load()).To(Equal(int32(3)))
+			})
+
+			It("should give up after the configured attempts", func() {
+				failures, status = 5, http.StatusInternalServerError
+				err := publisher.PublishVMEvent(context.Background(), event)
+				Expect(err).To(MatchError(ErrPublishFailed))
+				Expect(calls.Load()).To(Equal(int32(3)))
+			})
+
+			It("should not retry client errors", func() {
+				failures, status = 5, http.StatusUnprocessableEntity
+				err := publisher.PublishVMEvent(context.Background(), event)
+				Expect(err).To(MatchError(ErrPublishFailed))
+				Expect(calls.Load()).To(Equal(int32(1)))
+			})
+		})
+	})
+})
+
+var _ = Describe("NewPublisher", func() {
+	It("should create a Kafka publisher when configured", func() {
+		p, err := NewPublisher(PublisherConfig{
+			Backend: BackendKafka,
+			Kafka:   KafkaPublisherConfig{RESTProxyURL: "http://kafka-rest:8082", Topic: "dcm.vm"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(BeAssignableToTypeOf(&KafkaPublisher{}))
+	})
+
+	It("should default to NATS", func() {
+		p, err := NewPublisher(PublisherConfig{
+			NATS: NATSPublisherConfig{NATSURL: "nats://127.0.0.1:14222", Subject: "test.subject"},
+		})
+		Expect(err).To(MatchError(ErrNotConnected))
+		Expect(p).To(BeNil())
+	})
+
+	It("should reject an invalid Kafka configuration", func() {
+		_, err := NewPublisher(PublisherConfig{Backend: BackendKafka, Kafka: KafkaPublisherConfig{RESTProxyURL: "kafka-rest", Topic: "dcm.vm"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should reject an unknown backend", func() {
+		_, err := NewPublisher(PublisherConfig{Backend: "amqp"})
+		Expect(err).To(MatchError(ContainSubstring("unknown event backend")))
+	})
+})
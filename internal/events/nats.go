@@ -0,0 +1,419 @@
+package events
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// natsConn is the part of a NATS connection the publisher depends on
+type natsConn interface {
+	IsConnected() bool
+	Publish(subject string, data []byte) error
+	FlushWithContext(ctx context.Context) error
+	Close()
+}
+
+// NATSPublisher publishes events to a NATS subject, through JetStream when it
+// is enabled so that events are persisted until consumers read them
+type NATSPublisher struct {
+	natsConn     natsConn
+	js           jetstream.JetStream
+	natsURL      string
+	subject      string
+	source       string
+	stream       string
+	timeout      time.Duration
+	maxReconnect int
+	// buffer holds events published while disconnected; nil drops them
+	buffer *eventBuffer
+	// security holds the TLS and authentication options of the connection
+	security []nats.Option
+
+	reconnectWait    time.Duration
+	maxReconnectWait time.Duration
+
+	// retryPending is set while a flush that failed is scheduled to run again,
+	// and flushRetries counts the flushes that failed in a row
+	retryPending atomic.Bool
+	flushRetries atomic.Int32
+
+	// ctx ends with the publisher, stopping a flush of the buffer on Close
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NATSPublisherConfig contains configuration for the NATS publisher
+type NATSPublisherConfig struct {
+	NATSURL string
+	Subject string
+	// Source is the CloudEvent source of published events; empty uses DefaultSource
+	Source string
+	// JetStream publishes with acknowledgement to the stream capturing Subject;
+	// otherwise events are published on core NATS and lost without subscribers
+	JetStream bool
+	// Stream, when set with JetStream, is created or updated to capture Subject
+	Stream string
+	// Timeout bounds each publish, including waiting for the JetStream acknowledgement
+	Timeout time.Duration
+	// BufferSize is the number of VMs whose latest event is kept while
+	// disconnected and published on reconnect; 0 drops events instead
+	BufferSize       int
+	MaxReconnect     int
+	ReconnectWait    time.Duration
+	MaxReconnectWait time.Duration
+
+	// TLS secures the connection; nil leaves it to the URL scheme (tls://)
+	TLS *NATSTLSConfig
+	// User and Password authenticate with a username and password
+	User     string
+	Password string
+	// Token authenticates with a token
+	Token string
+	// CredsFile authenticates with a user JWT and nkey seed read from a .creds file
+	CredsFile string
+}
+
+// NATSTLSConfig holds the TLS settings of a NATS connection. Empty files fall
+// back to the system roots and no client certificate.
+type NATSTLSConfig struct {
+	// CAFile is a PEM bundle of the CAs the server certificate is verified against
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and key for mutual TLS
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify accepts any server certificate; for testing only
+	InsecureSkipVerify bool
+}
+
+const (
+	// defaultReconnectWait is the initial delay between reconnect rounds
+	defaultReconnectWait = time.Second
+	// defaultMaxReconnectWait caps the delay between reconnect rounds
+	defaultMaxReconnectWait = 30 * time.Second
+	// reconnectJitter is the largest fraction taken off a reconnect delay, so
+	// publishers disconnected together do not reconnect in lockstep
+	reconnectJitter = 0.2
+)
+
+// NewNATSPublisher creates a new NATS JetStream publisher
+func NewNATSPublisher(config NATSPublisherConfig) (*NATSPublisher, error) {
+	p := &NATSPublisher{
+		natsURL:          config.NATSURL,
+		subject:          config.Subject,
+		source:           config.Source,
+		stream:           config.Stream,
+		timeout:          config.Timeout,
+		maxReconnect:     config.MaxReconnect,
+		reconnectWait:    config.ReconnectWait,
+		maxReconnectWait: config.MaxReconnectWait,
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	if err := validateSource(p.source); err != nil {
+		return nil, fmt.Errorf("failed to create NATS publisher: %w", err)
+	}
+	security, err := natsSecurityOptions(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create NATS publisher: %w", err)
+	}
+	p.security = security
+	if config.BufferSize > 0 {
+		p.buffer = newEventBuffer(config.BufferSize)
+	}
+	if p.reconnectWait <= 0 {
+		p.reconnectWait = defaultReconnectWait
+	}
+	if p.maxReconnectWait < p.reconnectWait {
+		p.maxReconnectWait = max(defaultMaxReconnectWait, p.reconnectWait)
+	}
+
+	if err := p.connect(config.JetStream); err != nil {
+		p.cancel()
+		return nil, fmt.Errorf("failed to create NATS publisher: %w: %w", ErrNotConnected, err)
+	}
+
+	return p, nil
+}
+
+// connect establishes connection to NATS server and, when enabled, sets up JetStream
+func (p *NATSPublisher) connect(useJetStream bool) error {
+	opts := []nats.Option{
+		nats.CustomReconnectDelay(p.reconnectDelay),
+		nats.MaxReconnects(p.maxReconnect),
+		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
+			zap.S().Warnw("NATS disconnected", "error", err)
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			zap.S().Infow("NATS reconnected", "url", nc.ConnectedUrl())
+			// Publishing waits for acknowledgements, which must not block the callback
+			go p.flushBuffer(p.ctx)
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			zap.S().Info("NATS connection closed")
+		}),
+	}
+	opts = append(opts, p.security...)
+
+	nc, err := nats.Connect(p.natsURL, opts...)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	p.natsConn = nc
+
+	if !useJetStream {
+		zap.S().Infow("Connected to NATS, publishing without persistence", "subject", p.subject)
+		return nil
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		nc.Close()
+		return fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+	p.js = js
+
+	if err := p.ensureStream(context.Background()); err != nil {
+		nc.Close()
+		return err
+	}
+
+	zap.S().Infow("Connected to NATS, publishing to JetStream", "subject", p.subject)
+	return nil
+}
+
+// natsSecurityOptions returns the connection options for the configured TLS
+// settings and credentials. At most one authentication method may be set.
+func natsSecurityOptions(config NATSPublisherConfig) ([]nats.Option, error) {
+	var opts []nats.Option
+
+	methods := 0
+	if config.User != "" || config.Password != "" {
+		if config.User == "" {
+			return nil, errors.New("NATS password requires a user")
+		}
+		opts = append(opts, nats.UserInfo(config.User, config.Password))
+		methods++
+	}
+	if config.Token != "" {
+		opts = append(opts, nats.Token(config.Token))
+		methods++
+	}
+	if config.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(config.CredsFile))
+		methods++
+	}
+	if methods > 1 {
+		return nil, errors.New("only one of NATS user and password, token or credentials file may be set")
+	}
+
+	if config.TLS == nil {
+		return opts, nil
+	}
+	if (config.TLS.CertFile == "") != (config.TLS.KeyFile == "") {
+		return nil, errors.New("NATS TLS client certificate and key must be set together")
+	}
+	opts = append(opts, nats.Secure(&tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.TLS.InsecureSkipVerify,
+	}))
+	if config.TLS.CAFile != "" {
+		opts = append(opts, nats.RootCAs(config.TLS.CAFile))
+	}
+	if config.TLS.CertFile != "" {
+		opts = append(opts, nats.ClientCert(config.TLS.CertFile, config.TLS.KeyFile))
+	}
+	return opts, nil
+}
+
+// ensureStream creates or updates the configured stream so that it captures
+// the subject. Without a configured stream, one must already capture it.
+func (p *NATSPublisher) ensureStream(ctx context.Context) error {
+	if p.stream == "" {
+		return nil
+	}
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	_, err := p.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     p.stream,
+		Subjects: []string{p.subject},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up JetStream stream %q: %w", p.stream, err)
+	}
+	return nil
+}
+
+// reconnectDelay returns how long to wait after the given number of failed
+// reconnect rounds. The delay doubles from reconnectWait up to maxReconnectWait,
+// less a random jitter. NATS counts rounds per disconnection, so the delay
+// starts over once a reconnect succeeds.
+func (p *NATSPublisher) reconnectDelay(attempts int) time.Duration {
+	delay := p.reconnectWait
+	for i := 1; i < attempts && delay < p.maxReconnectWait; i++ {
+		delay *= 2
+	}
+	delay = min(delay, p.maxReconnectWait)
+	return delay - time.Duration(rand.Float64()*reconnectJitter*float64(delay))
+}
+
+// PublishVMEvent publishes a VM phase change event to NATS. While disconnected,
+// the event is buffered for publishing on reconnect when a buffer is configured.
+// While buffered events remain, it is buffered behind them, so that the events
+// of a VM are published in order.
+func (p *NATSPublisher) PublishVMEvent(ctx context.Context, vmEvent VMEvent) error {
+	if !p.IsConnected() || (p.buffer != nil && p.buffer.busy()) {
+		return p.bufferEvent(vmEvent)
+	}
+	if err := p.publish(ctx, vmEvent); err != nil {
+		return err
+	}
+	if p.buffer != nil {
+		p.buffer.supersede(vmEvent)
+	}
+	return nil
+}
+
+// publish sends a VM event over the current connection
+func (p *NATSPublisher) publish(ctx context.Context, vmEvent VMEvent) error {
+	eventData, err := newCloudEvent(vmEvent, p.source, p.subject)
+	if err != nil {
+		return err
+	}
+
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	if p.js == nil {
+		return p.publishCore(ctx, vmEvent, eventData)
+	}
+
+	// Publish to JetStream with acknowledgement
+	_, err = p.js.Publish(ctx, p.subject, eventData)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout) {
+			return fmt.Errorf("%w: %w", ErrFlushTimeout, err)
+		}
+		return fmt.Errorf("%w to JetStream: %w", ErrPublishFailed, err)
+	}
+
+	zap.S().Debugw("Published VM event to JetStream", "vmID", vmEvent.Id, "subject", p.subject)
+	return nil
+}
+
+// publishCore publishes on core NATS, flushing so that a connection that
+// cannot reach the server is reported
+func (p *NATSPublisher) publishCore(ctx context.Context, vmEvent VMEvent, eventData []byte) error {
+	if err := p.natsConn.Publish(p.subject, eventData); err != nil {
+		return fmt.Errorf("%w to NATS: %w", ErrPublishFailed, err)
+	}
+	if err := p.natsConn.FlushWithContext(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, nats.ErrTimeout) {
+			return fmt.Errorf("%w: %w", ErrFlushTimeout, err)
+		}
+		return fmt.Errorf("%w to NATS: %w", ErrPublishFailed, err)
+	}
+
+	zap.S().Debugw("Published VM event to NATS", "vmID", vmEvent.Id, "subject", p.subject)
+	return nil
+}
+
+// bufferEvent keeps an event published while disconnected, or behind other
+// buffered events, for replay once connected. Without a buffer the event is
+// rejected.
+func (p *NATSPublisher) bufferEvent(vmEvent VMEvent) error {
+	if p.buffer == nil {
+		return ErrNotConnected
+	}
+	if p.buffer.add(vmEvent) {
+		zap.S().Warnw("NATS event buffer full, dropped the oldest buffered VM event", "size", p.buffer.size)
+	}
+	zap.S().Debugw("Buffered VM event", "vmID", vmEvent.Id, "connected", p.IsConnected())
+
+	// The connection may have come back after the check, with the buffer already
+	// flushed. The flush publishes every buffered event, so it runs in the
+	// background rather than within the caller's context; a scheduled retry
+	// publishes the event instead.
+	if p.IsConnected() && !p.retryPending.Load() {
+		go p.flushBuffer(p.ctx)
+	}
+	return nil
+}
+
+// flushBuffer publishes the buffered events until none are left. Only one
+// flush runs at a time; events buffered during a flush are published by it.
+// Events that fail to publish end the flush and stay buffered, unless a newer
+// event of the VM arrived. While still connected, the flush is retried after a
+// backoff, since no reconnect will trigger it.
+func (p *NATSPublisher) flushBuffer(ctx context.Context) {
+	if p.buffer == nil || !p.buffer.startFlush() {
+		return
+	}
+
+	for {
+		buffered := p.buffer.drainFlush()
+		if len(buffered) == 0 {
+			p.flushRetries.Store(0)
+			return
+		}
+		zap.S().Infow("Publishing buffered VM events", "count", len(buffered))
+		for i, vmEvent := range buffered {
+			if ctx.Err() != nil || !p.IsConnected() {
+				p.buffer.endFlush(buffered[i:])
+				return
+			}
+			if err := p.publish(ctx, vmEvent); err != nil {
+				zap.S().Warnw("Failed to publish buffered VM event", "vmID", vmEvent.Id, "error", err)
+				p.buffer.endFlush(buffered[i:])
+				p.retryFlush()
+				return
+			}
+		}
+	}
+}
+
+// retryFlush schedules another flush of the buffer after a failed one, backing
+// off like reconnects do. Only one retry is pending at a time.
+func (p *NATSPublisher) retryFlush() {
+	if p.ctx.Err() != nil || !p.retryPending.CompareAndSwap(false, true) {
+		return
+	}
+	delay := p.reconnectDelay(int(p.flushRetries.Add(1)))
+	time.AfterFunc(delay, func() {
+		p.retryPending.Store(false)
+		if p.ctx.Err() == nil {
+			p.flushBuffer(p.ctx)
+		}
+	})
+}
+
+// Close gracefully closes the NATS connection
+func (p *NATSPublisher) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if p.natsConn != nil {
+		p.natsConn.Close()
+	}
+	return nil
+}
+
+// IsConnected returns whether NATS connection is active
+func (p *NATSPublisher) IsConnected() bool {
+	return p.natsConn != nil && p.natsConn.IsConnected()
+}
@@ -4,128 +4,157 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/google/uuid"
-	"github.com/nats-io/nats.go"
-	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Event bus backends a Publisher can be created for
+const (
+	BackendNATS  = "nats"
+	BackendKafka = "kafka"
+)
+
+// DefaultSource is the CloudEvent source of events published without a configured one
+const DefaultSource = "kubevirt.localhost"
+
+// CloudEvent types of VM events
+const (
+	// EventTypeStatus reports a VM phase change
+	EventTypeStatus = "dcm.status.vm"
+	// EventTypeReady reports, once per VM, that it is running and reachable over SSH
+	EventTypeReady = "dcm.vm.ready"
 )
 
 // VMEvent represents a VM status event
 type VMEvent struct {
+	// Type is the CloudEvent type of the event; empty is EventTypeStatus
+	Type      string    `json:"-"`
 	Id        string    `json:"id"`
 	Status    string    `json:"status"`
 	Timestamp time.Time `json:"timestamp"`
+	// Endpoints are the addresses the VM can be reached at, reported once it
+	// is running when endpoint reporting is enabled
+	Endpoints []Endpoint `json:"endpoints,omitempty"`
+	// Connection tells how to reach a ready VM, set on EventTypeReady events
+	Connection *Connection `json:"connection,omitempty"`
 }
 
-// Publisher handles NATS JetStream event publishing with CloudEvents formatting
-type Publisher struct {
-	natsConn     *nats.Conn
-	js           jetstream.JetStream
-	natsURL      string
-	subject      string
-	maxReconnect int
+// Connection tells how to reach a VM that is ready
+type Connection struct {
+	// IP is the first address of the VM
+	IP string `json:"ip"`
+	// SSHCommand connects to the guest SSH port
+	SSHCommand string `json:"ssh_command"`
+	// SSHNodePort is the port opened on every node for the guest SSH port,
+	// when it is published through one
+	SSHNodePort int `json:"ssh_node_port,omitempty"`
 }
 
-// PublisherConfig contains configuration for the event publisher
-type PublisherConfig struct {
-	NATSURL      string
-	Subject      string
-	MaxReconnect int
+// eventType returns the CloudEvent type of the event
+func (e VMEvent) eventType() string {
+	if e.Type == "" {
+		return EventTypeStatus
+	}
+	return e.Type
 }
 
-// NewPublisher creates a new NATS JetStream publisher
-func NewPublisher(config PublisherConfig) (*Publisher, error) {
-	p := &Publisher{
-		natsURL:      config.NATSURL,
-		subject:      config.Subject,
-		maxReconnect: config.MaxReconnect,
-	}
+// Endpoint is an address a VM can be reached at: one of its IPs, or a guest
+// port published by its port Service
+type Endpoint struct {
+	// Type is IP for a VM address, or the type of the Service publishing a port
+	Type string `json:"type"`
+	// Host is the VM IP or the load balancer address, when known
+	Host string `json:"host,omitempty"`
+	// Port is the published guest port
+	Port int `json:"port,omitempty"`
+	// NodePort is the port opened on every node for the guest port
+	NodePort int `json:"node_port,omitempty"`
+	// Protocol of the published port: TCP, UDP or SCTP
+	Protocol string `json:"protocol,omitempty"`
+	// SSHCommand connects to the guest SSH port, when it is reachable at a known host
+	SSHCommand string `json:"ssh_command,omitempty"`
+}
 
-	if err := p.connect(); err != nil {
-		return nil, fmt.Errorf("failed to create NATS publisher: %w", err)
-	}
+// Publisher publishes VM events as CloudEvents to an event bus
+type Publisher interface {
+	// PublishVMEvent publishes a VM phase change event
+	PublishVMEvent(ctx context.Context, vmEvent VMEvent) error
+	// IsConnected returns whether events can currently be published
+	IsConnected() bool
+	// Close releases the connection to the event bus
+	Close() error
+}
 
-	return p, nil
+// PublisherConfig selects the event bus backend and holds its configuration
+type PublisherConfig struct {
+	// Backend is either nats or kafka; empty selects nats
+	Backend string
+	// Source identifies this provider instance as the CloudEvent source of
+	// every event, overriding the source of the backend configuration
+	Source string
+	NATS   NATSPublisherConfig
+	Kafka  KafkaPublisherConfig
 }
 
-// connect establishes connection to NATS server and sets up JetStream
-func (p *Publisher) connect() error {
-	opts := []nats.Option{
-		nats.ReconnectWait(2 * time.Second),
-		nats.MaxReconnects(p.maxReconnect),
-		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
-			log.Printf("NATS disconnected: %v", err)
-		}),
-		nats.ReconnectHandler(func(nc *nats.Conn) {
-			log.Printf("NATS reconnected to %v", nc.ConnectedUrl())
-		}),
-		nats.ClosedHandler(func(nc *nats.Conn) {
-			log.Printf("NATS connection closed")
-		}),
+// NewPublisher creates a publisher for the configured backend
+func NewPublisher(config PublisherConfig) (Publisher, error) {
+	if config.Source != "" {
+		config.NATS.Source = config.Source
+		config.Kafka.Source = config.Source
 	}
 
-	nc, err := nats.Connect(p.natsURL, opts...)
-	if err != nil {
-		return fmt.Errorf("failed to connect to NATS: %w", err)
+	// Return a nil interface rather than a typed nil on failure
+	switch strings.ToLower(config.Backend) {
+	case "", BackendNATS:
+		p, err := NewNATSPublisher(config.NATS)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	case BackendKafka:
+		p, err := NewKafkaPublisher(config.Kafka)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unknown event backend %q", config.Backend)
 	}
-	p.natsConn = nc
+}
 
-	js, err := jetstream.New(nc)
-	if err != nil {
-		nc.Close()
-		return fmt.Errorf("failed to create JetStream context: %w", err)
+// validateSource checks that a CloudEvent source is a URI reference
+func validateSource(source string) error {
+	if _, err := url.Parse(source); err != nil {
+		return fmt.Errorf("invalid event source %q: %w", source, err)
 	}
-	p.js = js
-
-	log.Printf("Connected to NATS, publishing to subject %q", p.subject)
 	return nil
 }
 
-// PublishVMEvent publishes a VM phase change event to NATS JetStream
-func (p *Publisher) PublishVMEvent(ctx context.Context, vmEvent VMEvent) error {
-	if !p.IsConnected() {
-		return fmt.Errorf("NATS connection not available")
+// newCloudEvent encodes a VM event as a CloudEvent. Every backend publishes
+// the same payload; subject names the subject or topic it is published to.
+func newCloudEvent(vmEvent VMEvent, source, subject string) ([]byte, error) {
+	if source == "" {
+		source = DefaultSource
 	}
 
-	// Create CloudEvent
 	event := cloudevents.NewEvent()
 	event.SetID(uuid.New().String())
-	event.SetType("dcm.status.vm")
-	event.SetSource("kubevirt.localhost") // TODO: change to the actual source
-	event.SetSubject(p.subject)
+	event.SetType(vmEvent.eventType())
+	event.SetSource(source)
+	event.SetSubject(subject)
 	event.SetTime(vmEvent.Timestamp)
 
 	if err := event.SetData(cloudevents.ApplicationJSON, vmEvent); err != nil {
-		return fmt.Errorf("failed to set CloudEvent data: %w", err)
+		return nil, fmt.Errorf("%w: failed to set CloudEvent data: %w", ErrMarshal, err)
 	}
 
 	eventData, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal CloudEvent: %w", err)
+		return nil, fmt.Errorf("%w: failed to marshal CloudEvent: %w", ErrMarshal, err)
 	}
-
-	// Publish to JetStream with acknowledgement
-	_, err = p.js.Publish(ctx, p.subject, eventData)
-	if err != nil {
-		return fmt.Errorf("failed to publish event to JetStream: %w", err)
-	}
-
-	log.Printf("Successfully published VM event for %s to JetStream subject %s", vmEvent.Id, p.subject)
-	return nil
-}
-
-// Close gracefully closes the NATS connection
-func (p *Publisher) Close() error {
-	if p.natsConn != nil {
-		p.natsConn.Close()
-	}
-	return nil
-}
-
-// IsConnected returns whether NATS connection is active
-func (p *Publisher) IsConnected() bool {
-	return p.natsConn != nil && p.natsConn.IsConnected()
+	return eventData, nil
 }
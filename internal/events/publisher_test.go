@@ -2,9 +2,23 @@ package events
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -14,43 +28,494 @@ func TestEvents(t *testing.T) {
 	RunSpecs(t, "Events Suite")
 }
 
+// fakeConn is a NATS connection with a fixed connection state that records
+// core NATS publishes
+type fakeConn struct {
+	connected bool
+	flushErr  error
+	published []string
+}
+
+func (c *fakeConn) IsConnected() bool { return c.connected }
+func (c *fakeConn) Close()            { c.connected = false }
+
+func (c *fakeConn) Publish(subject string, _ []byte) error {
+	c.published = append(c.published, subject)
+	return nil
+}
+
+func (c *fakeConn) FlushWithContext(_ context.Context) error { return c.flushErr }
+
+// fakeJetStream fails or acknowledges publishes with publishErr, fails the
+// first failures publishes, or never acknowledges them when blocked, and
+// records the stream it is asked to set up. onPublish, when set, runs before
+// each publish is handled.
+type fakeJetStream struct {
+	jetstream.JetStream
+	publishErr   error
+	failures     int
+	blocked      bool
+	onPublish    func()
+	streamConfig *jetstream.StreamConfig
+
+	mu        sync.Mutex
+	published [][]byte
+}
+
+func (j *fakeJetStream) Publish(ctx context.Context, _ string, data []byte, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if onPublish := j.onPublish; onPublish != nil {
+		j.onPublish = nil
+		onPublish()
+	}
+	if j.blocked {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if j.publishErr != nil {
+		return nil, j.publishErr
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.failures > 0 {
+		j.failures--
+		return nil, nats.ErrNoResponders
+	}
+	j.published = append(j.published, data)
+	return &jetstream.PubAck{}, nil
+}
+
+func (j *fakeJetStream) CreateOrUpdateStream(_ context.Context, cfg jetstream.StreamConfig) (jetstream.Stream, error) {
+	j.streamConfig = &cfg
+	return nil, nil
+}
+
 var _ = Describe("Publisher", func() {
 	Describe("IsConnected", func() {
 		It("should return false when natsConn is nil", func() {
-			p := &Publisher{}
+			p := &NATSPublisher{}
 			Expect(p.IsConnected()).To(BeFalse())
 		})
 	})
 
 	Describe("Close", func() {
 		It("should return no error when natsConn is nil", func() {
-			p := &Publisher{}
+			p := &NATSPublisher{}
 			Expect(p.Close()).NotTo(HaveOccurred())
 		})
 	})
 
 	Describe("PublishVMEvent", func() {
 		It("should return not-connected error when natsConn is nil", func() {
-			p := &Publisher{}
+			p := &NATSPublisher{}
 			err := p.PublishVMEvent(context.Background(), VMEvent{
 				Id:        "test-id",
 				Status:    "Running",
 				Timestamp: time.Now(),
 			})
-			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(ContainSubstring("not available"))
+			Expect(err).To(MatchError(ErrNotConnected))
+		})
+
+		DescribeTable("should return typed errors for each failure",
+			func(event VMEvent, publishErr error, expected error) {
+				p := &NATSPublisher{
+					natsConn: &fakeConn{connected: true},
+					js:       &fakeJetStream{publishErr: publishErr},
+					subject:  "test.subject",
+				}
+
+				err := p.PublishVMEvent(context.Background(), event)
+				Expect(errors.Is(err, expected)).To(BeTrue(), "got %v", err)
+				if publishErr != nil {
+					Expect(errors.Is(err, publishErr)).To(BeTrue())
+				}
+			},
+			Entry("unencodable event", VMEvent{Id: "test-id", Timestamp: time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC)}, nil, ErrMarshal),
+			Entry("rejected publish", VMEvent{Id: "test-id", Timestamp: time.Now()}, nats.ErrNoResponders, ErrPublishFailed),
+			Entry("acknowledgement timeout", VMEvent{Id: "test-id", Timestamp: time.Now()}, nats.ErrTimeout, ErrFlushTimeout),
+			Entry("context deadline", VMEvent{Id: "test-id", Timestamp: time.Now()}, context.DeadlineExceeded, ErrFlushTimeout),
+		)
+
+		It("should publish when connected", func() {
+			p := &NATSPublisher{
+				natsConn: &fakeConn{connected: true},
+				js:       &fakeJetStream{},
+				subject:  "test.subject",
+			}
+
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "test-id", Timestamp: time.Now()})).To(Succeed())
+		})
+
+		It("should time out waiting for the acknowledgement", func() {
+			p := &NATSPublisher{
+				natsConn: &fakeConn{connected: true},
+				js:       &fakeJetStream{blocked: true},
+				subject:  "test.subject",
+				timeout:  10 * time.Millisecond,
+			}
+
+			err := p.PublishVMEvent(context.Background(), VMEvent{Id: "test-id", Timestamp: time.Now()})
+			Expect(errors.Is(err, ErrFlushTimeout)).To(BeTrue(), "got %v", err)
+		})
+
+		Context("without JetStream", func() {
+			It("should publish on core NATS", func() {
+				conn := &fakeConn{connected: true}
+				p := &NATSPublisher{natsConn: conn, subject: "test.subject"}
+
+				Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "test-id", Timestamp: time.Now()})).To(Succeed())
+				Expect(conn.published).To(Equal([]string{"test.subject"}))
+			})
+
+			It("should report a flush timeout", func() {
+				p := &NATSPublisher{
+					natsConn: &fakeConn{connected: true, flushErr: nats.ErrTimeout},
+					subject:  "test.subject",
+				}
+
+				err := p.PublishVMEvent(context.Background(), VMEvent{Id: "test-id", Timestamp: time.Now()})
+				Expect(errors.Is(err, ErrFlushTimeout)).To(BeTrue(), "got %v", err)
+			})
+		})
+	})
+
+	Describe("buffering while disconnected", func() {
+		var (
+			conn *fakeConn
+			js   *fakeJetStream
+			p    *NATSPublisher
+		)
+
+		// publishedStatuses decodes the VM ID and status of every published event
+		publishedStatuses := func() []string {
+			js.mu.Lock()
+			defer js.mu.Unlock()
+			var statuses []string
+			for _, data := range js.published {
+				var cloudEvent struct {
+					Data VMEvent `json:"data"`
+				}
+				Expect(json.Unmarshal(data, &cloudEvent)).To(Succeed())
+				statuses = append(statuses, cloudEvent.Data.Id+"="+cloudEvent.Data.Status)
+			}
+			return statuses
+		}
+
+		BeforeEach(func() {
+			conn = &fakeConn{connected: false}
+			js = &fakeJetStream{}
+			p = &NATSPublisher{
+				natsConn:         conn,
+				js:               js,
+				subject:          "dcm.vm",
+				buffer:           newEventBuffer(2),
+				reconnectWait:    time.Hour,
+				maxReconnectWait: time.Hour,
+				ctx:              context.Background(),
+			}
+		})
+
+		It("should publish the latest event of each VM on reconnect", func() {
+			for _, event := range []VMEvent{
+				{Id: "vm-a", Status: "Pending"},
+				{Id: "vm-b", Status: "Pending"},
+				{Id: "vm-a", Status: "Running"},
+			} {
+				Expect(p.PublishVMEvent(context.Background(), event)).To(Succeed())
+			}
+			Expect(js.published).To(BeEmpty())
+
+			conn.connected = true
+			p.flushBuffer(context.Background())
+
+			Expect(publishedStatuses()).To(Equal([]string{"vm-b=Pending", "vm-a=Running"}))
+			Expect(p.buffer.len()).To(BeZero())
+		})
+
+		It("should not let a status event replace the ready event of a VM", func() {
+			for _, event := range []VMEvent{
+				{Type: EventTypeReady, Id: "vm-a", Status: "Running"},
+				{Id: "vm-a", Status: "Stopped"},
+			} {
+				Expect(p.PublishVMEvent(context.Background(), event)).To(Succeed())
+			}
+
+			conn.connected = true
+			p.flushBuffer(context.Background())
+
+			Expect(publishedStatuses()).To(Equal([]string{"vm-a=Running", "vm-a=Stopped"}))
+		})
+
+		It("should drop the oldest VM when the buffer overflows", func() {
+			for _, id := range []string{"vm-a", "vm-b", "vm-c"} {
+				Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: id, Status: "Running"})).To(Succeed())
+			}
+
+			conn.connected = true
+			p.flushBuffer(context.Background())
+
+			Expect(publishedStatuses()).To(Equal([]string{"vm-b=Running", "vm-c=Running"}))
+		})
+
+		It("should keep events that fail to publish for the next reconnect", func() {
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-a", Status: "Running"})).To(Succeed())
+
+			conn.connected = true
+			js.publishErr = nats.ErrNoResponders
+			p.flushBuffer(context.Background())
+			Expect(p.buffer.len()).To(Equal(1))
+
+			js.publishErr = nil
+			p.flushBuffer(context.Background())
+			Expect(publishedStatuses()).To(Equal([]string{"vm-a=Running"}))
+		})
+
+		It("should retry a failed flush while still connected", func() {
+			p.reconnectWait = 10 * time.Millisecond
+			p.maxReconnectWait = 10 * time.Millisecond
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-a", Status: "Pending"})).To(Succeed())
+
+			conn.connected = true
+			js.failures = 1
+			p.flushBuffer(context.Background())
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-b", Status: "Running"})).To(Succeed())
+
+			Eventually(publishedStatuses).Should(Equal([]string{"vm-a=Pending", "vm-b=Running"}))
+			Eventually(p.buffer.busy).Should(BeFalse())
+		})
+
+		It("should flush behind buffered events without blocking the caller", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			p.ctx = ctx
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-a", Status: "Pending"})).To(Succeed())
+
+			conn.connected = true
+			js.blocked = true
+			published := make(chan error, 1)
+			go func() {
+				published <- p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-b", Status: "Running"})
+			}()
+			Eventually(published).Should(Receive(BeNil()))
+		})
+
+		It("should publish an event arriving during a flush after the older buffered ones", func() {
+			for _, id := range []string{"vm-a", "vm-b"} {
+				Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: id, Status: "Pending"})).To(Succeed())
+			}
+
+			conn.connected = true
+			js.onPublish = func() {
+				Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-b", Status: "Running"})).To(Succeed())
+			}
+			p.flushBuffer(context.Background())
+
+			Expect(publishedStatuses()).To(Equal([]string{"vm-a=Pending", "vm-b=Pending", "vm-b=Running"}))
+			Expect(p.buffer.busy()).To(BeFalse())
+		})
+
+		It("should publish directly once the buffer is flushed", func() {
+			conn.connected = true
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-a", Status: "Running"})).To(Succeed())
+
+			Expect(publishedStatuses()).To(Equal([]string{"vm-a=Running"}))
+			Expect(p.buffer.len()).To(BeZero())
+		})
+
+		It("should stop flushing once the publisher is closed", func() {
+			Expect(p.PublishVMEvent(context.Background(), VMEvent{Id: "vm-a", Status: "Running"})).To(Succeed())
+
+			conn.connected = true
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			p.flushBuffer(ctx)
+
+			Expect(js.published).To(BeEmpty())
+			Expect(p.buffer.len()).To(Equal(1))
+		})
+	})
+
+	Describe("eventBuffer", func() {
+		It("should drop a buffered event superseded by a newer published one", func() {
+			now := time.Now()
+			buffer := newEventBuffer(2)
+			buffer.add(VMEvent{Id: "vm-a", Status: "Pending", Timestamp: now})
+			buffer.add(VMEvent{Type: EventTypeReady, Id: "vm-a", Status: "Running", Timestamp: now})
+
+			buffer.supersede(VMEvent{Id: "vm-a", Status: "Running", Timestamp: now.Add(time.Second)})
+			Expect(buffer.len()).To(Equal(1))
+
+			buffer.supersede(VMEvent{Type: EventTypeReady, Id: "vm-a", Status: "Running", Timestamp: now.Add(-time.Second)})
+			Expect(buffer.len()).To(Equal(1))
+		})
+	})
+
+	Describe("natsSecurityOptions", func() {
+		// apply applies connection options to the NATS defaults
+		apply := func(opts []nats.Option) nats.Options {
+			o := nats.GetDefaultOptions()
+			for _, opt := range opts {
+				Expect(opt(&o)).To(Succeed())
+			}
+			return o
+		}
+
+		// writeCertificate writes a self-signed certificate and its key as PEM files
+		writeCertificate := func() (certFile, keyFile string) {
+			key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			template := &x509.Certificate{
+				SerialNumber:          big.NewInt(1),
+				Subject:               pkix.Name{CommonName: "nats-test"},
+				NotBefore:             time.Now(),
+				NotAfter:              time.Now().Add(time.Hour),
+				IsCA:                  true,
+				BasicConstraintsValid: true,
+			}
+			der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+			Expect(err).NotTo(HaveOccurred())
+			keyDER, err := x509.MarshalECPrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+
+			dir := GinkgoT().TempDir()
+			certFile = filepath.Join(dir, "cert.pem")
+			keyFile = filepath.Join(dir, "key.pem")
+			Expect(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)).To(Succeed())
+			Expect(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)).To(Succeed())
+			return certFile, keyFile
+		}
+
+		It("should add no options without TLS or credentials", func() {
+			opts, err := natsSecurityOptions(NATSPublisherConfig{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(opts).To(BeEmpty())
+		})
+
+		It("should authenticate with a user and password", func() {
+			opts, err := natsSecurityOptions(NATSPublisherConfig{User: "dcm", Password: "s3cret"})
+			Expect(err).NotTo(HaveOccurred())
+
+			o := apply(opts)
+			Expect(o.User).To(Equal("dcm"))
+			Expect(o.Password).To(Equal("s3cret"))
+			Expect(o.Secure).To(BeFalse())
+		})
+
+		It("should authenticate with a token", func() {
+			opts, err := natsSecurityOptions(NATSPublisherConfig{Token: "t0ken"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apply(opts).Token).To(Equal("t0ken"))
+		})
+
+		It("should authenticate with a credentials file", func() {
+			// The seed is only read when signing the server nonce on connect
+			credsFile := filepath.Join(GinkgoT().TempDir(), "user.creds")
+			Expect(os.WriteFile(credsFile, []byte(
+				"-----BEGIN NATS USER JWT-----\neyJ0eXAiOiJKV1QifQ.e30.c2ln\n------END NATS USER JWT------\n"), 0o600)).To(Succeed())
+
+			opts, err := natsSecurityOptions(NATSPublisherConfig{CredsFile: credsFile})
+			Expect(err).NotTo(HaveOccurred())
+
+			o := apply(opts)
+			Expect(o.UserJWT).NotTo(BeNil())
+			Expect(o.SignatureCB).NotTo(BeNil())
+		})
+
+		It("should verify the server with the configured CA and present a client certificate", func() {
+			certFile, keyFile := writeCertificate()
+			opts, err := natsSecurityOptions(NATSPublisherConfig{
+				Token: "t0ken",
+				TLS:   &NATSTLSConfig{CAFile: certFile, CertFile: certFile, KeyFile: keyFile},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			o := apply(opts)
+			Expect(o.Secure).To(BeTrue())
+			Expect(o.TLSConfig.InsecureSkipVerify).To(BeFalse())
+			Expect(o.RootCAsCB).NotTo(BeNil())
+			Expect(o.TLSCertCB).NotTo(BeNil())
+			Expect(o.Token).To(Equal("t0ken"))
+		})
+
+		It("should skip server verification when asked to", func() {
+			opts, err := natsSecurityOptions(NATSPublisherConfig{TLS: &NATSTLSConfig{InsecureSkipVerify: true}})
+			Expect(err).NotTo(HaveOccurred())
+
+			o := apply(opts)
+			Expect(o.Secure).To(BeTrue())
+			Expect(o.TLSConfig.InsecureSkipVerify).To(BeTrue())
+			Expect(o.RootCAsCB).To(BeNil())
+		})
+
+		DescribeTable("should reject conflicting settings",
+			func(config NATSPublisherConfig, message string) {
+				_, err := natsSecurityOptions(config)
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("password without user", NATSPublisherConfig{Password: "s3cret"}, "requires a user"),
+			Entry("user and token", NATSPublisherConfig{User: "dcm", Token: "t0ken"}, "only one of"),
+			Entry("token and credentials file", NATSPublisherConfig{Token: "t0ken", CredsFile: "user.creds"}, "only one of"),
+			Entry("certificate without key", NATSPublisherConfig{TLS: &NATSTLSConfig{CertFile: "cert.pem"}}, "set together"),
+		)
+	})
+
+	Describe("ensureStream", func() {
+		It("should capture the subject in a file-backed stream", func() {
+			js := &fakeJetStream{}
+			p := &NATSPublisher{js: js, subject: "dcm.vm", stream: "DCM_VM"}
+
+			Expect(p.ensureStream(context.Background())).To(Succeed())
+			Expect(js.streamConfig).NotTo(BeNil())
+			Expect(js.streamConfig.Name).To(Equal("DCM_VM"))
+			Expect(js.streamConfig.Subjects).To(Equal([]string{"dcm.vm"}))
+			Expect(js.streamConfig.Storage).To(Equal(jetstream.FileStorage))
+		})
+
+		It("should leave streams alone when none is configured", func() {
+			js := &fakeJetStream{}
+			p := &NATSPublisher{js: js, subject: "dcm.vm"}
+
+			Expect(p.ensureStream(context.Background())).To(Succeed())
+			Expect(js.streamConfig).To(BeNil())
+		})
+	})
+
+	Describe("reconnectDelay", func() {
+		It("should double the delay after each failed round up to the cap", func() {
+			p := &NATSPublisher{reconnectWait: time.Second, maxReconnectWait: 30 * time.Second}
+
+			expected := []time.Duration{1, 2, 4, 8, 16, 30, 30, 30}
+			for i, base := range expected {
+				delay := p.reconnectDelay(i + 1)
+				Expect(delay).To(BeNumerically("<=", base*time.Second), "attempt %d", i+1)
+				Expect(delay).To(BeNumerically(">=", time.Duration(float64(base*time.Second)*(1-reconnectJitter))), "attempt %d", i+1)
+			}
+		})
+
+		It("should stay capped after many failed rounds", func() {
+			p := &NATSPublisher{reconnectWait: time.Second, maxReconnectWait: 30 * time.Second}
+			Expect(p.reconnectDelay(1000)).To(BeNumerically("<=", 30*time.Second))
+		})
+
+		It("should jitter the delay", func() {
+			p := &NATSPublisher{reconnectWait: time.Second, maxReconnectWait: 30 * time.Second}
+			delays := map[time.Duration]bool{}
+			for range 20 {
+				delays[p.reconnectDelay(3)] = true
+			}
+			Expect(len(delays)).To(BeNumerically(">", 1))
 		})
 	})
 
 	Describe("NewPublisher", func() {
 		It("should return error when NATS server is unreachable", func() {
-			_, err := NewPublisher(PublisherConfig{
+			_, err := NewNATSPublisher(NATSPublisherConfig{
 				NATSURL:      "nats://127.0.0.1:14222",
 				Subject:      "test.subject",
 				MaxReconnect: 0,
 			})
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(ContainSubstring("failed to create NATS publisher"))
+			Expect(err).To(MatchError(ErrNotConnected))
 		})
 	})
 })
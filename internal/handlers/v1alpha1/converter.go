@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 
+	k8sv1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
 	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
 	"github.com/dcm-project/kubevirt-service-provider/internal/api/server"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
 )
 
 func vmSpecToServerVM(vmSpec *types.VMSpec, path *string, id string) (*server.VM, error) {
@@ -19,7 +23,7 @@ func vmSpecToServerVM(vmSpec *types.VMSpec, path *string, id string) (*server.VM
 	}
 
 	var serverVM server.VM
-	if err := json.Unmarshal(data, &serverVM); err != nil {
+	if err := json.Unmarshal(data, &serverVM.Spec); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal to server.VM: %w", err)
 	}
 
@@ -45,3 +49,101 @@ func createVMRequestToVMSpec(createVM *server.CreateVMJSONRequestBody) (*types.V
 
 	return &vmSpec, nil
 }
+
+// connectMethods lists the guest ports published by a port Service, or nil when
+// there is none. Load balancer addresses are included once they are assigned.
+func connectMethods(service *k8sv1.Service) *[]server.ConnectMethod {
+	if service == nil || len(service.Spec.Ports) == 0 {
+		return nil
+	}
+
+	var host *string
+	switch service.Spec.Type {
+	case k8sv1.ServiceTypeLoadBalancer:
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			addr := ingress.IP
+			if addr == "" {
+				addr = ingress.Hostname
+			}
+			if addr != "" {
+				host = &addr
+				break
+			}
+		}
+	case k8sv1.ServiceTypeClusterIP:
+		if addr := service.Spec.ClusterIP; addr != "" && addr != k8sv1.ClusterIPNone {
+			host = &addr
+		}
+	}
+
+	methods := make([]server.ConnectMethod, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		method := server.ConnectMethod{
+			Type:     server.ConnectMethodType(service.Spec.Type),
+			Protocol: server.ConnectMethodProtocol(port.Protocol),
+			Port:     port.TargetPort.IntValue(),
+			Host:     host,
+		}
+		if port.NodePort != 0 {
+			nodePort := int(port.NodePort)
+			method.NodePort = &nodePort
+		}
+		methods = append(methods, method)
+	}
+	return &methods
+}
+
+// vmConditions lists the conditions KubeVirt reports for a VM, or nil when
+// there are none
+func vmConditions(vm *kubevirtv1.VirtualMachine) *[]server.VMCondition {
+	if len(vm.Status.Conditions) == 0 {
+		return nil
+	}
+
+	conditions := make([]server.VMCondition, 0, len(vm.Status.Conditions))
+	for _, c := range vm.Status.Conditions {
+		condition := server.VMCondition{
+			Type:   string(c.Type),
+			Status: server.VMConditionStatus(c.Status),
+		}
+		if c.Reason != "" {
+			reason := c.Reason
+			condition.Reason = &reason
+		}
+		if c.Message != "" {
+			message := c.Message
+			condition.Message = &message
+		}
+		if !c.LastTransitionTime.IsZero() {
+			transition := c.LastTransitionTime.UTC()
+			condition.LastTransitionTime = &transition
+		}
+		conditions = append(conditions, condition)
+	}
+	return &conditions
+}
+
+// vmConnection returns the connection info recorded on a VM once it was
+// running, or nil when none was recorded
+func vmConnection(vm *kubevirtv1.VirtualMachine) *server.VMConnection {
+	info := kubevirt.VirtualMachineConnectionInfo(vm)
+	if info == nil {
+		return nil
+	}
+
+	connection := &server.VMConnection{}
+	if info.IP != "" {
+		connection.Ip = &info.IP
+	}
+	if info.SSHUser != "" {
+		connection.SshUser = &info.SSHUser
+	}
+	if info.SSHSecretName != "" {
+		connection.SshSecretName = &info.SSHSecretName
+	}
+	if info.SSHNodePort != 0 {
+		nodePort := int(info.SSHNodePort)
+		connection.SshNodePort = &nodePort
+	}
+	return connection
+}
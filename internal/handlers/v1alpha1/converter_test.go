@@ -3,11 +3,74 @@ package v1alpha1
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/dcm-project/kubevirt-service-provider/internal/api/server"
 )
 
 var _ = Describe("Converters", func() {
+	Describe("connectMethods", func() {
+		sshService := func(serviceType k8sv1.ServiceType) *k8sv1.Service {
+			return &k8sv1.Service{
+				Spec: k8sv1.ServiceSpec{
+					Type:      serviceType,
+					ClusterIP: "10.96.0.20",
+					Ports: []k8sv1.ServicePort{{
+						Protocol:   k8sv1.ProtocolTCP,
+						Port:       22,
+						TargetPort: intstr.FromInt32(22),
+					}},
+				},
+			}
+		}
+
+		It("should report the node port of a NodePort service", func() {
+			service := sshService(k8sv1.ServiceTypeNodePort)
+			service.Spec.Ports[0].NodePort = 30022
+
+			nodePort := 30022
+			Expect(connectMethods(service)).To(HaveValue(ConsistOf(
+				server.ConnectMethod{Type: server.NodePort, Protocol: server.TCP, Port: 22, NodePort: &nodePort},
+			)))
+		})
+
+		It("should report the guest port and the allocated node port of a custom SSH target", func() {
+			service := sshService(k8sv1.ServiceTypeNodePort)
+			service.Spec.Ports[0].TargetPort = intstr.FromInt32(2222)
+			service.Spec.Ports[0].NodePort = 31022
+
+			nodePort := 31022
+			Expect(connectMethods(service)).To(HaveValue(ConsistOf(
+				server.ConnectMethod{Type: server.NodePort, Protocol: server.TCP, Port: 2222, NodePort: &nodePort},
+			)))
+		})
+
+		It("should report the ingress of a LoadBalancer service once assigned", func() {
+			service := sshService(k8sv1.ServiceTypeLoadBalancer)
+			Expect(connectMethods(service)).To(HaveValue(ConsistOf(
+				server.ConnectMethod{Type: server.LoadBalancer, Protocol: server.TCP, Port: 22},
+			)))
+
+			service.Status.LoadBalancer.Ingress = []k8sv1.LoadBalancerIngress{{Hostname: "vm.example.com"}}
+			host := "vm.example.com"
+			Expect(connectMethods(service)).To(HaveValue(ConsistOf(
+				server.ConnectMethod{Type: server.LoadBalancer, Protocol: server.TCP, Port: 22, Host: &host},
+			)))
+		})
+
+		It("should report the cluster IP of a ClusterIP service", func() {
+			host := "10.96.0.20"
+			Expect(connectMethods(sshService(k8sv1.ServiceTypeClusterIP))).To(HaveValue(ConsistOf(
+				server.ConnectMethod{Type: server.ClusterIP, Protocol: server.TCP, Port: 22, Host: &host},
+			)))
+		})
+
+		It("should report nothing without a service", func() {
+			Expect(connectMethods(nil)).To(BeNil())
+		})
+	})
+
 	Describe("vmSpecToServerVM", func() {
 		It("should return error for nil input", func() {
 			result, err := vmSpecToServerVM(nil, nil, "")
@@ -29,6 +92,17 @@ var _ = Describe("Converters", func() {
 			Expect(*result.Path).To(Equal(path))
 		})
 
+		It("should carry the VMSpec fields into the spec", func() {
+			vmSpec := newTestVMSpec()
+
+			result, err := vmSpecToServerVM(vmSpec, nil, "")
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Spec.GuestOs.Type).To(Equal(vmSpec.GuestOs.Type))
+			Expect(result.Spec.Vcpu.Count).To(Equal(vmSpec.Vcpu.Count))
+			Expect(result.Spec.Memory.Size).To(Equal(vmSpec.Memory.Size))
+		})
+
 		It("should handle invalid UUID gracefully", func() {
 			vmSpec := newTestVMSpec()
 			path := "/api/v1alpha1/vms/not-a-uuid"
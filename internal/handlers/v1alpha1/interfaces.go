@@ -3,23 +3,55 @@ package v1alpha1
 import (
 	"context"
 
+	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
 )
 
 // VMClient defines the operations the handler needs from a KubeVirt client.
 type VMClient interface {
 	CreateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
 	GetVirtualMachine(ctx context.Context, vmID string) (*kubevirtv1.VirtualMachine, error)
-	ListVirtualMachines(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error)
-	DeleteVirtualMachine(ctx context.Context, vmID string) error
+	ListVirtualMachines(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error)
+	DeleteVirtualMachine(ctx context.Context, vmID string, gracePeriodSeconds *int64) error
+	StartVirtualMachine(ctx context.Context, vmID string) error
+	StopVirtualMachine(ctx context.Context, vmID string) error
+	RestartVirtualMachine(ctx context.Context, vmID string) error
+	GetVirtualMachineUsage(ctx context.Context, vmID string) (*kubevirt.ResourceUsage, error)
 	UpdateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
+	CheckNamespaceAccess(ctx context.Context) error
+	ResolveNodePool(ctx context.Context, vm *kubevirtv1.VirtualMachine) error
+	CheckDataVolumeSources(ctx context.Context, vm *kubevirtv1.VirtualMachine) error
+	CheckCapacity(ctx context.Context, vm *kubevirtv1.VirtualMachine) error
+	GetDiskImports(ctx context.Context, vm *kubevirtv1.VirtualMachine) ([]kubevirt.DiskImport, error)
+	CreateSecret(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error)
+	GetSecret(ctx context.Context, name string) (*k8sv1.Secret, error)
+	UpdateSecret(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error)
+	DeleteSecret(ctx context.Context, name string) error
+	DeleteSecretsByInstanceID(ctx context.Context, vmID string) error
+	CreateService(ctx context.Context, service *k8sv1.Service) (*k8sv1.Service, error)
+	GetService(ctx context.Context, name string) (*k8sv1.Service, error)
+	ListServices(ctx context.Context, vmID string) ([]k8sv1.Service, error)
+	DeleteService(ctx context.Context, name string) error
+	CreatePersistentVolumeClaim(ctx context.Context, claim *k8sv1.PersistentVolumeClaim) (*k8sv1.PersistentVolumeClaim, error)
 }
 
 // VMMapper defines the operations the handler needs for VM spec conversion.
 type VMMapper interface {
 	VMSpecToVirtualMachine(vmSpec *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error)
 	VirtualMachineToVMSpec(vm *kubevirtv1.VirtualMachine) (*types.VMSpec, error)
+	ResizeVirtualMachine(vm *kubevirtv1.VirtualMachine, vcpu *int, memory *string) (bool, error)
+	Secrets(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.Secret, error)
+	PortService(vmSpec *types.VMSpec, vmID string) (*k8sv1.Service, error)
+	SubdomainService(vmSpec *types.VMSpec) (*k8sv1.Service, error)
+	PersistentVolumeClaims(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.PersistentVolumeClaim, error)
+}
+
+// VMPolicy decides whether a VM may be created. Check returns the reasons the
+// request is rejected, or none when it is allowed.
+type VMPolicy interface {
+	Check(ctx context.Context, vmSpec *types.VMSpec) []string
 }
@@ -1,10 +1,16 @@
 package v1alpha1
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"maps"
 
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
@@ -15,18 +21,65 @@ import (
 
 const (
 	APIPrefix = "/api/v1alpha1/"
+
+	// defaultPageSize is the number of VMs listed per page unless a smaller
+	// max_page_size is requested; it also bounds larger requests
+	defaultPageSize = 100
 )
 
 type KubevirtHandler struct {
 	kubevirtClient VMClient
 	mapper         VMMapper
+	policy         VMPolicy
+	provenance     *kubevirt.Provenance
+	capabilities   kubevirt.Capabilities
+
+	allowDuplicateHostnames bool
+}
+
+// HandlerOption configures optional KubevirtHandler behavior
+type HandlerOption func(*KubevirtHandler)
+
+// SetPolicy sets the policy create requests are checked against before any
+// cluster call
+func SetPolicy(p VMPolicy) HandlerOption {
+	return func(s *KubevirtHandler) {
+		s.policy = p
+	}
+}
+
+// SetProvenance annotates created VMs with the originating request ID and the
+// given provider and schema versions; nil disables the annotations
+func SetProvenance(p *kubevirt.Provenance) HandlerOption {
+	return func(s *KubevirtHandler) {
+		s.provenance = p
+	}
 }
 
-func NewKubevirtHandler(kubevirtClient VMClient, mapper VMMapper) *KubevirtHandler {
-	return &KubevirtHandler{
+// SetCapabilities sets the capabilities reported by GetCapabilities
+func SetCapabilities(c kubevirt.Capabilities) HandlerOption {
+	return func(s *KubevirtHandler) {
+		s.capabilities = c
+	}
+}
+
+// SetAllowDuplicateHostnames lets VMs share a hostname within a DNS subdomain,
+// which CreateVM otherwise rejects with 409 Conflict
+func SetAllowDuplicateHostnames(allowed bool) HandlerOption {
+	return func(s *KubevirtHandler) {
+		s.allowDuplicateHostnames = allowed
+	}
+}
+
+func NewKubevirtHandler(kubevirtClient VMClient, mapper VMMapper, opts ...HandlerOption) *KubevirtHandler {
+	s := &KubevirtHandler{
 		kubevirtClient: kubevirtClient,
 		mapper:         mapper,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // kubevirtVMToServerVM converts a typed KubeVirt VM to the API server.VM type.
@@ -52,11 +105,18 @@ func (s *KubevirtHandler) kubevirtVMToServerVM(vm *kubevirtv1.VirtualMachine) (*
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert VMSpec to server VM: %w", err)
 	}
+	serverVM.Connection = vmConnection(vm)
 	return serverVM, nil
 }
 
 // (GET /health)
 func (s *KubevirtHandler) GetHealth(ctx context.Context, request server.GetHealthRequestObject) (server.GetHealthResponseObject, error) {
+	if err := s.kubevirtClient.CheckNamespaceAccess(ctx); err != nil {
+		zap.S().Warnw("Health check failed", "error", err)
+		body, _ := kubevirt.ServiceUnavailableError(err.Error())
+		return server.GetHealth503ApplicationProblemPlusJSONResponse(body), nil
+	}
+
 	status := "ok"
 	path := fmt.Sprintf("%shealth", APIPrefix)
 	return server.GetHealth200JSONResponse{
@@ -65,12 +125,54 @@ func (s *KubevirtHandler) GetHealth(ctx context.Context, request server.GetHealt
 	}, nil
 }
 
+// (GET /vms/capabilities)
+func (s *KubevirtHandler) GetCapabilities(ctx context.Context, request server.GetCapabilitiesRequestObject) (server.GetCapabilitiesResponseObject, error) {
+	c := s.capabilities
+	resp := server.GetCapabilities200JSONResponse{
+		GuestOs:           nonNil(c.GuestOS),
+		DiskBuses:         nonNil(c.DiskBuses),
+		SshKeyPropagation: nonNil(c.SSHKeyPropagation),
+		PortServiceTypes:  nonNil(c.PortServiceTypes),
+		LiveMigration:     c.LiveMigration,
+		Snapshots:         c.Snapshots,
+	}
+	if c.MaxVCPU > 0 {
+		resp.MaxVcpu = &c.MaxVCPU
+	}
+	if c.MaxMemory != "" {
+		resp.MaxMemory = &c.MaxMemory
+	}
+	if c.MaxDiskCapacity != "" {
+		resp.MaxDiskCapacity = &c.MaxDiskCapacity
+	}
+	if c.DefaultSSHKeyPropagation != "" {
+		resp.DefaultSshKeyPropagation = &c.DefaultSSHKeyPropagation
+	}
+	return resp, nil
+}
+
+// nonNil returns an empty list for nil, so required arrays encode as []
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
 // (GET /vms)
 func (s *KubevirtHandler) ListVMs(ctx context.Context, request server.ListVMsRequestObject) (server.ListVMsResponseObject, error) {
+	pageSize := defaultPageSize
+	if size := request.Params.MaxPageSize; size != nil && *size > 0 && *size < defaultPageSize {
+		pageSize = *size
+	}
 	listOptions := metav1.ListOptions{
 		LabelSelector: fmt.Sprintf("%s=%s", constants.DCMLabelManagedBy, constants.DCMManagedByValue),
+		Limit:         int64(pageSize),
 	}
-	list, err := s.kubevirtClient.ListVirtualMachines(ctx, listOptions)
+	if request.Params.PageToken != nil {
+		listOptions.Continue = *request.Params.PageToken
+	}
+	list, nextPageToken, err := s.kubevirtClient.ListVirtualMachines(ctx, listOptions)
 	if err != nil {
 		return kubevirt.MapKubernetesErrorForList(err), nil
 	}
@@ -78,21 +180,35 @@ func (s *KubevirtHandler) ListVMs(ctx context.Context, request server.ListVMsReq
 	for i := range list {
 		serverVM, err := s.kubevirtVMToServerVM(&list[i])
 		if err != nil {
-			log.Printf("Warning: skipping VM %s: failed to convert: %v", list[i].Name, err)
+			zap.S().Warnw("Skipping VM that failed to convert", "vm", list[i].Name, "error", err)
 			continue
 		}
+		if reason, _ := kubevirt.VirtualMachineStatusReason(&list[i]); reason != "" {
+			serverVM.StatusReason = &reason
+		}
 		vms = append(vms, *serverVM)
 	}
-	return server.ListVMs200JSONResponse{Vms: &vms}, nil
+	response := server.ListVMs200JSONResponse{Vms: &vms}
+	if nextPageToken != "" {
+		response.NextPageToken = &nextPageToken
+	}
+	return response, nil
 }
 
 // (POST /vms)
 func (s *KubevirtHandler) CreateVM(ctx context.Context, request server.CreateVMRequestObject) (server.CreateVMResponseObject, error) {
 	vmSpec := request.Body
-	vmID := *request.Params.Id
+	vmID, err := resolveVMID(request.Params.Id)
+	if err != nil {
+		body, statusCode := kubevirt.ValidationError(err.Error())
+		return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+			Body:       body,
+			StatusCode: statusCode,
+		}, nil
+	}
 	path := fmt.Sprintf("%svms/%s", APIPrefix, vmID)
 
-	log.Printf("CreateVM called: vmID=%s, body=%+v", vmID, vmSpec)
+	zap.S().Debugw("CreateVM called", "vmID", vmID, "body", vmSpec)
 
 	// Convert VMSpec to KubeVirt VirtualMachine
 	catalogVMSpec, err := createVMRequestToVMSpec(vmSpec)
@@ -104,6 +220,16 @@ func (s *KubevirtHandler) CreateVM(ctx context.Context, request server.CreateVMR
 		}, nil
 	}
 
+	if s.policy != nil {
+		if violations := s.policy.Check(ctx, catalogVMSpec); len(violations) > 0 {
+			body, statusCode := kubevirt.PolicyViolationError(violations)
+			return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+				Body:       body,
+				StatusCode: statusCode,
+			}, nil
+		}
+	}
+
 	virtualMachine, err := s.mapper.VMSpecToVirtualMachine(catalogVMSpec, vmID)
 	if err != nil {
 		body, statusCode := kubevirt.ValidationError(fmt.Sprintf("Failed to convert VMSpec to VirtualMachine: %v", err))
@@ -112,13 +238,173 @@ func (s *KubevirtHandler) CreateVM(ctx context.Context, request server.CreateVMR
 			StatusCode: statusCode,
 		}, nil
 	}
+	if s.provenance != nil {
+		kubevirt.AnnotateProvenance(virtualMachine, *s.provenance, middleware.GetReqID(ctx))
+	}
+
+	// Validate the requested guest ports before anything is created
+	portService, err := s.mapper.PortService(catalogVMSpec, vmID)
+	if err != nil {
+		body, statusCode := kubevirt.ValidationError(fmt.Sprintf("Failed to build port service: %v", err))
+		return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+			Body:       body,
+			StatusCode: statusCode,
+		}, nil
+	}
+
+	subdomainService, err := s.mapper.SubdomainService(catalogVMSpec)
+	if err != nil {
+		body, statusCode := kubevirt.ValidationError(fmt.Sprintf("Failed to build subdomain service: %v", err))
+		return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+			Body:       body,
+			StatusCode: statusCode,
+		}, nil
+	}
+
+	// Two VMs with the same hostname in a subdomain would share one DNS name
+	if !s.allowDuplicateHostnames {
+		if err := s.checkHostnameAvailable(ctx, virtualMachine); err != nil {
+			if errors.Is(err, kubevirt.ErrHostnameInUse) {
+				body, statusCode := kubevirt.ConflictError(err.Error())
+				return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+					Body:       body,
+					StatusCode: statusCode,
+				}, nil
+			}
+			return kubevirt.MapKubernetesError(err), nil
+		}
+	}
+
+	claims, err := s.mapper.PersistentVolumeClaims(catalogVMSpec, vmID)
+	if err != nil {
+		body, statusCode := kubevirt.ValidationError(fmt.Sprintf("Failed to build persistent volume claims: %v", err))
+		return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+			Body:       body,
+			StatusCode: statusCode,
+		}, nil
+	}
+
+	// Target the requested node pool and check the cluster can schedule it
+	if err := s.kubevirtClient.ResolveNodePool(ctx, virtualMachine); err != nil {
+		if errors.Is(err, kubevirt.ErrNodePoolUnavailable) {
+			body, statusCode := kubevirt.ValidationError(err.Error())
+			return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+				Body:       body,
+				StatusCode: statusCode,
+			}, nil
+		}
+		return kubevirt.MapKubernetesError(err), nil
+	}
+
+	// Golden DataVolumes the VM is cloned from or attaches must already exist
+	if err := s.kubevirtClient.CheckDataVolumeSources(ctx, virtualMachine); err != nil {
+		if errors.Is(err, kubevirt.ErrDataVolumeNotFound) || errors.Is(err, kubevirt.ErrDataVolumeNotShareable) {
+			body, statusCode := kubevirt.ValidationError(err.Error())
+			return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+				Body:       body,
+				StatusCode: statusCode,
+			}, nil
+		}
+		return kubevirt.MapKubernetesError(err), nil
+	}
+
+	// Reject VMs that, with KubeVirt's launcher overhead, exceed the quota or fit no node
+	if err := s.kubevirtClient.CheckCapacity(ctx, virtualMachine); err != nil {
+		if errors.Is(err, kubevirt.ErrInsufficientCapacity) {
+			body, statusCode := kubevirt.ValidationError(err.Error())
+			return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+				Body:       body,
+				StatusCode: statusCode,
+			}, nil
+		}
+		return kubevirt.MapKubernetesError(err), nil
+	}
+
+	// The headless Service governing the subdomain is shared by all VMs in it,
+	// so it is created by the first of them and never owned by a single VM
+	if subdomainService != nil {
+		if _, err := s.kubevirtClient.CreateService(ctx, subdomainService); err != nil && !kubevirt.IsAlreadyExistsError(err) {
+			return kubevirt.MapKubernetesError(err), nil
+		}
+	}
+
+	// Create the Secrets the VM references first, so the VM never boots without them
+	secrets, err := s.mapper.Secrets(catalogVMSpec, vmID)
+	if err != nil {
+		body, statusCode := kubevirt.ValidationError(fmt.Sprintf("Failed to build VM secrets: %v", err))
+		return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+			Body:       body,
+			StatusCode: statusCode,
+		}, nil
+	}
+	// Only Secrets created here are rolled back. Existing ones keep their data
+	// until the VM is created, so a failing request never changes them.
+	appliedSecrets := make([]*k8sv1.Secret, 0, len(secrets))
+	createdSecrets := make([]*k8sv1.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		applied, created, err := s.applySecret(ctx, secret, vmID)
+		if err != nil {
+			s.deleteSecrets(ctx, createdSecrets)
+			if errors.Is(err, kubevirt.ErrSecretNotManaged) {
+				body, statusCode := kubevirt.ConflictError(err.Error())
+				return &server.CreateVMdefaultApplicationProblemPlusJSONResponse{
+					Body:       body,
+					StatusCode: statusCode,
+				}, nil
+			}
+			return kubevirt.MapKubernetesError(err), nil
+		}
+		appliedSecrets = append(appliedSecrets, applied)
+		if created {
+			createdSecrets = append(createdSecrets, applied)
+		}
+	}
 
 	// Create the VirtualMachine in Kubernetes cluster
 	createdVM, err := s.kubevirtClient.CreateVirtualMachine(ctx, virtualMachine)
 	if err != nil {
+		s.deleteSecrets(ctx, createdSecrets)
 		return kubevirt.MapKubernetesError(err), nil
 	}
 
+	// Rotate the data of existing secrets and owner-reference all of them to
+	// the VM so they are garbage collected with it
+	for i, secret := range appliedSecrets {
+		if !maps.EqualFunc(secret.Data, secrets[i].Data, bytes.Equal) {
+			secret.Data = secrets[i].Data
+			zap.S().Infow("Updating data of existing secret", "secret", secret.Name)
+		}
+		kubevirt.SetOwnerReference(&secret.ObjectMeta, createdVM)
+		if _, err := s.kubevirtClient.UpdateSecret(ctx, secret); err != nil {
+			zap.S().Warnw("Failed to update secret", "secret", secret.Name, "error", err)
+		}
+	}
+
+	// Provision the claims backing persistent disks, owned by the VM so they
+	// are deleted with it
+	for _, claim := range claims {
+		claim.OwnerReferences = append(claim.OwnerReferences, kubevirt.OwnerReference(createdVM))
+		if _, err := s.kubevirtClient.CreatePersistentVolumeClaim(ctx, claim); err != nil {
+			if delErr := s.kubevirtClient.DeleteVirtualMachine(ctx, vmID, nil); delErr != nil {
+				zap.S().Warnw("Failed to clean up VM", "vmID", vmID, "error", delErr)
+			}
+			return kubevirt.MapKubernetesError(err), nil
+		}
+	}
+
+	// Publish the requested guest ports through a Service owned by the VM
+	var createdService *k8sv1.Service
+	if portService != nil {
+		portService.OwnerReferences = append(portService.OwnerReferences, kubevirt.OwnerReference(createdVM))
+		createdService, err = s.kubevirtClient.CreateService(ctx, portService)
+		if err != nil {
+			if delErr := s.kubevirtClient.DeleteVirtualMachine(ctx, vmID, nil); delErr != nil {
+				zap.S().Warnw("Failed to clean up VM", "vmID", vmID, "error", delErr)
+			}
+			return kubevirt.MapKubernetesError(err), nil
+		}
+	}
+
 	// Convert created VM back to response resource
 	createdVMSpec, err := s.mapper.VirtualMachineToVMSpec(createdVM)
 	if err != nil {
@@ -136,13 +422,97 @@ func (s *KubevirtHandler) CreateVM(ctx context.Context, request server.CreateVMR
 			StatusCode: statusCode,
 		}, nil
 	}
+	serverVM.ConnectMethods = connectMethods(createdService)
 	return server.CreateVM201JSONResponse(*serverVM), nil
 }
 
+// applySecret creates a Secret or, when one of the same name exists, returns it
+// unchanged so that its data is only rotated once the VM is created. Existing
+// Secrets must carry the DCM labels of the VM. created reports whether the
+// Secret is new.
+func (s *KubevirtHandler) applySecret(ctx context.Context, secret *k8sv1.Secret, vmID string) (*k8sv1.Secret, bool, error) {
+	created, err := s.kubevirtClient.CreateSecret(ctx, secret)
+	if err == nil {
+		return created, true, nil
+	}
+	if !kubevirt.IsAlreadyExistsError(err) {
+		return nil, false, err
+	}
+
+	existing, err := s.kubevirtClient.GetSecret(ctx, secret.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := kubevirt.CheckSecretManaged(existing, vmID); err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// checkHostnameAvailable fails with kubevirt.ErrHostnameInUse when another
+// DCM-managed VM has the same hostname in the subdomain of vm
+func (s *KubevirtHandler) checkHostnameAvailable(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	if vm.Spec.Template == nil || vm.Spec.Template.Spec.Subdomain == "" {
+		return nil
+	}
+	existing, _, err := s.kubevirtClient.ListVirtualMachines(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", constants.DCMLabelManagedBy, constants.DCMManagedByValue),
+	})
+	if err != nil {
+		return err
+	}
+	return kubevirt.HostnameConflict(vm, existing)
+}
+
+// deleteSecrets removes secrets created for a VM that could not be created
+func (s *KubevirtHandler) deleteSecrets(ctx context.Context, secrets []*k8sv1.Secret) {
+	for _, secret := range secrets {
+		if err := s.kubevirtClient.DeleteSecret(ctx, secret.Name); err != nil {
+			zap.S().Warnw("Failed to clean up secret", "secret", secret.Name, "error", err)
+		}
+	}
+}
+
+// portConnectMethods returns the exposed guest ports of a VM. A missing port
+// Service means no ports were exposed; lookup failures are logged and omitted.
+func (s *KubevirtHandler) portConnectMethods(ctx context.Context, vmID string) *[]server.ConnectMethod {
+	service, err := s.kubevirtClient.GetService(ctx, kubevirt.PortServiceName(vmID))
+	if err != nil {
+		if !kubevirt.IsNotFoundError(err) {
+			zap.S().Warnw("Failed to get port service for VM", "vmID", vmID, "error", err)
+		}
+		return nil
+	}
+	return connectMethods(service)
+}
+
+// diskImportStatus reports the progress or failure of importing the disks of a
+// VM that is not ready yet, such as pulling its OS image into a data volume.
+// Lookup failures are logged and omitted.
+func (s *KubevirtHandler) diskImportStatus(ctx context.Context, vm *kubevirtv1.VirtualMachine) (reason, message string) {
+	imports, err := s.kubevirtClient.GetDiskImports(ctx, vm)
+	if err != nil {
+		zap.S().Warnw("Failed to get disk imports of VM", "vm", vm.Name, "error", err)
+		return "", ""
+	}
+	return kubevirt.DiskImportStatus(imports)
+}
+
 // (DELETE /vms/{vmId})
 func (s *KubevirtHandler) DeleteVM(ctx context.Context, request server.DeleteVMRequestObject) (server.DeleteVMResponseObject, error) {
-	// Delete the VM
-	err := s.kubevirtClient.DeleteVirtualMachine(ctx, request.VmId)
+	if request.Params.DryRun != nil && *request.Params.DryRun {
+		return s.deletionPlan(ctx, request.VmId), nil
+	}
+
+	// Delete the VM, optionally overriding its grace period
+	err := s.kubevirtClient.DeleteVirtualMachine(ctx, request.VmId, request.Params.GracePeriodSeconds)
+	if err != nil && !kubevirt.IsNotFoundError(err) {
+		return kubevirt.MapKubernetesErrorForDelete(err), nil
+	}
+	// Resources are found by label, so those left behind by a VM that is
+	// already gone are cleaned up too
+	s.deleteVMSecrets(ctx, request.VmId)
+	s.deleteServices(ctx, request.VmId)
 	if err != nil {
 		return kubevirt.MapKubernetesErrorForDelete(err), nil
 	}
@@ -150,6 +520,149 @@ func (s *KubevirtHandler) DeleteVM(ctx context.Context, request server.DeleteVMR
 	return server.DeleteVM204Response{}, nil
 }
 
+// deletionPlan lists the resources deleting a VM would remove, without
+// deleting anything
+func (s *KubevirtHandler) deletionPlan(ctx context.Context, vmID string) server.DeleteVMResponseObject {
+	vm, err := s.kubevirtClient.GetVirtualMachine(ctx, vmID)
+	if err != nil {
+		return kubevirt.MapKubernetesErrorForDelete(err)
+	}
+
+	resources := []server.DeletedResource{}
+	for _, r := range kubevirt.DeletedResources(vm) {
+		resources = append(resources, server.DeletedResource{Kind: r.Kind, Name: r.Name})
+	}
+	services, err := s.kubevirtClient.ListServices(ctx, vmID)
+	if err != nil {
+		return kubevirt.MapKubernetesErrorForDelete(err)
+	}
+	for _, service := range services {
+		resources = append(resources, server.DeletedResource{Kind: "Service", Name: service.Name})
+	}
+	return server.DeleteVM200JSONResponse{Resources: resources}
+}
+
+// deleteVMSecrets removes the Secrets of a deleted VM. Like deleteServices it
+// covers Secrets without an owner reference, and failures are only logged.
+func (s *KubevirtHandler) deleteVMSecrets(ctx context.Context, vmID string) {
+	if err := s.kubevirtClient.DeleteSecretsByInstanceID(ctx, vmID); err != nil {
+		zap.S().Warnw("Failed to clean up secrets", "vmID", vmID, "error", err)
+	}
+}
+
+// deleteServices removes the Services of a deleted VM. Services owned by the
+// VM are garbage collected anyway, but those created without an owner
+// reference would leak. Failures are logged, as the VM itself is gone.
+func (s *KubevirtHandler) deleteServices(ctx context.Context, vmID string) {
+	services, err := s.kubevirtClient.ListServices(ctx, vmID)
+	if err != nil {
+		zap.S().Warnw("Failed to list services of deleted VM", "vmID", vmID, "error", err)
+		return
+	}
+	for _, service := range services {
+		if err := s.kubevirtClient.DeleteService(ctx, service.Name); err != nil && !kubevirt.IsNotFoundError(err) {
+			zap.S().Warnw("Failed to clean up service", "vmID", vmID, "service", service.Name, "error", err)
+		}
+	}
+}
+
+// (POST /vms/{vmId}/start)
+func (s *KubevirtHandler) StartVM(ctx context.Context, request server.StartVMRequestObject) (server.StartVMResponseObject, error) {
+	if err := s.kubevirtClient.StartVirtualMachine(ctx, request.VmId); err != nil {
+		return kubevirt.MapKubernetesErrorForStart(err), nil
+	}
+	return server.StartVM204Response{}, nil
+}
+
+// (POST /vms/{vmId}/stop)
+func (s *KubevirtHandler) StopVM(ctx context.Context, request server.StopVMRequestObject) (server.StopVMResponseObject, error) {
+	if err := s.kubevirtClient.StopVirtualMachine(ctx, request.VmId); err != nil {
+		return kubevirt.MapKubernetesErrorForStop(err), nil
+	}
+	return server.StopVM204Response{}, nil
+}
+
+// (POST /vms/{vmId}/restart)
+func (s *KubevirtHandler) RestartVM(ctx context.Context, request server.RestartVMRequestObject) (server.RestartVMResponseObject, error) {
+	if err := s.kubevirtClient.RestartVirtualMachine(ctx, request.VmId); err != nil {
+		return kubevirt.MapKubernetesErrorForRestart(err), nil
+	}
+	return server.RestartVM204Response{}, nil
+}
+
+// restartRequiredMessage explains the RestartRequired reason of a VM resized while running
+const restartRequiredMessage = "The new resources take effect when the VM is restarted"
+
+// (POST /vms/{vmId}/resize)
+func (s *KubevirtHandler) ResizeVM(ctx context.Context, request server.ResizeVMRequestObject) (server.ResizeVMResponseObject, error) {
+	vmID := request.VmId
+	if request.Body == nil {
+		body, _ := kubevirt.ValidationError("Request body is required")
+		return server.ResizeVM400ApplicationProblemPlusJSONResponse(body), nil
+	}
+
+	vm, err := s.kubevirtClient.GetVirtualMachine(ctx, vmID)
+	if err != nil {
+		return kubevirt.MapKubernetesErrorForResize(err), nil
+	}
+
+	changed, err := s.mapper.ResizeVirtualMachine(vm, request.Body.Vcpu, request.Body.Memory)
+	if err != nil {
+		body, _ := kubevirt.ValidationError(fmt.Sprintf("Invalid resize request: %v", err))
+		return server.ResizeVM400ApplicationProblemPlusJSONResponse(body), nil
+	}
+
+	// A running instance keeps its resources until it is restarted. KubeVirt
+	// reports this with a RestartRequired condition, which it sets asynchronously.
+	restartRequired := false
+	if changed {
+		if s.policy != nil {
+			vmSpec, err := s.mapper.VirtualMachineToVMSpec(vm)
+			if err != nil {
+				body, statusCode := kubevirt.InternalServerError(fmt.Sprintf("Failed to convert VirtualMachine to VMSpec: %v", err))
+				return server.ResizeVMdefaultApplicationProblemPlusJSONResponse{
+					Body:       body,
+					StatusCode: statusCode,
+				}, nil
+			}
+			if violations := s.policy.Check(ctx, vmSpec); len(violations) > 0 {
+				body, statusCode := kubevirt.PolicyViolationError(violations)
+				return server.ResizeVMdefaultApplicationProblemPlusJSONResponse{
+					Body:       body,
+					StatusCode: statusCode,
+				}, nil
+			}
+		}
+
+		restartRequired = vm.Status.Created
+		if vm, err = s.kubevirtClient.UpdateVirtualMachine(ctx, vm); err != nil {
+			return kubevirt.MapKubernetesErrorForResize(err), nil
+		}
+	}
+
+	serverVM, err := s.kubevirtVMToServerVM(vm)
+	if err != nil {
+		body, statusCode := kubevirt.InternalServerError(fmt.Sprintf("Failed to convert VM spec: %v", err))
+		return server.ResizeVMdefaultApplicationProblemPlusJSONResponse{
+			Body:       body,
+			StatusCode: statusCode,
+		}, nil
+	}
+	serverVM.ConnectMethods = s.portConnectMethods(ctx, vmID)
+	serverVM.Conditions = vmConditions(vm)
+	reason, message := kubevirt.VirtualMachineStatusReason(vm)
+	if reason == "" && restartRequired {
+		reason, message = string(kubevirtv1.VirtualMachineRestartRequired), restartRequiredMessage
+	}
+	if reason != "" {
+		serverVM.StatusReason = &reason
+		if message != "" {
+			serverVM.Spec.StatusMessage = &message
+		}
+	}
+	return server.ResizeVM200JSONResponse(*serverVM), nil
+}
+
 // (GET /vms/{vmId})
 func (s *KubevirtHandler) GetVM(ctx context.Context, request server.GetVMRequestObject) (server.GetVMResponseObject, error) {
 	vmID := request.VmId
@@ -190,9 +703,58 @@ func (s *KubevirtHandler) GetVM(ctx context.Context, request server.GetVMRequest
 			StatusCode: statusCode,
 		}, nil
 	}
+	serverVM.ConnectMethods = s.portConnectMethods(ctx, vmID)
+	serverVM.Conditions = vmConditions(vm)
+	serverVM.Connection = vmConnection(vm)
+	reason, message := kubevirt.VirtualMachineStatusReason(vm)
+	if reason == "" && !vm.Status.Ready {
+		reason, message = s.diskImportStatus(ctx, vm)
+	}
+	if reason != "" {
+		serverVM.StatusReason = &reason
+	}
+	if message != "" {
+		serverVM.Spec.StatusMessage = &message
+	}
 	return server.GetVM200JSONResponse(*serverVM), nil
 }
 
+// (GET /vms/{vmId}/usage)
+func (s *KubevirtHandler) GetVMUsage(ctx context.Context, request server.GetVMUsageRequestObject) (server.GetVMUsageResponseObject, error) {
+	usage, err := s.kubevirtClient.GetVirtualMachineUsage(ctx, request.VmId)
+	if err != nil {
+		return kubevirt.MapKubernetesErrorForUsage(err), nil
+	}
+
+	path := fmt.Sprintf("%svms/%s/usage", APIPrefix, request.VmId)
+	resp := server.GetVMUsage200JSONResponse{
+		Path:   &path,
+		Cpu:    usage.CPU.String(),
+		Memory: usage.Memory.String(),
+	}
+	if !usage.Timestamp.IsZero() {
+		resp.Timestamp = &usage.Timestamp
+	}
+	if usage.Window > 0 {
+		window := usage.Window.String()
+		resp.Window = &window
+	}
+	return resp, nil
+}
+
+// resolveVMID returns the canonical form of the caller-provided VM ID, or a newly
+// generated one when none was provided. Provided IDs must be valid UUIDs.
+func resolveVMID(id *string) (string, error) {
+	if id == nil || *id == "" {
+		return uuid.New().String(), nil
+	}
+	parsed, err := uuid.Parse(*id)
+	if err != nil {
+		return "", fmt.Errorf("invalid VM ID %q: must be a UUID", *id)
+	}
+	return parsed.String(), nil
+}
+
 // extractVMIDFromVM extracts the DCM instance ID from a KubeVirt VM object
 func (s *KubevirtHandler) extractVMIDFromVM(vm *kubevirtv1.VirtualMachine) string {
 	// First check main metadata labels
@@ -0,0 +1,838 @@
+package v1alpha1
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/api/server"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt/fake"
+)
+
+var (
+	_ VMClient = (*kubevirt.Client)(nil)
+	_ VMClient = (*fake.Client)(nil)
+	_ VMMapper = (*kubevirt.Mapper)(nil)
+)
+
+var _ = Describe("KubevirtHandler with the in-memory client", func() {
+	var (
+		client *fake.Client
+		h      *KubevirtHandler
+		ctx    context.Context
+		vmID   string
+		body   server.CreateVMJSONRequestBody
+	)
+
+	BeforeEach(func() {
+		client = fake.NewClient("default")
+		ctx = context.Background()
+		vmID = "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+		body = server.CreateVMJSONRequestBody{
+			Spec: server.VMSpec{
+				ServiceType: server.Vm,
+				Metadata:    server.ServiceMetadata{Name: "web-01"},
+				GuestOs:     server.GuestOS{Type: "fedora"},
+				Vcpu:        server.Vcpu{Count: 2},
+				Memory:      server.Memory{Size: "2Gi"},
+				Storage: server.Storage{Disks: []server.Disk{
+					{Name: "boot"},
+					{Name: "data", Capacity: "20Gi"},
+				}},
+			},
+		}
+	})
+
+	createVM := func() server.CreateVMResponseObject {
+		resp, err := h.CreateVM(ctx, server.CreateVMRequestObject{
+			Params: server.CreateVMParams{Id: &vmID},
+			Body:   &body,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return resp
+	}
+
+	Context("with inline cloud-init", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+		})
+
+		It("should create, get, list and delete a VM", func() {
+			created, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*created.Path).To(Equal(APIPrefix + "vms/" + vmID))
+
+			stored, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Name).To(Equal("dcm-" + vmID))
+			Expect(stored.Labels).To(HaveKeyWithValue(constants.DCMLabelManagedBy, constants.DCMManagedByValue))
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			_, ok = getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			listResp, err := h.ListVMs(ctx, server.ListVMsRequestObject{})
+			Expect(err).NotTo(HaveOccurred())
+			list, ok := listResp.(server.ListVMs200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*list.Vms).To(HaveLen(1))
+
+			deleteResp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleteResp).To(Equal(server.DeleteVM204Response{}))
+
+			getResp, err = h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			_, ok = getResp.(server.GetVM404ApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should page through VMs with round-tripped page tokens", func() {
+			ids := []string{
+				"0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c01",
+				"0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c02",
+				"0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c03",
+			}
+			for _, id := range ids {
+				vmID = id
+				_, ok := createVM().(server.CreateVM201JSONResponse)
+				Expect(ok).To(BeTrue())
+			}
+
+			pageSize := 2
+			var listed []string
+			var token *string
+			for pages := 0; ; pages++ {
+				Expect(pages).To(BeNumerically("<", len(ids)), "pagination did not terminate")
+				resp, err := h.ListVMs(ctx, server.ListVMsRequestObject{
+					Params: server.ListVMsParams{MaxPageSize: &pageSize, PageToken: token},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				page, ok := resp.(server.ListVMs200JSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(len(*page.Vms)).To(BeNumerically("<=", pageSize))
+				for _, vm := range *page.Vms {
+					listed = append(listed, *vm.Path)
+				}
+				if page.NextPageToken == nil {
+					break
+				}
+				token = page.NextPageToken
+			}
+			expected := make([]string, 0, len(ids))
+			for _, id := range ids {
+				expected = append(expected, APIPrefix+"vms/"+id)
+			}
+			Expect(listed).To(Equal(expected))
+		})
+
+		It("should remove secrets left behind by a VM that is already gone", func() {
+			orphan := &k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "leftover",
+				Labels: map[string]string{
+					constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+					constants.DCMLabelInstanceID: vmID,
+				},
+			}}
+			other := &k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "unrelated",
+				Labels: map[string]string{
+					constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+					constants.DCMLabelInstanceID: "another-vm",
+				},
+			}}
+			_, err := client.CreateSecret(ctx, orphan)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.CreateSecret(ctx, other)
+			Expect(err).NotTo(HaveOccurred())
+
+			deleteResp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			_, ok := deleteResp.(server.DeleteVM404ApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+
+			Expect(client.Secret("leftover")).To(BeNil())
+			Expect(client.Secret("unrelated")).NotTo(BeNil())
+		})
+
+		It("should list the connection info recorded once the VM is running", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			listed := func() server.VM {
+				resp, err := h.ListVMs(ctx, server.ListVMsRequestObject{})
+				Expect(err).NotTo(HaveOccurred())
+				list, ok := resp.(server.ListVMs200JSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(*list.Vms).To(HaveLen(1))
+				return (*list.Vms)[0]
+			}
+			Expect(listed().Connection).To(BeNil())
+
+			// Record the connection info the way the monitor does on the Running transition
+			stored, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			info := kubevirt.ConnectionInfo{IP: "10.244.0.12", SSHSecretName: "dcm-" + vmID + "-ssh", SSHNodePort: 30022}
+			stored.Annotations = map[string]string{}
+			for key, value := range info.Annotations() {
+				if value != nil {
+					stored.Annotations[key] = *value
+				}
+			}
+			_, err = client.UpdateVirtualMachine(ctx, stored)
+			Expect(err).NotTo(HaveOccurred())
+
+			connection := listed().Connection
+			Expect(connection).NotTo(BeNil())
+			Expect(connection.Ip).To(HaveValue(Equal("10.244.0.12")))
+			Expect(connection.SshSecretName).To(HaveValue(Equal("dcm-" + vmID + "-ssh")))
+			Expect(connection.SshNodePort).To(HaveValue(Equal(30022)))
+			Expect(connection.SshUser).To(BeNil())
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			got, ok := getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(got.Connection).To(Equal(connection))
+		})
+
+		It("should return 409 when the same ID is created twice", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusConflict))
+		})
+
+		It("should list a VM deleted in the cluster as terminating", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			stored, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			now := metav1.Now()
+			stored.DeletionTimestamp = &now
+			_, err = client.UpdateVirtualMachine(ctx, stored)
+			Expect(err).NotTo(HaveOccurred())
+
+			listResp, err := h.ListVMs(ctx, server.ListVMsRequestObject{})
+			Expect(err).NotTo(HaveOccurred())
+			list, ok := listResp.(server.ListVMs200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*list.Vms).To(HaveLen(1))
+			Expect((*list.Vms)[0].StatusReason).To(HaveValue(Equal("Terminating")))
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			vm, ok := getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(vm.StatusReason).To(HaveValue(Equal("Terminating")))
+		})
+
+		It("should report why a stuck VM is not running", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			stored, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			stored.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusImagePullBackOff
+			stored.Status.Conditions = []kubevirtv1.VirtualMachineCondition{{
+				Type:    kubevirtv1.VirtualMachineReady,
+				Status:  k8sv1.ConditionFalse,
+				Reason:  "ImagePullBackOff",
+				Message: `Back-off pulling image "quay.io/containerdisks/fedora:latest"`,
+			}}
+			_, err = client.UpdateVirtualMachine(ctx, stored)
+			Expect(err).NotTo(HaveOccurred())
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			vm, ok := getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(vm.StatusReason).To(HaveValue(Equal("ImagePullBackOff")))
+			Expect(*vm.Spec.StatusMessage).To(ContainSubstring("Back-off pulling image"))
+			Expect(vm.Conditions).NotTo(BeNil())
+			Expect(*vm.Conditions).To(ConsistOf(And(
+				HaveField("Type", "Ready"),
+				HaveField("Status", server.False),
+				HaveField("Reason", HaveValue(Equal("ImagePullBackOff"))),
+			)))
+		})
+
+		It("should report a failing OS image import into a data volume", func() {
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"disk_storage": map[string]interface{}{
+				"boot": map[string]interface{}{"backend": "dataVolume"},
+			}}}
+			client.DiskImports = []kubevirt.DiskImport{{
+				Disk:  "boot",
+				Phase: "ImportInProgress",
+				Error: `Unable to pull image "quay.io/containerdisks/fedora:latest"`,
+			}}
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			vm, ok := getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(vm.StatusReason).To(HaveValue(Equal(kubevirt.DiskImportError)))
+			Expect(vm.Spec.StatusMessage).To(HaveValue(ContainSubstring("disk boot: Unable to pull image")))
+		})
+
+		It("should report the progress of a disk import", func() {
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"disk_storage": map[string]interface{}{
+				"boot": map[string]interface{}{"backend": "dataVolume"},
+			}}}
+			client.DiskImports = []kubevirt.DiskImport{{Disk: "boot", Phase: "ImportInProgress", Progress: "45.00%"}}
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			vm, ok := getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(vm.StatusReason).To(HaveValue(Equal(kubevirt.DiskImportInProgress)))
+			Expect(vm.Spec.StatusMessage).To(HaveValue(Equal("Importing disk boot: 45.00%")))
+		})
+	})
+
+	Context("with the Manual run strategy", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"run_strategy": "Manual"}}
+		})
+
+		getStatus := func() string {
+			resp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			got, ok := resp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(got.Spec.Status).NotTo(BeNil())
+			return *got.Spec.Status
+		}
+
+		It("should stay stopped after create until it is started", func() {
+			created, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*created.Spec.Status).To(Equal("Stopped"))
+			Expect(getStatus()).To(Equal("Stopped"))
+
+			Expect(client.StartVirtualMachine(ctx, vmID)).To(Succeed())
+			Expect(getStatus()).To(Equal("Running"))
+		})
+
+		It("should start, restart and stop the VM through the API", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			restartResp, err := h.RestartVM(ctx, server.RestartVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restartResp).To(BeAssignableToTypeOf(server.RestartVM409ApplicationProblemPlusJSONResponse{}))
+
+			startResp, err := h.StartVM(ctx, server.StartVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(startResp).To(Equal(server.StartVM204Response{}))
+			Expect(getStatus()).To(Equal("Running"))
+
+			restartResp, err = h.RestartVM(ctx, server.RestartVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restartResp).To(Equal(server.RestartVM204Response{}))
+
+			for i := 0; i < 2; i++ {
+				stopResp, err := h.StopVM(ctx, server.StopVMRequestObject{VmId: vmID})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(stopResp).To(Equal(server.StopVM204Response{}))
+				Expect(getStatus()).To(Equal("Stopped"))
+			}
+		})
+
+		It("should return 404 when starting a VM that does not exist", func() {
+			resp, err := h.StartVM(ctx, server.StartVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(BeAssignableToTypeOf(server.StartVM404ApplicationProblemPlusJSONResponse{}))
+		})
+	})
+
+	Context("when resizing", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"run_strategy": "Manual"}}
+		})
+
+		resizeVM := func(vcpu int, memory string) server.ResizeVMResponseObject {
+			resp, err := h.ResizeVM(ctx, server.ResizeVMRequestObject{
+				VmId: vmID,
+				Body: &server.ResizeVMJSONRequestBody{Vcpu: &vcpu, Memory: &memory},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return resp
+		}
+
+		storedRequests := func() k8sv1.ResourceList {
+			stored, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			return stored.Spec.Template.Spec.Domain.Resources.Requests
+		}
+
+		It("should grow a stopped VM without requiring a restart", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			resized, ok := resizeVM(4, "8Gi").(server.ResizeVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*resized.Path).To(Equal(APIPrefix + "vms/" + vmID))
+			Expect(resized.Spec.Vcpu.Count).To(Equal(4))
+			Expect(resized.Spec.Memory.Size).To(Equal("8Gi"))
+			Expect(resized.StatusReason).To(BeNil())
+
+			requests := storedRequests()
+			Expect(requests.Cpu().String()).To(Equal("4"))
+			Expect(requests.Memory().String()).To(Equal("8Gi"))
+		})
+
+		It("should shrink a running VM and report that it must be restarted", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(client.StartVirtualMachine(ctx, vmID)).To(Succeed())
+
+			resized, ok := resizeVM(1, "1Gi").(server.ResizeVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(resized.Spec.Vcpu.Count).To(Equal(1))
+			Expect(resized.Spec.Memory.Size).To(Equal("1Gi"))
+			Expect(resized.StatusReason).To(HaveValue(Equal("RestartRequired")))
+			Expect(resized.Spec.StatusMessage).To(HaveValue(Equal(restartRequiredMessage)))
+
+			requests := storedRequests()
+			Expect(requests.Cpu().String()).To(Equal("1"))
+			Expect(requests.Memory().String()).To(Equal("1Gi"))
+		})
+
+		It("should not require a restart when the resources are unchanged", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(client.StartVirtualMachine(ctx, vmID)).To(Succeed())
+
+			resized, ok := resizeVM(2, "2Gi").(server.ResizeVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(resized.StatusReason).To(BeNil())
+		})
+
+		It("should return 400 without changing the VM when the size is invalid", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			Expect(resizeVM(0, "4Gi")).To(BeAssignableToTypeOf(server.ResizeVM400ApplicationProblemPlusJSONResponse{}))
+			requests := storedRequests()
+			Expect(requests.Memory().String()).To(Equal("2Gi"))
+		})
+
+		It("should return 404 when resizing a VM that does not exist", func() {
+			Expect(resizeVM(4, "8Gi")).To(BeAssignableToTypeOf(server.ResizeVM404ApplicationProblemPlusJSONResponse{}))
+		})
+	})
+
+	Context("when the guest OS is required", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default", kubevirt.SetRequireGuestOS(true)))
+		})
+
+		It("should return 400 without creating the VM when the guest OS is omitted", func() {
+			body.Spec.GuestOs = server.GuestOS{}
+
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			_, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with SSH key limits", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default", kubevirt.SetSSHKeyLimits(1, 0)))
+		})
+
+		It("should return 400 without creating the key secret when too many keys are sent", func() {
+			keys := "ssh-ed25519 AAAAOne a@example\nssh-ed25519 AAAATwo b@example"
+			body.Spec.Access = &server.Access{SshPublicKey: &keys}
+
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(client.Secret("dcm-" + vmID + "-ssh")).To(BeNil())
+		})
+	})
+
+	Context("with SSH access", func() {
+		const (
+			oldKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIM7MFQfcHd1ylZUcKQiI8JWtuQRNG3PWlubfBl1oO9T8 user@example"
+			newKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIGt5xX5qCVI5KCwEgY6WES8/A6QAG6l6VkwjhSo/HqX8 other@example"
+		)
+		secretName := func() string { return "dcm-" + vmID + "-ssh" }
+
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+		})
+
+		It("should label the key secret for its VM and own it by the VM", func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default",
+				kubevirt.SetAccessMetadata(map[string]string{"team": "web"}, nil)))
+			key := oldKey
+			body.Spec.Access = &server.Access{SshPublicKey: &key}
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			secret := client.Secret(secretName())
+			Expect(secret).NotTo(BeNil())
+			Expect(secret.Labels).To(HaveKeyWithValue(constants.DCMLabelInstanceID, vmID))
+			Expect(secret.Labels).To(HaveKeyWithValue(constants.DCMLabelManagedBy, constants.DCMManagedByValue))
+			Expect(secret.Labels).To(HaveKeyWithValue("team", "web"))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Kind).To(Equal("VirtualMachine"))
+			Expect(secret.OwnerReferences[0].Name).To(Equal("dcm-" + vmID))
+		})
+
+		It("should keep the stored key when a duplicate VM is rejected", func() {
+			key := oldKey
+			body.Spec.Access = &server.Access{SshPublicKey: &key}
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(client.Secret(secretName()).Data).To(HaveKeyWithValue("ssh-publickey", []byte(oldKey)))
+
+			key = newKey
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusConflict))
+
+			secret := client.Secret(secretName())
+			Expect(secret).NotTo(BeNil())
+			Expect(secret.Data).To(HaveKeyWithValue("ssh-publickey", []byte(oldKey)))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+		})
+
+		It("should rotate a leftover secret of the VM and own it by the new VM", func() {
+			_, err := client.CreateSecret(ctx, &k8sv1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: secretName(),
+					Labels: map[string]string{
+						constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+						constants.DCMLabelInstanceID: vmID,
+					},
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: "kubevirt.io/v1",
+						Kind:       "VirtualMachine",
+						Name:       "dcm-" + vmID,
+						UID:        "deleted-vm",
+					}},
+				},
+				Data: map[string][]byte{"ssh-publickey": []byte(oldKey)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			key := newKey
+			body.Spec.Access = &server.Access{SshPublicKey: &key}
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			secret := client.Secret(secretName())
+			Expect(secret.Data).To(HaveKeyWithValue("ssh-publickey", []byte(newKey)))
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Name).To(Equal("dcm-" + vmID))
+			Expect(secret.OwnerReferences[0].UID).NotTo(BeEquivalentTo("deleted-vm"))
+		})
+
+		It("should refuse to take over a secret without the DCM labels of the VM", func() {
+			_, err := client.CreateSecret(ctx, &k8sv1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName()},
+				Data:       map[string][]byte{"ssh-publickey": []byte(oldKey)},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			key := newKey
+			body.Spec.Access = &server.Access{SshPublicKey: &key}
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusConflict))
+
+			secret := client.Secret(secretName())
+			Expect(secret.Data).To(HaveKeyWithValue("ssh-publickey", []byte(oldKey)))
+			Expect(secret.OwnerReferences).To(BeEmpty())
+			_, err = client.GetVirtualMachine(ctx, vmID)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with exposed guest ports", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"expose_ports": []interface{}{
+				map[string]interface{}{"port": 22},
+				map[string]interface{}{"port": 80},
+			}}}
+		})
+
+		It("should create one owned service and report its ports as connect methods", func() {
+			created, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(created.ConnectMethods).NotTo(BeNil())
+			Expect(*created.ConnectMethods).To(HaveLen(2))
+
+			svc, err := client.GetService(ctx, "dcm-"+vmID+"-ports")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.OwnerReferences).To(HaveLen(1))
+			Expect(svc.OwnerReferences[0].Name).To(Equal("dcm-" + vmID))
+			Expect(svc.Spec.Ports).To(HaveLen(2))
+			Expect(svc.Spec.Ports[0].Port).To(BeEquivalentTo(22))
+			Expect(svc.Spec.Ports[1].Port).To(BeEquivalentTo(80))
+
+			getResp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			got, ok := getResp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(got.ConnectMethods).NotTo(BeNil())
+			Expect(*got.ConnectMethods).To(ConsistOf(
+				server.ConnectMethod{Type: server.NodePort, Protocol: server.TCP, Port: 22},
+				server.ConnectMethod{Type: server.NodePort, Protocol: server.TCP, Port: 80},
+			))
+		})
+
+		It("should list the VM and its service on a dry-run delete without deleting them", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			dryRun := true
+			resp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: vmID, Params: server.DeleteVMParams{DryRun: &dryRun}})
+			Expect(err).NotTo(HaveOccurred())
+			plan, ok := resp.(server.DeleteVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(plan.Resources).To(ConsistOf(
+				server.DeletedResource{Kind: "VirtualMachine", Name: "dcm-" + vmID},
+				server.DeletedResource{Kind: "Service", Name: "dcm-" + vmID + "-ports"},
+			))
+
+			_, err = client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = client.GetService(ctx, "dcm-"+vmID+"-ports")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should delete every service of the VM along with it", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			// A service created without an owner reference, e.g. by an older release
+			ssh := &k8sv1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name: "dcm-" + vmID + "-ssh",
+				Labels: map[string]string{
+					constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+					constants.DCMLabelInstanceID: vmID,
+				},
+			}}
+			_, err := client.CreateService(ctx, ssh)
+			Expect(err).NotTo(HaveOccurred())
+			other := &k8sv1.Service{ObjectMeta: metav1.ObjectMeta{
+				Name: "dcm-other-ports",
+				Labels: map[string]string{
+					constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+					constants.DCMLabelInstanceID: "other",
+				},
+			}}
+			_, err = client.CreateService(ctx, other)
+			Expect(err).NotTo(HaveOccurred())
+
+			resp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: vmID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(Equal(server.DeleteVM204Response{}))
+
+			services, err := client.ListServices(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(services).To(BeEmpty())
+			_, err = client.GetService(ctx, "dcm-other-ports")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject invalid ports without creating the VM", func() {
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"expose_ports": []interface{}{
+				map[string]interface{}{"port": 0},
+			}}}
+
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			_, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with a default subdomain", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default", kubevirt.SetSubdomain("vms")))
+		})
+
+		It("should place every VM in the subdomain governed by a single shared service", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			firstID := vmID
+			vmID = "1b2c3d4e-5f6a-4b7c-9d8e-0f1a2b3c4d5e"
+			body.Spec.Metadata.Name = "web-02"
+			_, ok = createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			for _, id := range []string{firstID, vmID} {
+				vm, err := client.GetVirtualMachine(ctx, id)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(vm.Spec.Template.Spec.Subdomain).To(Equal("vms"))
+			}
+
+			svc, err := client.GetService(ctx, "vms")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.ClusterIP).To(Equal("None"))
+			Expect(svc.Spec.Selector).To(HaveKeyWithValue(constants.DCMLabelSubdomain, "vms"))
+			Expect(svc.OwnerReferences).To(BeEmpty())
+		})
+
+		It("should return 409 for a hostname already used in the subdomain", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			vmID = "1b2c3d4e-5f6a-4b7c-9d8e-0f1a2b3c4d5e"
+
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusConflict))
+			Expect(errResp.Body.Detail).To(HaveValue(ContainSubstring("web-01")))
+			_, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).To(HaveOccurred())
+
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"hostname": "web-01b"}}
+			_, ok = createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("should allow duplicate hostnames when configured to", func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default", kubevirt.SetSubdomain("vms")),
+				SetAllowDuplicateHostnames(true))
+
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			vmID = "1b2c3d4e-5f6a-4b7c-9d8e-0f1a2b3c4d5e"
+			_, ok = createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Context("with a boot disk from an existing data volume", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"disk_storage": map[string]interface{}{
+				"boot": map[string]interface{}{"backend": "dataVolume", "data_volume": "fedora-golden"},
+			}}}
+		})
+
+		It("should clone the data volume when it exists", func() {
+			client.DataVolumes = []string{"fedora-golden"}
+
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			vm, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vm.Spec.DataVolumeTemplates).To(HaveLen(1))
+			Expect(vm.Spec.DataVolumeTemplates[0].Spec.Source.PVC.Name).To(Equal("fedora-golden"))
+		})
+
+		It("should return 400 without creating the VM when the data volume does not exist", func() {
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(*errResp.Body.Detail).To(ContainSubstring("fedora-golden"))
+			_, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with a persistent data disk", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default"))
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"disk_storage": map[string]interface{}{
+				"data": map[string]interface{}{"backend": "persistentVolumeClaim"},
+			}}}
+		})
+
+		It("should create a claim owned by the VM", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			claim := client.PersistentVolumeClaim("dcm-" + vmID + "-data")
+			Expect(claim).NotTo(BeNil())
+			Expect(claim.OwnerReferences).To(HaveLen(1))
+			Expect(claim.OwnerReferences[0].Name).To(Equal("dcm-" + vmID))
+
+			vm, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(vm.Spec.Template.Spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal(claim.Name))
+		})
+
+		It("should reject a claim for the boot disk without creating the VM", func() {
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"disk_storage": map[string]interface{}{
+				"boot": map[string]interface{}{"backend": "persistentVolumeClaim"},
+			}}}
+
+			errResp, ok := createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			_, err := client.GetVirtualMachine(ctx, vmID)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("with cloud-init stored in a secret", func() {
+		BeforeEach(func() {
+			h = NewKubevirtHandler(client, kubevirt.NewMapper("default", kubevirt.SetCloudInitFromSecret(true)))
+			body.Spec.ProviderHints = &server.ProviderHints{"kubevirt": {"hostname": "web-01"}}
+		})
+
+		It("should store the user data in a secret owned by the VM", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			secret := client.Secret("dcm-" + vmID + "-cloudinit")
+			Expect(secret).NotTo(BeNil())
+			Expect(secret.OwnerReferences).To(HaveLen(1))
+			Expect(secret.OwnerReferences[0].Name).To(Equal("dcm-" + vmID))
+		})
+
+		It("should list the secret on a dry-run delete without deleting it", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+
+			dryRun := true
+			resp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: vmID, Params: server.DeleteVMParams{DryRun: &dryRun}})
+			Expect(err).NotTo(HaveOccurred())
+			plan, ok := resp.(server.DeleteVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(plan.Resources).To(ContainElement(server.DeletedResource{Kind: "Secret", Name: "dcm-" + vmID + "-cloudinit"}))
+
+			Expect(client.Secret("dcm-" + vmID + "-cloudinit")).NotTo(BeNil())
+			_, err = client.GetVirtualMachine(ctx, vmID)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should remove the secret when the VM cannot be created", func() {
+			_, ok := createVM().(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(client.DeleteSecret(ctx, "dcm-"+vmID+"-cloudinit")).To(Succeed())
+
+			_, ok = createVM().(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(client.Secret("dcm-" + vmID + "-cloudinit")).To(BeNil())
+		})
+	})
+})
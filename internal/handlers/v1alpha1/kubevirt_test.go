@@ -4,15 +4,22 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
 	"github.com/dcm-project/kubevirt-service-provider/internal/api/server"
+	"github.com/dcm-project/kubevirt-service-provider/internal/config"
 	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -91,6 +98,10 @@ var _ = Describe("KubevirtHandler", func() {
 
 	Describe("GetHealth", func() {
 		It("should return 200 with status ok", func() {
+			client.checkNamespaceAccessFn = func(_ context.Context) error {
+				return nil
+			}
+
 			resp, err := h.GetHealth(ctx, server.GetHealthRequestObject{})
 
 			Expect(err).NotTo(HaveOccurred())
@@ -99,14 +110,65 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(*healthResp.Status).To(Equal("ok"))
 			Expect(*healthResp.Path).To(Equal("/api/v1alpha1/health"))
 		})
+
+		It("should return 503 when the namespace is missing", func() {
+			client.checkNamespaceAccessFn = func(_ context.Context) error {
+				return fmt.Errorf("namespace \"vms\" not found")
+			}
+
+			resp, err := h.GetHealth(ctx, server.GetHealthRequestObject{})
+
+			Expect(err).NotTo(HaveOccurred())
+			unavailableResp, ok := resp.(server.GetHealth503ApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*unavailableResp.Status).To(Equal(http.StatusServiceUnavailable))
+			Expect(*unavailableResp.Detail).To(ContainSubstring("not found"))
+		})
+	})
+
+	Describe("GetCapabilities", func() {
+		It("should report the configured capabilities", func() {
+			h = NewKubevirtHandler(client, mapper, SetCapabilities(kubevirt.Capabilities{
+				GuestOS:           []string{"fedora"},
+				MaxVCPU:           4,
+				MaxMemory:         "8Gi",
+				DiskBuses:         []string{"virtio"},
+				SSHKeyPropagation: []string{"nocloud"},
+				PortServiceTypes:  []string{"NodePort"},
+				LiveMigration:     true,
+			}))
+
+			resp, err := h.GetCapabilities(ctx, server.GetCapabilitiesRequestObject{})
+
+			Expect(err).NotTo(HaveOccurred())
+			caps, ok := resp.(server.GetCapabilities200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(caps.GuestOs).To(Equal([]string{"fedora"}))
+			Expect(caps.MaxVcpu).To(HaveValue(Equal(4)))
+			Expect(caps.MaxMemory).To(HaveValue(Equal("8Gi")))
+			Expect(caps.MaxDiskCapacity).To(BeNil())
+			Expect(caps.LiveMigration).To(BeTrue())
+			Expect(caps.Snapshots).To(BeFalse())
+		})
+
+		It("should report empty lists rather than null", func() {
+			resp, err := h.GetCapabilities(ctx, server.GetCapabilitiesRequestObject{})
+
+			Expect(err).NotTo(HaveOccurred())
+			caps, ok := resp.(server.GetCapabilities200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(caps.GuestOs).To(BeEmpty())
+			Expect(caps.GuestOs).NotTo(BeNil())
+			Expect(caps.MaxVcpu).To(BeNil())
+		})
 	})
 
 	Describe("ListVMs", func() {
 		It("should return VMs successfully", func() {
 			vm := newTestVM(testID)
-			client.listFn = func(_ context.Context, opts metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error) {
+			client.listFn = func(_ context.Context, opts metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
 				Expect(opts.LabelSelector).To(ContainSubstring(constants.DCMLabelManagedBy))
-				return []kubevirtv1.VirtualMachine{*vm}, nil
+				return []kubevirtv1.VirtualMachine{*vm}, "", nil
 			}
 			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
 				return newTestVMSpec(), nil
@@ -120,9 +182,50 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(*listResp.Vms).To(HaveLen(1))
 		})
 
+		It("should request a bounded page and return the next page token", func() {
+			vm := newTestVM(testID)
+			pageSize, token := 10, "page-2"
+			client.listFn = func(_ context.Context, opts metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
+				Expect(opts.Limit).To(Equal(int64(10)))
+				Expect(opts.Continue).To(Equal("page-2"))
+				return []kubevirtv1.VirtualMachine{*vm}, "page-3", nil
+			}
+			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+				return newTestVMSpec(), nil
+			}
+
+			resp, err := h.ListVMs(ctx, server.ListVMsRequestObject{
+				Params: server.ListVMsParams{MaxPageSize: &pageSize, PageToken: &token},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			listResp, ok := resp.(server.ListVMs200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*listResp.Vms).To(HaveLen(1))
+			Expect(listResp.NextPageToken).To(HaveValue(Equal("page-3")))
+		})
+
+		It("should bound the page size and omit the token on the last page", func() {
+			pageSize := 1000
+			client.listFn = func(_ context.Context, opts metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
+				Expect(opts.Limit).To(Equal(int64(defaultPageSize)))
+				Expect(opts.Continue).To(BeEmpty())
+				return []kubevirtv1.VirtualMachine{}, "", nil
+			}
+
+			resp, err := h.ListVMs(ctx, server.ListVMsRequestObject{
+				Params: server.ListVMsParams{MaxPageSize: &pageSize},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			listResp, ok := resp.(server.ListVMs200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(listResp.NextPageToken).To(BeNil())
+		})
+
 		It("should return an empty list when no VMs exist", func() {
-			client.listFn = func(_ context.Context, _ metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error) {
-				return []kubevirtv1.VirtualMachine{}, nil
+			client.listFn = func(_ context.Context, _ metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
+				return []kubevirtv1.VirtualMachine{}, "", nil
 			}
 
 			resp, err := h.ListVMs(ctx, server.ListVMsRequestObject{})
@@ -134,8 +237,8 @@ var _ = Describe("KubevirtHandler", func() {
 		})
 
 		It("should return an error response when client fails", func() {
-			client.listFn = func(_ context.Context, _ metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error) {
-				return nil, fmt.Errorf("connection refused")
+			client.listFn = func(_ context.Context, _ metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
+				return nil, "", fmt.Errorf("connection refused")
 			}
 
 			resp, err := h.ListVMs(ctx, server.ListVMsRequestObject{})
@@ -149,8 +252,8 @@ var _ = Describe("KubevirtHandler", func() {
 		It("should skip VMs that fail conversion with a warning", func() {
 			vm1 := newTestVM(testID)
 			vm2 := newTestVM("00000000-0000-0000-0000-000000000002")
-			client.listFn = func(_ context.Context, _ metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error) {
-				return []kubevirtv1.VirtualMachine{*vm1, *vm2}, nil
+			client.listFn = func(_ context.Context, _ metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
+				return []kubevirtv1.VirtualMachine{*vm1, *vm2}, "", nil
 			}
 			callCount := 0
 			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
@@ -209,6 +312,164 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(*createResp.Path).To(ContainSubstring(testID))
 		})
 
+		It("should pass a valid provided ID to the mapper in canonical form", func() {
+			upperID := "ABCDEF01-2345-4678-9ABC-DEF012345678"
+			request.Params.Id = &upperID
+			var mappedID string
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error) {
+				mappedID = vmID
+				return newTestVM(vmID), nil
+			}
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				return vm, nil
+			}
+			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+				return newTestVMSpec(), nil
+			}
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			createResp, ok := resp.(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(mappedID).To(Equal("abcdef01-2345-4678-9abc-def012345678"))
+			Expect(*createResp.Path).To(HaveSuffix(mappedID))
+		})
+
+		It("should generate an ID when none is provided", func() {
+			request.Params.Id = nil
+			var mappedID string
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error) {
+				mappedID = vmID
+				return newTestVM(vmID), nil
+			}
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				return vm, nil
+			}
+			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+				return newTestVMSpec(), nil
+			}
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			_, ok := resp.(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			_, parseErr := uuid.Parse(mappedID)
+			Expect(parseErr).NotTo(HaveOccurred())
+		})
+
+		It("should return 400 when the provided ID is not a UUID", func() {
+			invalidID := "not-a-uuid"
+			request.Params.Id = &invalidID
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+
+		Context("when the VM references secrets", func() {
+			var secret *k8sv1.Secret
+
+			BeforeEach(func() {
+				secret = &k8sv1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "dcm-test-cloudinit"},
+					Data:       map[string][]byte{"userdata": []byte("#cloud-config\n")},
+				}
+				mapper.secretsFn = func(_ *types.VMSpec, _ string) ([]*k8sv1.Secret, error) {
+					return []*k8sv1.Secret{secret}, nil
+				}
+				mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+					return newTestVM(testID), nil
+				}
+				mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+					return newTestVMSpec(), nil
+				}
+				client.createSecretFn = func(_ context.Context, s *k8sv1.Secret) (*k8sv1.Secret, error) {
+					return s, nil
+				}
+			})
+
+			It("should create the secret and owner-reference it to the VM", func() {
+				var created, updated *k8sv1.Secret
+				client.createSecretFn = func(_ context.Context, s *k8sv1.Secret) (*k8sv1.Secret, error) {
+					created = s.DeepCopy()
+					return s, nil
+				}
+				client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+					Expect(created).NotTo(BeNil(), "secret must exist before the VM")
+					vm.UID = "vm-uid"
+					return vm, nil
+				}
+				client.updateSecretFn = func(_ context.Context, s *k8sv1.Secret) (*k8sv1.Secret, error) {
+					updated = s
+					return s, nil
+				}
+
+				resp, err := h.CreateVM(ctx, request)
+
+				Expect(err).NotTo(HaveOccurred())
+				_, ok := resp.(server.CreateVM201JSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(created.Name).To(Equal("dcm-test-cloudinit"))
+				Expect(updated).NotTo(BeNil())
+				Expect(updated.OwnerReferences).To(HaveLen(1))
+				Expect(updated.OwnerReferences[0].Kind).To(Equal("VirtualMachine"))
+				Expect(string(updated.OwnerReferences[0].UID)).To(Equal("vm-uid"))
+			})
+
+			It("should delete the secret when VM creation fails", func() {
+				var deleted string
+				client.createFn = func(_ context.Context, _ *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+					return nil, newConflictError()
+				}
+				client.deleteSecretFn = func(_ context.Context, name string) error {
+					deleted = name
+					return nil
+				}
+
+				resp, err := h.CreateVM(ctx, request)
+
+				Expect(err).NotTo(HaveOccurred())
+				errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(errResp.StatusCode).To(Equal(http.StatusConflict))
+				Expect(deleted).To(Equal("dcm-test-cloudinit"))
+			})
+
+			It("should delete already created secrets when a later secret fails", func() {
+				sshSecret := &k8sv1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "dcm-test-ssh"}}
+				mapper.secretsFn = func(_ *types.VMSpec, _ string) ([]*k8sv1.Secret, error) {
+					return []*k8sv1.Secret{secret, sshSecret}, nil
+				}
+				client.createSecretFn = func(_ context.Context, s *k8sv1.Secret) (*k8sv1.Secret, error) {
+					if s.Name == "dcm-test-ssh" {
+						return nil, newConflictError()
+					}
+					return s, nil
+				}
+				var deleted []string
+				client.deleteSecretFn = func(_ context.Context, name string) error {
+					deleted = append(deleted, name)
+					return nil
+				}
+				client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+					Fail("VM must not be created when its secrets are missing")
+					return nil, nil
+				}
+
+				resp, err := h.CreateVM(ctx, request)
+
+				Expect(err).NotTo(HaveOccurred())
+				_, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(deleted).To(Equal([]string{"dcm-test-cloudinit"}))
+			})
+		})
+
 		It("should return error when client create fails", func() {
 			mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
 				return newTestVM(testID), nil
@@ -237,11 +498,282 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(ok).To(BeTrue())
 			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
 		})
+
+		It("should delete the VM when the port service cannot be created", func() {
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+				return newTestVM(testID), nil
+			}
+			mapper.portServiceFn = func(_ *types.VMSpec, vmID string) (*k8sv1.Service, error) {
+				return &k8sv1.Service{ObjectMeta: metav1.ObjectMeta{Name: kubevirt.PortServiceName(vmID)}}, nil
+			}
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				return vm, nil
+			}
+			client.createServiceFn = func(_ context.Context, _ *k8sv1.Service) (*k8sv1.Service, error) {
+				return nil, fmt.Errorf("connection refused")
+			}
+			var deleted string
+			client.deleteFn = func(_ context.Context, vmID string, _ *int64) error {
+				deleted = vmID
+				return nil
+			}
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			_, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(deleted).To(Equal(testID))
+		})
+
+		It("should create the VM when its subdomain service already exists", func() {
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+				return newTestVM(testID), nil
+			}
+			mapper.subdomainFn = func(_ *types.VMSpec) (*k8sv1.Service, error) {
+				return &k8sv1.Service{ObjectMeta: metav1.ObjectMeta{Name: "vms"}}, nil
+			}
+			client.createServiceFn = func(_ context.Context, svc *k8sv1.Service) (*k8sv1.Service, error) {
+				return nil, apierrors.NewAlreadyExists(schema.GroupResource{Resource: "services"}, svc.Name)
+			}
+			created := false
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				created = true
+				return vm, nil
+			}
+			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+				return newTestVMSpec(), nil
+			}
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			_, ok := resp.(server.CreateVM201JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(created).To(BeTrue())
+		})
+
+		It("should return validation error without creating the VM when the node pool is unavailable", func() {
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+				return newTestVM(testID), nil
+			}
+			client.resolveNodePoolFn = func(_ context.Context, _ *kubevirtv1.VirtualMachine) error {
+				return fmt.Errorf("%w: runtime class %q not found", kubevirt.ErrNodePoolUnavailable, "vms")
+			}
+			created := false
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				created = true
+				return vm, nil
+			}
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(created).To(BeFalse())
+		})
+
+		It("should return validation error without creating the VM when capacity is insufficient", func() {
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+				return newTestVM(testID), nil
+			}
+			client.checkCapacityFn = func(_ context.Context, _ *kubevirtv1.VirtualMachine) error {
+				return fmt.Errorf("%w: requests.memory needs 2304Mi but only 2Gi remains in quota %q",
+					kubevirt.ErrInsufficientCapacity, "compute")
+			}
+			created := false
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				created = true
+				return vm, nil
+			}
+
+			resp, err := h.CreateVM(ctx, request)
+
+			Expect(err).NotTo(HaveOccurred())
+			errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(*errResp.Body.Detail).To(ContainSubstring("2304Mi"))
+			Expect(created).To(BeFalse())
+		})
+
+		Context("with provenance annotations", func() {
+			BeforeEach(func() {
+				h = NewKubevirtHandler(client, mapper, SetProvenance(&kubevirt.Provenance{
+					ProviderVersion: "v1.2.3",
+					SchemaVersion:   "v1alpha1",
+				}))
+				mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+					return newTestVM(testID), nil
+				}
+				mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+					return newTestVMSpec(), nil
+				}
+			})
+
+			It("should annotate the VM with the request ID and the build and schema versions", func() {
+				var created *kubevirtv1.VirtualMachine
+				client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+					created = vm
+					return vm, nil
+				}
+
+				resp, err := h.CreateVM(context.WithValue(ctx, middleware.RequestIDKey, "host/abc-000001"), request)
+
+				Expect(err).NotTo(HaveOccurred())
+				_, ok := resp.(server.CreateVM201JSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(created.Annotations).To(HaveKeyWithValue(constants.DCMAnnotationRequestID, "host/abc-000001"))
+				Expect(created.Annotations).To(HaveKeyWithValue(constants.DCMAnnotationProviderVersion, "v1.2.3"))
+				Expect(created.Annotations).To(HaveKeyWithValue(constants.DCMAnnotationSchemaVersion, "v1alpha1"))
+			})
+
+			It("should leave out the request ID when the request has none", func() {
+				var created *kubevirtv1.VirtualMachine
+				client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+					created = vm
+					return vm, nil
+				}
+
+				_, err := h.CreateVM(ctx, request)
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(created.Annotations).NotTo(HaveKey(constants.DCMAnnotationRequestID))
+				Expect(created.Annotations).To(HaveKeyWithValue(constants.DCMAnnotationSchemaVersion, "v1alpha1"))
+			})
+		})
+
+		It("should not annotate provenance by default", func() {
+			mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+				return newTestVM(testID), nil
+			}
+			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+				return newTestVMSpec(), nil
+			}
+			var created *kubevirtv1.VirtualMachine
+			client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+				created = vm
+				return vm, nil
+			}
+
+			_, err := h.CreateVM(context.WithValue(ctx, middleware.RequestIDKey, "host/abc-000001"), request)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Annotations).NotTo(HaveKey(constants.DCMAnnotationRequestID))
+			Expect(created.Annotations).NotTo(HaveKey(constants.DCMAnnotationProviderVersion))
+		})
+
+		Context("with a create policy", func() {
+			BeforeEach(func() {
+				policy, err := kubevirt.NewPolicy(&config.PolicyConfig{
+					AllowedGuestOS: []string{"fedora", "ubuntu"},
+					MaxVCPU:        4,
+					MaxMemory:      "8Gi",
+					RequiredLabels: []string{"team"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				h = NewKubevirtHandler(client, mapper, SetPolicy(policy))
+				request.Body.Spec.Metadata.Labels = &map[string]string{"team": "infra"}
+			})
+
+			It("should create a VM that satisfies the policy", func() {
+				mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+					return newTestVM(testID), nil
+				}
+				client.createFn = func(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+					return vm, nil
+				}
+				mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+					return newTestVMSpec(), nil
+				}
+
+				resp, err := h.CreateVM(ctx, request)
+
+				Expect(err).NotTo(HaveOccurred())
+				_, ok := resp.(server.CreateVM201JSONResponse)
+				Expect(ok).To(BeTrue())
+			})
+
+			It("should return 422 listing every violation before any cluster call", func() {
+				request.Body.Spec.GuestOs.Type = "windows"
+				request.Body.Spec.Vcpu.Count = 16
+				request.Body.Spec.Metadata.Labels = nil
+				mapped := false
+				mapper.vmSpecToVMFn = func(_ *types.VMSpec, _ string) (*kubevirtv1.VirtualMachine, error) {
+					mapped = true
+					return newTestVM(testID), nil
+				}
+
+				resp, err := h.CreateVM(ctx, request)
+
+				Expect(err).NotTo(HaveOccurred())
+				errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+				Expect(ok).To(BeTrue())
+				Expect(errResp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+				Expect(*errResp.Body.Detail).To(ContainSubstring(`guest OS "windows" is not allowed`))
+				Expect(*errResp.Body.Detail).To(ContainSubstring("vcpu count 16 exceeds the maximum of 4"))
+				Expect(*errResp.Body.Detail).To(ContainSubstring(`required metadata label "team" is missing`))
+				Expect(mapped).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("GetVMUsage", func() {
+		It("should return the current usage", func() {
+			timestamp := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+			client.usageFn = func(_ context.Context, vmID string) (*kubevirt.ResourceUsage, error) {
+				Expect(vmID).To(Equal(testID))
+				return &kubevirt.ResourceUsage{
+					CPU:       resource.MustParse("250m"),
+					Memory:    resource.MustParse("1Gi"),
+					Timestamp: timestamp,
+					Window:    30 * time.Second,
+				}, nil
+			}
+
+			resp, err := h.GetVMUsage(ctx, server.GetVMUsageRequestObject{VmId: testID})
+
+			Expect(err).NotTo(HaveOccurred())
+			usage, ok := resp.(server.GetVMUsage200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(usage.Cpu).To(Equal("250m"))
+			Expect(usage.Memory).To(Equal("1Gi"))
+			Expect(*usage.Path).To(Equal(APIPrefix + "vms/" + testID + "/usage"))
+			Expect(*usage.Timestamp).To(Equal(timestamp))
+			Expect(*usage.Window).To(Equal("30s"))
+		})
+
+		It("should return 501 when resource metrics are unavailable", func() {
+			client.usageFn = func(_ context.Context, _ string) (*kubevirt.ResourceUsage, error) {
+				return nil, fmt.Errorf("%w: metrics.k8s.io/v1beta1 is not served by the cluster", kubevirt.ErrMetricsUnavailable)
+			}
+
+			resp, err := h.GetVMUsage(ctx, server.GetVMUsageRequestObject{VmId: testID})
+
+			Expect(err).NotTo(HaveOccurred())
+			errResp, ok := resp.(server.GetVMUsage501ApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(*errResp.Status).To(Equal(http.StatusNotImplemented))
+		})
+
+		It("should return 404 when the VM is not running", func() {
+			client.usageFn = func(_ context.Context, vmID string) (*kubevirt.ResourceUsage, error) {
+				return nil, apierrors.NewNotFound(schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachineinstances"}, vmID)
+			}
+
+			resp, err := h.GetVMUsage(ctx, server.GetVMUsageRequestObject{VmId: testID})
+
+			Expect(err).NotTo(HaveOccurred())
+			_, ok := resp.(server.GetVMUsage404ApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+		})
 	})
 
 	Describe("DeleteVM", func() {
 		It("should delete a VM successfully and return 204", func() {
-			client.deleteFn = func(_ context.Context, _ string) error {
+			client.deleteFn = func(_ context.Context, _ string, _ *int64) error {
 				return nil
 			}
 
@@ -252,8 +784,27 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(ok).To(BeTrue())
 		})
 
+		It("should pass a grace period override to the client", func() {
+			var gracePeriod *int64
+			client.deleteFn = func(_ context.Context, _ string, gracePeriodSeconds *int64) error {
+				gracePeriod = gracePeriodSeconds
+				return nil
+			}
+
+			resp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: testID})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(BeAssignableToTypeOf(server.DeleteVM204Response{}))
+			Expect(gracePeriod).To(BeNil())
+
+			force := int64(0)
+			resp, err = h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: testID, Params: server.DeleteVMParams{GracePeriodSeconds: &force}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(BeAssignableToTypeOf(server.DeleteVM204Response{}))
+			Expect(gracePeriod).To(HaveValue(BeZero()))
+		})
+
 		It("should return 404 when VM is not found", func() {
-			client.deleteFn = func(_ context.Context, _ string) error {
+			client.deleteFn = func(_ context.Context, _ string, _ *int64) error {
 				return newNotFoundError()
 			}
 
@@ -265,8 +816,44 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(*notFoundResp.Status).To(Equal(404))
 		})
 
+		It("should return 204 when the services of the deleted VM cannot be cleaned up", func() {
+			client.deleteFn = func(_ context.Context, _ string, _ *int64) error {
+				return nil
+			}
+			client.listServicesFn = func(_ context.Context, _ string) ([]k8sv1.Service, error) {
+				return []k8sv1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "dcm-" + testID + "-ports"}}}, nil
+			}
+			var deleted []string
+			client.deleteServiceFn = func(_ context.Context, name string) error {
+				deleted = append(deleted, name)
+				return fmt.Errorf("connection refused")
+			}
+
+			resp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: testID})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(BeAssignableToTypeOf(server.DeleteVM204Response{}))
+			Expect(deleted).To(ConsistOf("dcm-" + testID + "-ports"))
+		})
+
+		It("should return 404 on a dry run when the VM is not found", func() {
+			client.getFn = func(_ context.Context, _ string) (*kubevirtv1.VirtualMachine, error) {
+				return nil, newNotFoundError()
+			}
+			client.deleteFn = func(_ context.Context, _ string, _ *int64) error {
+				Fail("dry run must not delete the VM")
+				return nil
+			}
+
+			dryRun := true
+			resp, err := h.DeleteVM(ctx, server.DeleteVMRequestObject{VmId: testID, Params: server.DeleteVMParams{DryRun: &dryRun}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp).To(BeAssignableToTypeOf(server.DeleteVM404ApplicationProblemPlusJSONResponse{}))
+		})
+
 		It("should return error when delete fails", func() {
-			client.deleteFn = func(_ context.Context, _ string) error {
+			client.deleteFn = func(_ context.Context, _ string, _ *int64) error {
 				return fmt.Errorf("connection refused")
 			}
 
@@ -296,6 +883,30 @@ var _ = Describe("KubevirtHandler", func() {
 			Expect(*vmResp.Path).To(ContainSubstring(testID))
 		})
 
+		It("should report the reason and last error of a failing VM", func() {
+			client.getFn = func(_ context.Context, _ string) (*kubevirtv1.VirtualMachine, error) {
+				vm := newTestVM(testID)
+				vm.Status.Conditions = []kubevirtv1.VirtualMachineCondition{{
+					Type:    kubevirtv1.VirtualMachineFailure,
+					Status:  k8sv1.ConditionTrue,
+					Reason:  "CrashLoopBackOff",
+					Message: "guest failed to boot",
+				}}
+				return vm, nil
+			}
+			mapper.vmToVMSpecFn = func(_ *kubevirtv1.VirtualMachine) (*types.VMSpec, error) {
+				return newTestVMSpec(), nil
+			}
+
+			resp, err := h.GetVM(ctx, server.GetVMRequestObject{VmId: testID})
+
+			Expect(err).NotTo(HaveOccurred())
+			vmResp, ok := resp.(server.GetVM200JSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(vmResp.StatusReason).To(HaveValue(Equal("CrashLoopBackOff")))
+			Expect(vmResp.Spec.StatusMessage).To(HaveValue(Equal("guest failed to boot")))
+		})
+
 		It("should return 404 when VM is not found", func() {
 			client.getFn = func(_ context.Context, _ string) (*kubevirtv1.VirtualMachine, error) {
 				return nil, newNotFoundError()
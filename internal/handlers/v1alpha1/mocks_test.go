@@ -4,19 +4,43 @@ import (
 	"context"
 	"fmt"
 
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
 )
 
 // mockVMClient implements VMClient for testing.
 type mockVMClient struct {
-	createFn func(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
-	getFn    func(ctx context.Context, vmID string) (*kubevirtv1.VirtualMachine, error)
-	listFn   func(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error)
-	deleteFn func(ctx context.Context, vmID string) error
-	updateFn func(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
+	createFn  func(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
+	getFn     func(ctx context.Context, vmID string) (*kubevirtv1.VirtualMachine, error)
+	listFn    func(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error)
+	deleteFn  func(ctx context.Context, vmID string, gracePeriodSeconds *int64) error
+	startFn   func(ctx context.Context, vmID string) error
+	stopFn    func(ctx context.Context, vmID string) error
+	restartFn func(ctx context.Context, vmID string) error
+	updateFn  func(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error)
+	usageFn   func(ctx context.Context, vmID string) (*kubevirt.ResourceUsage, error)
+
+	checkNamespaceAccessFn func(ctx context.Context) error
+	resolveNodePoolFn      func(ctx context.Context, vm *kubevirtv1.VirtualMachine) error
+	checkDataVolumesFn     func(ctx context.Context, vm *kubevirtv1.VirtualMachine) error
+	checkCapacityFn        func(ctx context.Context, vm *kubevirtv1.VirtualMachine) error
+	getDiskImportsFn       func(ctx context.Context, vm *kubevirtv1.VirtualMachine) ([]kubevirt.DiskImport, error)
+	createSecretFn         func(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error)
+	getSecretFn            func(ctx context.Context, name string) (*k8sv1.Secret, error)
+	updateSecretFn         func(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error)
+	deleteSecretFn         func(ctx context.Context, name string) error
+	deleteSecretsByIDFn    func(ctx context.Context, vmID string) error
+	createServiceFn        func(ctx context.Context, service *k8sv1.Service) (*k8sv1.Service, error)
+	getServiceFn           func(ctx context.Context, name string) (*k8sv1.Service, error)
+	listServicesFn         func(ctx context.Context, vmID string) ([]k8sv1.Service, error)
+	deleteServiceFn        func(ctx context.Context, name string) error
+	createClaimFn          func(ctx context.Context, claim *k8sv1.PersistentVolumeClaim) (*k8sv1.PersistentVolumeClaim, error)
 }
 
 func (m *mockVMClient) CreateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
@@ -33,20 +57,41 @@ func (m *mockVMClient) GetVirtualMachine(ctx context.Context, vmID string) (*kub
 	return nil, fmt.Errorf("getFn not set")
 }
 
-func (m *mockVMClient) ListVirtualMachines(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error) {
+func (m *mockVMClient) ListVirtualMachines(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
 	if m.listFn != nil {
 		return m.listFn(ctx, options)
 	}
-	return nil, fmt.Errorf("listFn not set")
+	return nil, "", fmt.Errorf("listFn not set")
 }
 
-func (m *mockVMClient) DeleteVirtualMachine(ctx context.Context, vmID string) error {
+func (m *mockVMClient) DeleteVirtualMachine(ctx context.Context, vmID string, gracePeriodSeconds *int64) error {
 	if m.deleteFn != nil {
-		return m.deleteFn(ctx, vmID)
+		return m.deleteFn(ctx, vmID, gracePeriodSeconds)
 	}
 	return fmt.Errorf("deleteFn not set")
 }
 
+func (m *mockVMClient) StartVirtualMachine(ctx context.Context, vmID string) error {
+	if m.startFn != nil {
+		return m.startFn(ctx, vmID)
+	}
+	return fmt.Errorf("startFn not set")
+}
+
+func (m *mockVMClient) StopVirtualMachine(ctx context.Context, vmID string) error {
+	if m.stopFn != nil {
+		return m.stopFn(ctx, vmID)
+	}
+	return fmt.Errorf("stopFn not set")
+}
+
+func (m *mockVMClient) RestartVirtualMachine(ctx context.Context, vmID string) error {
+	if m.restartFn != nil {
+		return m.restartFn(ctx, vmID)
+	}
+	return fmt.Errorf("restartFn not set")
+}
+
 func (m *mockVMClient) UpdateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
 	if m.updateFn != nil {
 		return m.updateFn(ctx, vm)
@@ -54,10 +99,127 @@ func (m *mockVMClient) UpdateVirtualMachine(ctx context.Context, vm *kubevirtv1.
 	return nil, fmt.Errorf("updateFn not set")
 }
 
+func (m *mockVMClient) GetVirtualMachineUsage(ctx context.Context, vmID string) (*kubevirt.ResourceUsage, error) {
+	if m.usageFn != nil {
+		return m.usageFn(ctx, vmID)
+	}
+	return nil, fmt.Errorf("usageFn not set")
+}
+
+func (m *mockVMClient) CheckNamespaceAccess(ctx context.Context) error {
+	if m.checkNamespaceAccessFn != nil {
+		return m.checkNamespaceAccessFn(ctx)
+	}
+	return fmt.Errorf("checkNamespaceAccessFn not set")
+}
+
+func (m *mockVMClient) ResolveNodePool(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	if m.resolveNodePoolFn != nil {
+		return m.resolveNodePoolFn(ctx, vm)
+	}
+	return nil
+}
+
+func (m *mockVMClient) CheckCapacity(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	if m.checkCapacityFn != nil {
+		return m.checkCapacityFn(ctx, vm)
+	}
+	return nil
+}
+
+func (m *mockVMClient) CheckDataVolumeSources(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	if m.checkDataVolumesFn != nil {
+		return m.checkDataVolumesFn(ctx, vm)
+	}
+	return nil
+}
+
+func (m *mockVMClient) GetDiskImports(ctx context.Context, vm *kubevirtv1.VirtualMachine) ([]kubevirt.DiskImport, error) {
+	if m.getDiskImportsFn != nil {
+		return m.getDiskImportsFn(ctx, vm)
+	}
+	return nil, nil
+}
+
+func (m *mockVMClient) CreateSecret(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error) {
+	if m.createSecretFn != nil {
+		return m.createSecretFn(ctx, secret)
+	}
+	return nil, fmt.Errorf("createSecretFn not set")
+}
+
+func (m *mockVMClient) GetSecret(ctx context.Context, name string) (*k8sv1.Secret, error) {
+	if m.getSecretFn != nil {
+		return m.getSecretFn(ctx, name)
+	}
+	return nil, fmt.Errorf("getSecretFn not set")
+}
+
+func (m *mockVMClient) UpdateSecret(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error) {
+	if m.updateSecretFn != nil {
+		return m.updateSecretFn(ctx, secret)
+	}
+	return nil, fmt.Errorf("updateSecretFn not set")
+}
+
+func (m *mockVMClient) DeleteSecret(ctx context.Context, name string) error {
+	if m.deleteSecretFn != nil {
+		return m.deleteSecretFn(ctx, name)
+	}
+	return fmt.Errorf("deleteSecretFn not set")
+}
+
+func (m *mockVMClient) DeleteSecretsByInstanceID(ctx context.Context, vmID string) error {
+	if m.deleteSecretsByIDFn != nil {
+		return m.deleteSecretsByIDFn(ctx, vmID)
+	}
+	return nil
+}
+
+func (m *mockVMClient) CreateService(ctx context.Context, service *k8sv1.Service) (*k8sv1.Service, error) {
+	if m.createServiceFn != nil {
+		return m.createServiceFn(ctx, service)
+	}
+	return nil, fmt.Errorf("createServiceFn not set")
+}
+
+func (m *mockVMClient) GetService(ctx context.Context, name string) (*k8sv1.Service, error) {
+	if m.getServiceFn != nil {
+		return m.getServiceFn(ctx, name)
+	}
+	return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "services"}, name)
+}
+
+func (m *mockVMClient) ListServices(ctx context.Context, vmID string) ([]k8sv1.Service, error) {
+	if m.listServicesFn != nil {
+		return m.listServicesFn(ctx, vmID)
+	}
+	return nil, nil
+}
+
+func (m *mockVMClient) DeleteService(ctx context.Context, name string) error {
+	if m.deleteServiceFn != nil {
+		return m.deleteServiceFn(ctx, name)
+	}
+	return fmt.Errorf("deleteServiceFn not set")
+}
+
+func (m *mockVMClient) CreatePersistentVolumeClaim(ctx context.Context, claim *k8sv1.PersistentVolumeClaim) (*k8sv1.PersistentVolumeClaim, error) {
+	if m.createClaimFn != nil {
+		return m.createClaimFn(ctx, claim)
+	}
+	return nil, fmt.Errorf("createClaimFn not set")
+}
+
 // mockVMMapper implements VMMapper for testing.
 type mockVMMapper struct {
-	vmSpecToVMFn func(vmSpec *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error)
-	vmToVMSpecFn func(vm *kubevirtv1.VirtualMachine) (*types.VMSpec, error)
+	vmSpecToVMFn  func(vmSpec *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error)
+	vmToVMSpecFn  func(vm *kubevirtv1.VirtualMachine) (*types.VMSpec, error)
+	resizeFn      func(vm *kubevirtv1.VirtualMachine, vcpu *int, memory *string) (bool, error)
+	secretsFn     func(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.Secret, error)
+	portServiceFn func(vmSpec *types.VMSpec, vmID string) (*k8sv1.Service, error)
+	claimsFn      func(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.PersistentVolumeClaim, error)
+	subdomainFn   func(vmSpec *types.VMSpec) (*k8sv1.Service, error)
 }
 
 func (m *mockVMMapper) VMSpecToVirtualMachine(vmSpec *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error) {
@@ -73,3 +235,38 @@ func (m *mockVMMapper) VirtualMachineToVMSpec(vm *kubevirtv1.VirtualMachine) (*t
 	}
 	return nil, fmt.Errorf("vmToVMSpecFn not set")
 }
+
+func (m *mockVMMapper) ResizeVirtualMachine(vm *kubevirtv1.VirtualMachine, vcpu *int, memory *string) (bool, error) {
+	if m.resizeFn != nil {
+		return m.resizeFn(vm, vcpu, memory)
+	}
+	return false, fmt.Errorf("resizeFn not set")
+}
+
+func (m *mockVMMapper) Secrets(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.Secret, error) {
+	if m.secretsFn != nil {
+		return m.secretsFn(vmSpec, vmID)
+	}
+	return nil, nil
+}
+
+func (m *mockVMMapper) PortService(vmSpec *types.VMSpec, vmID string) (*k8sv1.Service, error) {
+	if m.portServiceFn != nil {
+		return m.portServiceFn(vmSpec, vmID)
+	}
+	return nil, nil
+}
+
+func (m *mockVMMapper) SubdomainService(vmSpec *types.VMSpec) (*k8sv1.Service, error) {
+	if m.subdomainFn != nil {
+		return m.subdomainFn(vmSpec)
+	}
+	return nil, nil
+}
+
+func (m *mockVMMapper) PersistentVolumeClaims(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.PersistentVolumeClaim, error) {
+	if m.claimsFn != nil {
+		return m.claimsFn(vmSpec, vmID)
+	}
+	return nil, nil
+}
@@ -0,0 +1,313 @@
+package kubevirt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// Provider hints controlling how the SSH public key reaches the guest
+const (
+	sshKeyPropagationHint = "ssh_key_propagation"
+	sshUsersHint          = "ssh_users"
+)
+
+// ErrSecretNotManaged is returned when a Secret a VM needs already exists but
+// does not carry the DCM labels of that VM
+var ErrSecretNotManaged = errors.New("secret not managed for this VM")
+
+// sshPublicKeySecretKey is the Secret key holding the VM's SSH public key. Keys
+// stored separately use it as a prefix, followed by their position.
+const sshPublicKeySecretKey = "ssh-publickey"
+
+// SSHKeySecretLayout selects how multiple SSH public keys are stored in the
+// key Secret. KubeVirt authorizes the keys of every Secret entry, so both
+// layouts grant the same access.
+type SSHKeySecretLayout string
+
+const (
+	// SSHKeySecretJoined stores all keys newline-separated under one entry
+	SSHKeySecretJoined SSHKeySecretLayout = "joined"
+	// SSHKeySecretSeparate stores each key under its own entry
+	SSHKeySecretSeparate SSHKeySecretLayout = "separate"
+)
+
+// ParseSSHKeySecretLayout validates an SSH key Secret layout
+func ParseSSHKeySecretLayout(s string) (SSHKeySecretLayout, error) {
+	switch l := SSHKeySecretLayout(s); l {
+	case SSHKeySecretJoined, SSHKeySecretSeparate:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown SSH key secret layout %q: must be %s or %s", s, SSHKeySecretJoined, SSHKeySecretSeparate)
+	}
+}
+
+// SSHKeyPropagation selects how KubeVirt propagates access credentials to the guest
+type SSHKeyPropagation string
+
+const (
+	// SSHKeyPropagationNoCloud injects keys through cloud-init at first boot only
+	SSHKeyPropagationNoCloud SSHKeyPropagation = "nocloud"
+	// SSHKeyPropagationQemuGuestAgent injects keys through the qemu guest agent,
+	// which must run in the guest, so keys can be rotated on a running VM
+	SSHKeyPropagationQemuGuestAgent SSHKeyPropagation = "qemu-guest-agent"
+)
+
+// ParseSSHKeyPropagation validates an SSH key propagation method
+func ParseSSHKeyPropagation(s string) (SSHKeyPropagation, error) {
+	switch p := SSHKeyPropagation(s); p {
+	case SSHKeyPropagationNoCloud, SSHKeyPropagationQemuGuestAgent:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown SSH key propagation method %q", s)
+	}
+}
+
+// sshKeySecretName returns the name of the Secret holding a VM's SSH public key
+func sshKeySecretName(vmID string) string {
+	return virtualMachineName(vmID) + "-ssh"
+}
+
+// sshPublicKey returns the SSH public key requested for the VM, if any
+func sshPublicKey(vmSpec *types.VMSpec) string {
+	if vmSpec.Access == nil || vmSpec.Access.SshPublicKey == nil {
+		return ""
+	}
+	return strings.TrimSpace(*vmSpec.Access.SshPublicKey)
+}
+
+// checkSSHKeyLimits rejects SSH public keys exceeding the configured count or
+// total size. Keys are counted one per line, ignoring blank and comment lines
+// as authorized_keys does.
+func (m *Mapper) checkSSHKeyLimits(key string) error {
+	if m.maxSSHKeyBytes > 0 && len(key) > m.maxSSHKeyBytes {
+		return fmt.Errorf("SSH public keys are %d bytes, exceeding the limit of %d", len(key), m.maxSSHKeyBytes)
+	}
+	if m.maxSSHKeys > 0 {
+		count := 0
+		for _, line := range strings.Split(key, "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				count++
+			}
+		}
+		if count > m.maxSSHKeys {
+			return fmt.Errorf("%d SSH public keys exceed the limit of %d", count, m.maxSSHKeys)
+		}
+	}
+	return nil
+}
+
+// parseSSHPublicKeys splits SSH public keys into one entry per key, dropping
+// blank and comment lines, and rejects entries that are not well-formed
+// authorized_keys lines.
+func parseSSHPublicKeys(key string) ([]string, error) {
+	var keys []string
+	for i, line := range strings.Split(key, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line)); err != nil {
+			return nil, fmt.Errorf("invalid SSH public key on line %d: %w", i+1, err)
+		}
+		keys = append(keys, line)
+	}
+	return keys, nil
+}
+
+// defaultGuestUser returns the default login user of the guest OS images
+func defaultGuestUser(guestOS types.GuestOS) string {
+	switch strings.ToLower(guestOS.Type) {
+	case "ubuntu", "centos", "fedora":
+		return strings.ToLower(guestOS.Type)
+	default:
+		return "cirros"
+	}
+}
+
+// sshKeyPropagation resolves the propagation method for a VM, preferring the
+// ssh_key_propagation provider hint over the mapper default.
+func (m *Mapper) sshKeyPropagation(vmSpec *types.VMSpec) (SSHKeyPropagation, error) {
+	var method string
+	if _, err := decodeHint(vmSpec, sshKeyPropagationHint, &method); err != nil {
+		return "", err
+	}
+	if method == "" {
+		return m.sshKeyPropagationDefault, nil
+	}
+	return ParseSSHKeyPropagation(method)
+}
+
+// buildAccessCredentials returns the access credentials injecting the VM's SSH
+// public key, or nil when no key was requested.
+func (m *Mapper) buildAccessCredentials(vmSpec *types.VMSpec, vmID string) ([]kubevirtv1.AccessCredential, error) {
+	key := sshPublicKey(vmSpec)
+	if key == "" {
+		return nil, nil
+	}
+	if err := m.checkSSHKeyLimits(key); err != nil {
+		return nil, err
+	}
+	if _, err := parseSSHPublicKeys(key); err != nil {
+		return nil, err
+	}
+	method, err := m.sshKeyPropagation(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	propagation := kubevirtv1.SSHPublicKeyAccessCredentialPropagationMethod{}
+	switch method {
+	case SSHKeyPropagationQemuGuestAgent:
+		var users []string
+		if _, err := decodeHint(vmSpec, sshUsersHint, &users); err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			users = []string{defaultGuestUser(vmSpec.GuestOs)}
+		}
+		propagation.QemuGuestAgent = &kubevirtv1.QemuGuestAgentSSHPublicKeyAccessCredentialPropagation{
+			Users: users,
+		}
+	default:
+		propagation.NoCloud = &kubevirtv1.NoCloudSSHPublicKeyAccessCredentialPropagation{}
+	}
+
+	return []kubevirtv1.AccessCredential{{
+		SSHPublicKey: &kubevirtv1.SSHPublicKeyAccessCredential{
+			Source: kubevirtv1.SSHPublicKeyAccessCredentialSource{
+				Secret: &kubevirtv1.AccessCredentialSecretSource{
+					SecretName: sshKeySecretName(vmID),
+				},
+			},
+			PropagationMethod: propagation,
+		},
+	}}, nil
+}
+
+// annotateSSHPublicKey records the VM's SSH public key on the VirtualMachine, so
+// that VirtualMachineToVMSpec can report it. KubeVirt keeps injecting the key
+// from the Secret referenced by the access credentials.
+func annotateSSHPublicKey(vmSpec *types.VMSpec, vm *kubevirtv1.VirtualMachine) {
+	key := sshPublicKey(vmSpec)
+	if key == "" {
+		return
+	}
+	if vm.Annotations == nil {
+		vm.Annotations = map[string]string{}
+	}
+	vm.Annotations[constants.DCMAnnotationSSHPublicKey] = key
+}
+
+// accessFromVirtualMachine returns the access settings recorded on a
+// VirtualMachine, or nil when it carries no SSH public key
+func accessFromVirtualMachine(vm *kubevirtv1.VirtualMachine) *types.Access {
+	key := vm.Annotations[constants.DCMAnnotationSSHPublicKey]
+	if key == "" {
+		return nil
+	}
+	return &types.Access{SshPublicKey: &key}
+}
+
+// hasNoCloudPropagation reports whether any credential is propagated through cloud-init
+func hasNoCloudPropagation(credentials []kubevirtv1.AccessCredential) bool {
+	for _, c := range credentials {
+		if c.SSHPublicKey != nil && c.SSHPublicKey.PropagationMethod.NoCloud != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasQemuGuestAgentPropagation reports whether any credential is propagated
+// through the qemu guest agent
+func hasQemuGuestAgentPropagation(credentials []kubevirtv1.AccessCredential) bool {
+	for _, c := range credentials {
+		if c.SSHPublicKey != nil && c.SSHPublicKey.PropagationMethod.QemuGuestAgent != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// sshKeySecret returns the Secret referenced by the VM's access credentials, or
+// nil when no SSH public key was requested.
+func (m *Mapper) sshKeySecret(vmSpec *types.VMSpec, vmID string) (*k8sv1.Secret, error) {
+	key := sshPublicKey(vmSpec)
+	if key == "" {
+		return nil, nil
+	}
+	keys, err := parseSSHPublicKeys(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string][]byte{}
+	if m.sshKeySecretLayout == SSHKeySecretSeparate {
+		for i, k := range keys {
+			data[fmt.Sprintf("%s-%d", sshPublicKeySecretKey, i+1)] = []byte(k)
+		}
+	} else {
+		data[sshPublicKeySecretKey] = []byte(strings.Join(keys, "\n"))
+	}
+	secret := &k8sv1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      sshKeySecretName(vmID),
+			Namespace: m.namespace,
+			Labels: map[string]string{
+				constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+				constants.DCMLabelInstanceID: vmID,
+				constants.DCMLabelSecretType: constants.DCMSecretTypeSSHKey,
+			},
+		},
+		Type: k8sv1.SecretTypeOpaque,
+		Data: data,
+	}
+	m.applyAccessMetadata(&secret.ObjectMeta)
+	return secret, nil
+}
+
+// CheckSecretManaged fails with ErrSecretNotManaged unless an existing Secret is
+// labeled as managed by DCM for the VM with the given instance ID, so that
+// Secrets of other VMs or owners are never taken over.
+func CheckSecretManaged(secret *k8sv1.Secret, vmID string) error {
+	labels := secret.GetLabels()
+	if labels[constants.DCMLabelManagedBy] != constants.DCMManagedByValue || labels[constants.DCMLabelInstanceID] != vmID {
+		return fmt.Errorf("%w: secret %s already exists", ErrSecretNotManaged, secret.Name)
+	}
+	return nil
+}
+
+// Secrets returns the Secrets a VM depends on: the cloud-init user data when it
+// is stored in a Secret, and the SSH public key for access credentials. The
+// caller is expected to create them before the VM and owner-reference them to it.
+func (m *Mapper) Secrets(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.Secret, error) {
+	var secrets []*k8sv1.Secret
+	cloudInit, err := m.CloudInitSecret(vmSpec, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if cloudInit != nil {
+		secrets = append(secrets, cloudInit)
+	}
+	keySecret, err := m.sshKeySecret(vmSpec, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if keySecret != nil {
+		secrets = append(secrets, keySecret)
+	}
+	return secrets, nil
+}
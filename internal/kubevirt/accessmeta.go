@@ -0,0 +1,64 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// dcmKeyPrefix is reserved for the labels and annotations DCM manages itself
+const dcmKeyPrefix = "dcm.project/"
+
+// ValidateAccessMetadata checks the extra labels and annotations configured for
+// the resources that give access to a VM. Keys must be qualified names outside
+// the dcm.project/ prefix, and label values valid label values.
+func ValidateAccessMetadata(labels, annotations map[string]string) error {
+	for key, value := range labels {
+		if err := validateAccessKey("label", key); err != nil {
+			return err
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid value %q for label %q: %s", value, key, strings.Join(errs, "; "))
+		}
+	}
+	for key := range annotations {
+		if err := validateAccessKey("annotation", key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateAccessKey(kind, key string) error {
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return fmt.Errorf("invalid %s key %q: %s", kind, key, strings.Join(errs, "; "))
+	}
+	if strings.HasPrefix(key, dcmKeyPrefix) {
+		return fmt.Errorf("invalid %s key %q: the %s prefix is reserved", kind, key, dcmKeyPrefix)
+	}
+	return nil
+}
+
+// applyAccessMetadata adds the configured extra labels and annotations to the
+// SSH key Secret or port Service of a VM. Keys already set are kept, so the
+// DCM labels used to find and clean up the resource always win.
+func (m *Mapper) applyAccessMetadata(meta *metav1.ObjectMeta) {
+	if len(m.accessLabels) > 0 && meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	for key, value := range m.accessLabels {
+		if _, ok := meta.Labels[key]; !ok {
+			meta.Labels[key] = value
+		}
+	}
+	if len(m.accessAnnotations) > 0 && meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	for key, value := range m.accessAnnotations {
+		if _, ok := meta.Annotations[key]; !ok {
+			meta.Annotations[key] = value
+		}
+	}
+}
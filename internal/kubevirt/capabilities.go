@@ -0,0 +1,67 @@
+package kubevirt
+
+import (
+	"slices"
+
+	k8sv1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// snapshotsSupported reports whether the provider can snapshot VMs. No
+// snapshot operations are implemented yet.
+const snapshotsSupported = false
+
+// Capabilities describes what VMs the provider can create with its current
+// configuration. Zero limits mean unlimited.
+type Capabilities struct {
+	GuestOS                  []string
+	MaxVCPU                  int
+	MaxMemory                string
+	MaxDiskCapacity          string
+	DiskBuses                []string
+	SSHKeyPropagation        []string
+	DefaultSSHKeyPropagation string
+	PortServiceTypes         []string
+	LiveMigration            bool
+	Snapshots                bool
+}
+
+// NewCapabilities assembles the capabilities from the mapper and the create
+// policy, which may be nil. Guest OS types are those the policy allows, or
+// every type with a built-in image when it allows all.
+func NewCapabilities(m *Mapper, p *Policy) Capabilities {
+	c := Capabilities{
+		DiskBuses: []string{string(kubevirtv1.DiskBusVirtio)},
+		SSHKeyPropagation: []string{
+			string(SSHKeyPropagationNoCloud),
+			string(SSHKeyPropagationQemuGuestAgent),
+		},
+		DefaultSSHKeyPropagation: string(m.sshKeyPropagationDefault),
+		PortServiceTypes: []string{
+			string(k8sv1.ServiceTypeNodePort),
+			string(k8sv1.ServiceTypeLoadBalancer),
+			string(k8sv1.ServiceTypeClusterIP),
+		},
+		LiveMigration: m.maintenanceReady,
+		Snapshots:     snapshotsSupported,
+	}
+
+	if p != nil && len(p.allowedGuestOS) > 0 {
+		c.GuestOS = slices.Clone(p.allowedGuestOS)
+	} else {
+		for guestOS := range guestOSImages {
+			c.GuestOS = append(c.GuestOS, guestOS)
+		}
+		slices.Sort(c.GuestOS)
+	}
+	if p != nil {
+		c.MaxVCPU = p.maxVCPU
+		if !p.maxMemory.IsZero() {
+			c.MaxMemory = p.maxMemory.String()
+		}
+		if !p.maxDiskCapacity.IsZero() {
+			c.MaxDiskCapacity = p.maxDiskCapacity.String()
+		}
+	}
+	return c
+}
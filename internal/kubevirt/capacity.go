@@ -0,0 +1,141 @@
+package kubevirt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// ErrInsufficientCapacity is returned when a VM would exceed the namespace quota
+// or does not fit on any node of the cluster.
+var ErrInsufficientCapacity = errors.New("insufficient capacity")
+
+// ParseOverhead parses the CPU and memory KubeVirt adds to each VM for its
+// launcher pod into a resource list. Empty values count as no overhead.
+func ParseOverhead(cpu, memory string) (k8sv1.ResourceList, error) {
+	overhead := k8sv1.ResourceList{}
+	for name, value := range map[k8sv1.ResourceName]string{
+		k8sv1.ResourceCPU:    cpu,
+		k8sv1.ResourceMemory: memory,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s overhead %q: %w", name, value, err)
+		}
+		if quantity.Sign() < 0 {
+			return nil, fmt.Errorf("invalid %s overhead %q: must not be negative", name, value)
+		}
+		overhead[name] = quantity
+	}
+	return overhead, nil
+}
+
+// capacityRequests returns the CPU and memory the VM's launcher pod requests:
+// the guest resources plus the configured KubeVirt overhead
+func (c *Client) capacityRequests(vm *kubevirtv1.VirtualMachine) k8sv1.ResourceList {
+	requests := k8sv1.ResourceList{}
+	if vm.Spec.Template != nil {
+		for _, name := range []k8sv1.ResourceName{k8sv1.ResourceCPU, k8sv1.ResourceMemory} {
+			if quantity, ok := vm.Spec.Template.Spec.Domain.Resources.Requests[name]; ok {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for name, overhead := range c.overhead {
+		quantity := requests[name]
+		quantity.Add(overhead)
+		requests[name] = quantity
+	}
+	return requests
+}
+
+// CheckCapacity verifies that the VM, including KubeVirt's per-VM overhead, fits
+// in the remaining ResourceQuota of the namespace and on at least one node
+// matching its node selector. Checks whose objects cannot be listed are skipped.
+func (c *Client) CheckCapacity(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	requests := c.capacityRequests(vm)
+	if len(requests) == 0 {
+		return nil
+	}
+
+	quotas, err := c.coreClient.CoreV1().ResourceQuotas(c.namespace).List(ctx, metav1.ListOptions{})
+	switch {
+	case apierrors.IsForbidden(err):
+		zap.S().Warnw("Cannot list resource quotas, skipping quota check", "error", err)
+	case err != nil:
+		return fmt.Errorf("failed to list resource quotas: %w", err)
+	default:
+		for _, quota := range quotas.Items {
+			if err := quotaFits(&quota, requests); err != nil {
+				return err
+			}
+		}
+	}
+
+	var selector map[string]string
+	if vm.Spec.Template != nil {
+		selector = vm.Spec.Template.Spec.NodeSelector
+	}
+	nodes, err := c.coreClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(selector).String(),
+	})
+	if apierrors.IsForbidden(err) {
+		zap.S().Warnw("Cannot list nodes, skipping node capacity check", "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if len(nodes.Items) == 0 {
+		return nil
+	}
+	for _, node := range nodes.Items {
+		if nodeHasResources(&node, requests) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no node has %s allocatable", ErrInsufficientCapacity, formatResources(requests))
+}
+
+// quotaFits checks the requests against the unused CPU and memory of a quota,
+// which may limit either the requests.* or the bare resource names
+func quotaFits(quota *k8sv1.ResourceQuota, requests k8sv1.ResourceList) error {
+	for name, quantity := range requests {
+		for _, limited := range []k8sv1.ResourceName{"requests." + name, name} {
+			hard, ok := quota.Status.Hard[limited]
+			if !ok {
+				hard, ok = quota.Spec.Hard[limited]
+			}
+			if !ok {
+				continue
+			}
+			remaining := hard.DeepCopy()
+			if used, ok := quota.Status.Used[limited]; ok {
+				remaining.Sub(used)
+			}
+			if remaining.Cmp(quantity) < 0 {
+				return fmt.Errorf("%w: %s needs %s but only %s remains in quota %q",
+					ErrInsufficientCapacity, limited, quantity.String(), remaining.String(), quota.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// formatResources renders CPU and memory requests in a stable order
+func formatResources(requests k8sv1.ResourceList) string {
+	cpu := requests[k8sv1.ResourceCPU]
+	memory := requests[k8sv1.ResourceMemory]
+	return fmt.Sprintf("cpu %s and memory %s", cpu.String(), memory.String())
+}
@@ -0,0 +1,90 @@
+package kubevirt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// serialChannelsHint lists the virtio-serial channels a VM is given, replacing
+// the mapper default
+const serialChannelsHint = "serial_channels"
+
+// Virtio-serial channels that can be requested for a VM
+const (
+	// GuestAgentChannel is the channel of the qemu guest agent
+	GuestAgentChannel = "org.qemu.guest_agent.0"
+	// DownwardMetricsChannel is the vhostmd channel exposing host metrics to the guest
+	DownwardMetricsChannel = "org.github.vhostmd.1"
+)
+
+// ParseSerialChannels validates the names of virtio-serial channels. KubeVirt
+// only exposes fixed channels, so arbitrary names cannot be attached.
+func ParseSerialChannels(names []string) ([]string, error) {
+	var channels []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+			continue
+		case GuestAgentChannel, DownwardMetricsChannel:
+		default:
+			return nil, fmt.Errorf("unsupported serial channel %q: must be %s or %s",
+				name, GuestAgentChannel, DownwardMetricsChannel)
+		}
+		if !slices.Contains(channels, name) {
+			channels = append(channels, name)
+		}
+	}
+	return channels, nil
+}
+
+// serialChannels resolves the channels of a VM, preferring the serial_channels
+// provider hint over the mapper default. The guest agent channel is always
+// included when SSH keys are propagated through the agent.
+func (m *Mapper) serialChannels(vmSpec *types.VMSpec, credentials []kubevirtv1.AccessCredential) ([]string, error) {
+	var requested []string
+	found, err := decodeHint(vmSpec, serialChannelsHint, &requested)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		requested = m.serialChannelsDefault
+	}
+	channels, err := ParseSerialChannels(requested)
+	if err != nil {
+		return nil, err
+	}
+	if hasQemuGuestAgentPropagation(credentials) && !slices.Contains(channels, GuestAgentChannel) {
+		channels = append(channels, GuestAgentChannel)
+	}
+	return channels, nil
+}
+
+// applySerialChannels attaches the VM's virtio-serial channels. KubeVirt adds
+// the guest agent channel to every domain, so it only needs recording; the
+// downward metrics channel is enabled through its device. The channels are
+// listed in an annotation of the VM template.
+func (m *Mapper) applySerialChannels(vmSpec *types.VMSpec, template *kubevirtv1.VirtualMachineInstanceTemplateSpec) error {
+	channels, err := m.serialChannels(vmSpec, template.Spec.AccessCredentials)
+	if err != nil {
+		return err
+	}
+	if len(channels) == 0 {
+		return nil
+	}
+
+	if slices.Contains(channels, DownwardMetricsChannel) {
+		template.Spec.Domain.Devices.DownwardMetrics = &kubevirtv1.DownwardMetrics{}
+	}
+	if template.ObjectMeta.Annotations == nil {
+		template.ObjectMeta.Annotations = map[string]string{}
+	}
+	template.ObjectMeta.Annotations[constants.DCMAnnotationSerialChannels] = strings.Join(channels, ",")
+	return nil
+}
@@ -2,29 +2,80 @@ package kubevirt
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"golang.org/x/sync/singleflight"
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/cache"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
-	"github.com/dcm-project/kubevirt-service-provider/internal/config"
 	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
 )
 
+// instanceIDIndex is the name of the VM cache index keyed by the DCM instance ID label
+const instanceIDIndex = "dcmInstanceID"
+
+// namespaceCheckKey coalesces namespace probes in the lookup group; the slash
+// keeps it apart from instance IDs, which are label values
+const namespaceCheckKey = "namespace/check"
+
+var (
+	virtualMachineGVR = schema.GroupVersionResource{
+		Group:    "kubevirt.io",
+		Version:  "v1",
+		Resource: "virtualmachines",
+	}
+	namespaceGVR = schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "namespaces",
+	}
+)
+
 // Client wraps a typed REST client for KubeVirt VM operations
 type Client struct {
 	restClient    *rest.RESTClient
 	dynamicClient dynamic.Interface
+	coreClient    kubernetes.Interface
 	namespace     string
 	timeout       time.Duration
 	maxRetries    int
+	retryBackoff  time.Duration
+	overhead      k8sv1.ResourceList
+
+	vmInformerFactory dynamicinformer.DynamicSharedInformerFactory
+	vmInformer        cache.SharedIndexInformer
+
+	namespaceCheckTTL  time.Duration
+	namespaceCheckMu   sync.Mutex
+	namespaceCheckedAt time.Time
+	namespaceCheckErr  error
+
+	getGroup       singleflight.Group
+	getCoalesceTTL time.Duration
+	recentGetsMu   sync.Mutex
+	recentGets     map[string]recentGet
+	// writeEpoch counts the VM changes made by this client, so that a fetch
+	// overlapping a change is not reused
+	writeEpoch uint64
+
+	recentWritesMu sync.Mutex
+	recentWrites   map[string]recentWrite
 }
 
 var (
@@ -53,65 +104,114 @@ func init() {
 	kubevirtParameterCodec = runtime.NewParameterCodec(kubevirtScheme)
 }
 
-// NewClient creates a new KubeVirt client with a typed REST client for VM operations
-// and a dynamic client for informers
-func NewClient(cfg *config.KubernetesConfig) (*Client, error) {
-	var restConfig *rest.Config
-	var err error
-
-	if cfg.Kubeconfig != "" {
-		restConfig, err = clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build config from kubeconfig file %s: %w", cfg.Kubeconfig, err)
-		}
-	} else {
-		restConfig, err = rest.InClusterConfig()
-		if err != nil {
-			return nil, fmt.Errorf("failed to build in-cluster config: %w", err)
-		}
+// ValidateNamespace checks that a namespace name is a valid DNS-1123 label
+func ValidateNamespace(namespace string) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))
 	}
+	return nil
+}
 
-	// Create typed REST client for KubeVirt API
-	kubevirtConfig := *restConfig
-	kubevirtConfig.GroupVersion = &schema.GroupVersion{Group: "kubevirt.io", Version: "v1"}
-	kubevirtConfig.APIPath = "/apis"
-	kubevirtConfig.NegotiatedSerializer = serializer.WithoutConversionCodecFactory{CodecFactory: kubevirtCodecs}
-	if kubevirtConfig.UserAgent == "" {
-		kubevirtConfig.UserAgent = rest.DefaultKubernetesUserAgent()
+// setupVMCache creates a VM informer indexed by the DCM instance ID label.
+// The informer is not started until StartVMCache is called.
+func (c *Client) setupVMCache(resyncPeriod time.Duration) error {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		c.dynamicClient,
+		resyncPeriod,
+		c.namespace,
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = fmt.Sprintf("%s=%s", constants.DCMLabelManagedBy, constants.DCMManagedByValue)
+		},
+	)
+	informer := factory.ForResource(virtualMachineGVR).Informer()
+	if err := informer.AddIndexers(cache.Indexers{instanceIDIndex: instanceIDIndexFunc}); err != nil {
+		return fmt.Errorf("failed to add VM instance ID indexer: %w", err)
 	}
+	c.vmInformerFactory = factory
+	c.vmInformer = informer
+	return nil
+}
 
-	restClient, err := rest.RESTClientFor(&kubevirtConfig)
+// instanceIDIndexFunc indexes objects by their DCM instance ID label
+func instanceIDIndexFunc(obj interface{}) ([]string, error) {
+	accessor, err := meta.Accessor(obj)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create KubeVirt REST client: %w", err)
+		return nil, err
+	}
+	if id := accessor.GetLabels()[constants.DCMLabelInstanceID]; id != "" {
+		return []string{id}, nil
 	}
+	return nil, nil
+}
 
-	// Create dynamic client
-	dynamicClient, err := dynamic.NewForConfig(restConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+// StartVMCache starts the VM informer cache and waits for it to sync.
+// It is a no-op when the cache is disabled.
+func (c *Client) StartVMCache(ctx context.Context) error {
+	if c.vmInformer == nil {
+		return nil
+	}
+	c.vmInformerFactory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.vmInformer.HasSynced) {
+		return fmt.Errorf("failed to sync VirtualMachine cache")
+	}
+	return nil
+}
+
+// getCachedVirtualMachine looks up a VM by DCM instance ID in the informer cache.
+// It returns nil when the cache is disabled, not yet synced, has no match, or
+// still holds the VM as it was before this client last changed it.
+func (c *Client) getCachedVirtualMachine(vmID string) *kubevirtv1.VirtualMachine {
+	u := c.cachedObject(vmID)
+	if u == nil || c.cacheBehindWrite(vmID, u.GetResourceVersion()) {
+		return nil
+	}
+	vm := &kubevirtv1.VirtualMachine{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, vm); err != nil {
+		return nil
 	}
+	vm.SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
+	return vm
+}
 
-	return &Client{
-		restClient:    restClient,
-		dynamicClient: dynamicClient,
-		namespace:     cfg.Namespace,
-		timeout:       cfg.Timeout,
-		maxRetries:    cfg.MaxRetries,
-	}, nil
+// cachedObject returns the informer cache entry of a VM by DCM instance ID, or
+// nil when the cache is disabled, not yet synced, or has no match
+func (c *Client) cachedObject(vmID string) *unstructured.Unstructured {
+	if c.vmInformer == nil || !c.vmInformer.HasSynced() {
+		return nil
+	}
+	items, err := c.vmInformer.GetIndexer().ByIndex(instanceIDIndex, vmID)
+	if err != nil || len(items) == 0 {
+		return nil
+	}
+	u, _ := items[0].(*unstructured.Unstructured)
+	return u
 }
 
 // CreateVirtualMachine creates a new VirtualMachine in the cluster
 func (c *Client) CreateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	normalizeRunStrategy(vm)
 
 	result := &kubevirtv1.VirtualMachine{}
-	err := c.restClient.Post().
-		Resource("virtualmachines").
-		Namespace(c.namespace).
-		Body(vm).
-		Do(timeoutCtx).
-		Into(result)
+	attempts := 0
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		attempts++
+		return c.restClient.Post().
+			Resource("virtualmachines").
+			Namespace(c.namespace).
+			Body(vm).
+			Do(ctx).
+			Into(result)
+	})
+	// A create that failed ambiguously may still have gone through, so a
+	// retry reporting the VM as already existing adopts it when it is ours
+	if attempts > 1 && apierrors.IsAlreadyExists(err) {
+		if existing, getErr := c.getCreatedVirtualMachine(ctx, vm); getErr == nil {
+			result, err = existing, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create VirtualMachine: %w", err)
 	}
@@ -119,92 +219,393 @@ func (c *Client) CreateVirtualMachine(ctx context.Context, vm *kubevirtv1.Virtua
 	return result, nil
 }
 
-// GetVirtualMachine retrieves a VirtualMachine by DCM instance ID
+// getCreatedVirtualMachine returns the VM named like vm when it carries the
+// same DCM instance ID, or an error when it belongs to someone else
+func (c *Client) getCreatedVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	existing := &kubevirtv1.VirtualMachine{}
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		return c.restClient.Get().
+			Resource("virtualmachines").
+			Namespace(c.namespace).
+			Name(vm.Name).
+			Do(ctx).
+			Into(existing)
+	})
+	if err != nil {
+		return nil, err
+	}
+	instanceID := vm.Labels[constants.DCMLabelInstanceID]
+	if instanceID == "" || existing.Labels[constants.DCMLabelInstanceID] != instanceID {
+		return nil, fmt.Errorf("VirtualMachine %s belongs to another instance", vm.Name)
+	}
+	return existing, nil
+}
+
+// GetVirtualMachine retrieves a VirtualMachine by DCM instance ID. The indexed
+// informer cache is consulted first, falling back to a live list on a miss.
+// After this client changes a VM, its cache entry is skipped until the
+// informer observes a newer version, so callers read their own writes.
+// Concurrent live lookups for the same ID share a single request, and results
+// are reused for the configured coalescing TTL.
 func (c *Client) GetVirtualMachine(ctx context.Context, vmID string) (*kubevirtv1.VirtualMachine, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
+	if vm := c.getCachedVirtualMachine(vmID); vm != nil {
+		return vm, nil
+	}
+	if vm := c.getRecentVirtualMachine(vmID); vm != nil {
+		return vm, nil
+	}
 
+	// The shared fetch must not fail for every waiter when the first caller goes away
+	result, err, _ := c.getGroup.Do(vmID, func() (interface{}, error) {
+		epoch := c.currentWriteEpoch()
+		vm, err := c.fetchVirtualMachine(context.WithoutCancel(ctx), vmID)
+		if err == nil {
+			c.rememberVirtualMachine(vmID, vm, epoch)
+		}
+		return vm, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*kubevirtv1.VirtualMachine).DeepCopy(), nil
+}
+
+// fetchVirtualMachine looks up a VirtualMachine by DCM instance ID in the cluster
+func (c *Client) fetchVirtualMachine(ctx context.Context, vmID string) (*kubevirtv1.VirtualMachine, error) {
 	vmList := &kubevirtv1.VirtualMachineList{}
-	err := c.restClient.Get().
-		Resource("virtualmachines").
-		Namespace(c.namespace).
-		VersionedParams(&metav1.ListOptions{
-			LabelSelector: fmt.Sprintf("%s=%s", constants.DCMLabelInstanceID, vmID),
-		}, kubevirtParameterCodec).
-		Do(timeoutCtx).
-		Into(vmList)
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		return c.restClient.Get().
+			Resource("virtualmachines").
+			Namespace(c.namespace).
+			VersionedParams(&metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", constants.DCMLabelInstanceID, vmID),
+			}, kubevirtParameterCodec).
+			Do(ctx).
+			Into(vmList)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get VirtualMachine by dcmlabelinstanceid: %w", err)
 	}
 	if len(vmList.Items) == 0 {
-		return nil, fmt.Errorf("VirtualMachine with dcmlabelinstanceid %q not found", vmID)
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachines"}, vmID)
 	}
 	vmList.Items[0].SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
 	return &vmList.Items[0], nil
 }
 
-// ListVirtualMachines lists all VirtualMachines in the namespace
-func (c *Client) ListVirtualMachines(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-
+// ListVirtualMachines lists the VirtualMachines in the namespace. With a limit
+// set in the options it returns one page and the continue token of the next
+// page, which is empty on the last page.
+func (c *Client) ListVirtualMachines(ctx context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
 	vmList := &kubevirtv1.VirtualMachineList{}
-	err := c.restClient.Get().
-		Resource("virtualmachines").
-		Namespace(c.namespace).
-		VersionedParams(&options, kubevirtParameterCodec).
-		Do(timeoutCtx).
-		Into(vmList)
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		return c.restClient.Get().
+			Resource("virtualmachines").
+			Namespace(c.namespace).
+			VersionedParams(&options, kubevirtParameterCodec).
+			Do(ctx).
+			Into(vmList)
+	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	for i := range vmList.Items {
 		vmList.Items[i].SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
 	}
-	return vmList.Items, nil
+	return vmList.Items, vmList.Continue, nil
 }
 
-// DeleteVirtualMachine deletes a VirtualMachine by DCM instance ID
-func (c *Client) DeleteVirtualMachine(ctx context.Context, vmId string) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
-	defer cancel()
-
+// DeleteVirtualMachine deletes a VirtualMachine by DCM instance ID. A non-nil
+// gracePeriodSeconds overrides the grace period of the VM; 0 deletes it
+// immediately.
+func (c *Client) DeleteVirtualMachine(ctx context.Context, vmId string, gracePeriodSeconds *int64) error {
 	item, err := c.GetVirtualMachine(ctx, vmId)
 	if err != nil {
 		return fmt.Errorf("failed to get VirtualMachine by dcmlabelinstanceid: %w", err)
 	}
-	if item == nil {
-		return fmt.Errorf("VirtualMachine with dcmlabelinstanceid %q not found", vmId)
+	c.forgetVirtualMachine(vmId)
+
+	attempts := 0
+	return c.withRetry(ctx, func(ctx context.Context) error {
+		attempts++
+		err := c.restClient.Delete().
+			Resource("virtualmachines").
+			Namespace(c.namespace).
+			Name(item.Name).
+			Body(&metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds}).
+			Do(ctx).
+			Error()
+		// A delete that failed ambiguously may still have gone through, so a
+		// retry finding the VM gone means it was deleted
+		if attempts > 1 && apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	})
+}
+
+// UpdateVirtualMachine updates an existing VirtualMachine. A legacy spec.running
+// field is migrated to the equivalent RunStrategy before the update is sent.
+func (c *Client) UpdateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	normalizeRunStrategy(vm)
+	c.forgetVirtualMachine(vm.Labels[constants.DCMLabelInstanceID])
+
+	result := &kubevirtv1.VirtualMachine{}
+	err := c.withRetry(ctx, func(ctx context.Context) error {
+		return c.restClient.Put().
+			Resource("virtualmachines").
+			Namespace(c.namespace).
+			Name(vm.Name).
+			Body(vm).
+			Do(ctx).
+			Into(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
+	return result, nil
+}
+
+// StartVirtualMachine asks KubeVirt to start a VirtualMachine by DCM instance ID.
+// This is how VMs with the Manual run strategy are brought up. Starting a
+// running VM is a no-op.
+func (c *Client) StartVirtualMachine(ctx context.Context, vmID string) error {
+	item, err := c.GetVirtualMachine(ctx, vmID)
+	if err != nil {
+		return fmt.Errorf("failed to get VirtualMachine by dcmlabelinstanceid: %w", err)
 	}
-	return c.restClient.Delete().
-		Resource("virtualmachines").
-		Namespace(c.namespace).
-		Name(item.Name).
-		Body(&metav1.DeleteOptions{}).
+	if item.Status.PrintableStatus == kubevirtv1.VirtualMachineStatusRunning {
+		return nil
+	}
+	return c.putSubresource(ctx, vmID, item.Name, "start", &kubevirtv1.StartOptions{})
+}
+
+// StopVirtualMachine asks KubeVirt to stop a VirtualMachine by DCM instance ID.
+// Stopping a stopped VM is a no-op.
+func (c *Client) StopVirtualMachine(ctx context.Context, vmID string) error {
+	item, err := c.GetVirtualMachine(ctx, vmID)
+	if err != nil {
+		return fmt.Errorf("failed to get VirtualMachine by dcmlabelinstanceid: %w", err)
+	}
+	if virtualMachineStatus(item) == statusStopped {
+		return nil
+	}
+	return c.putSubresource(ctx, vmID, item.Name, "stop", &kubevirtv1.StopOptions{})
+}
+
+// RestartVirtualMachine asks KubeVirt to restart a running VirtualMachine by
+// DCM instance ID
+func (c *Client) RestartVirtualMachine(ctx context.Context, vmID string) error {
+	item, err := c.GetVirtualMachine(ctx, vmID)
+	if err != nil {
+		return fmt.Errorf("failed to get VirtualMachine by dcmlabelinstanceid: %w", err)
+	}
+	return c.putSubresource(ctx, vmID, item.Name, "restart", &kubevirtv1.RestartOptions{})
+}
+
+// putSubresource issues a KubeVirt VirtualMachine subresource request such as
+// start or stop, and drops the VM from the lookup coalescing window since its
+// state is about to change. Unlike the other writes it is not retried: a
+// restart whose response was lost would restart the VM a second time, and
+// KubeVirt rejects a repeated start or stop with a conflict.
+func (c *Client) putSubresource(ctx context.Context, vmID, name, subresource string, options interface{}) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s options: %w", subresource, err)
+	}
+	c.forgetVirtualMachine(vmID)
+	return c.restClient.Put().
+		AbsPath("/apis/subresources.kubevirt.io/v1/namespaces", c.namespace, "virtualmachines", name, subresource).
+		Body(body).
 		Do(timeoutCtx).
 		Error()
 }
 
-// UpdateVirtualMachine updates an existing VirtualMachine
-func (c *Client) UpdateVirtualMachine(ctx context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+// CreateSecret creates a Secret in the namespace
+func (c *Client) CreateSecret(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
 	defer cancel()
 
-	result := &kubevirtv1.VirtualMachine{}
-	err := c.restClient.Put().
-		Resource("virtualmachines").
-		Namespace(c.namespace).
-		Name(vm.Name).
-		Body(vm).
-		Do(timeoutCtx).
-		Into(result)
+	result, err := c.coreClient.CoreV1().Secrets(c.namespace).Create(timeoutCtx, secret, metav1.CreateOptions{})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to create Secret: %w", err)
+	}
+	return result, nil
+}
+
+// GetSecret retrieves a Secret by name from the namespace
+func (c *Client) GetSecret(ctx context.Context, name string) (*k8sv1.Secret, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.coreClient.CoreV1().Secrets(c.namespace).Get(timeoutCtx, name, metav1.GetOptions{})
+}
+
+// UpdateSecret updates an existing Secret in the namespace
+func (c *Client) UpdateSecret(ctx context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result, err := c.coreClient.CoreV1().Secrets(c.namespace).Update(timeoutCtx, secret, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update Secret: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteSecret deletes a Secret by name from the namespace
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.coreClient.CoreV1().Secrets(c.namespace).Delete(timeoutCtx, name, metav1.DeleteOptions{})
+}
+
+// DeleteSecretsByInstanceID deletes every DCM-managed Secret labelled with the
+// DCM instance ID. Unlike DeleteSecret it needs neither the VM nor the Secret
+// names, so Secrets left behind by a VM that is already gone are removed too.
+func (c *Client) DeleteSecretsByInstanceID(ctx context.Context, vmID string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	err := c.coreClient.CoreV1().Secrets(c.namespace).DeleteCollection(timeoutCtx, metav1.DeleteOptions{}, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s=%s",
+			constants.DCMLabelManagedBy, constants.DCMManagedByValue, constants.DCMLabelInstanceID, vmID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete Secrets: %w", err)
+	}
+	return nil
+}
+
+// CreateService creates a Service in the namespace
+func (c *Client) CreateService(ctx context.Context, service *k8sv1.Service) (*k8sv1.Service, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result, err := c.coreClient.CoreV1().Services(c.namespace).Create(timeoutCtx, service, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Service: %w", err)
+	}
+	return result, nil
+}
+
+// GetService retrieves a Service by name from the namespace
+func (c *Client) GetService(ctx context.Context, name string) (*k8sv1.Service, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.coreClient.CoreV1().Services(c.namespace).Get(timeoutCtx, name, metav1.GetOptions{})
+}
+
+// ListServices returns the Services of a VM, i.e. those DCM labelled with its
+// instance ID, such as the Service publishing its guest ports
+func (c *Client) ListServices(ctx context.Context, vmID string) ([]k8sv1.Service, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	list, err := c.coreClient.CoreV1().Services(c.namespace).List(timeoutCtx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s,%s=%s",
+			constants.DCMLabelManagedBy, constants.DCMManagedByValue, constants.DCMLabelInstanceID, vmID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Services: %w", err)
+	}
+	return list.Items, nil
+}
+
+// DeleteService deletes a Service by name from the namespace
+func (c *Client) DeleteService(ctx context.Context, name string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	return c.coreClient.CoreV1().Services(c.namespace).Delete(timeoutCtx, name, metav1.DeleteOptions{})
+}
+
+// CreatePersistentVolumeClaim creates a PersistentVolumeClaim in the namespace
+func (c *Client) CreatePersistentVolumeClaim(ctx context.Context, claim *k8sv1.PersistentVolumeClaim) (*k8sv1.PersistentVolumeClaim, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	result, err := c.coreClient.CoreV1().PersistentVolumeClaims(c.namespace).Create(timeoutCtx, claim, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PersistentVolumeClaim: %w", err)
 	}
-	result.SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
 	return result, nil
 }
 
+// NamespaceExists reports whether the configured namespace exists
+func (c *Client) NamespaceExists(ctx context.Context) (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	_, err := c.dynamicClient.Resource(namespaceGVR).Get(timeoutCtx, c.namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// CheckNamespaceAccess verifies that the configured namespace exists and that
+// VirtualMachines can be listed in it. The result is cached for the configured TTL
+// so that frequent readiness probes stay cheap, and concurrent callers share a
+// single probe.
+func (c *Client) CheckNamespaceAccess(ctx context.Context) error {
+	if ok, err := c.cachedNamespaceCheck(); ok {
+		return err
+	}
+
+	// The shared probe must not fail for every waiter when the first caller goes away
+	_, err, _ := c.getGroup.Do(namespaceCheckKey, func() (interface{}, error) {
+		// A probe may have finished since the cache was read
+		if ok, err := c.cachedNamespaceCheck(); ok {
+			return nil, err
+		}
+		err := c.checkNamespaceAccess(context.WithoutCancel(ctx))
+		c.namespaceCheckMu.Lock()
+		c.namespaceCheckErr = err
+		c.namespaceCheckedAt = time.Now()
+		c.namespaceCheckMu.Unlock()
+		return nil, err
+	})
+	return err
+}
+
+// cachedNamespaceCheck returns the last namespace check result while it is fresh
+func (c *Client) cachedNamespaceCheck() (bool, error) {
+	c.namespaceCheckMu.Lock()
+	defer c.namespaceCheckMu.Unlock()
+
+	if c.namespaceCheckedAt.IsZero() || time.Since(c.namespaceCheckedAt) >= c.namespaceCheckTTL {
+		return false, nil
+	}
+	return true, c.namespaceCheckErr
+}
+
+func (c *Client) checkNamespaceAccess(ctx context.Context) error {
+	exists, err := c.NamespaceExists(ctx)
+	switch {
+	case apierrors.IsForbidden(err):
+		// Namespace-scoped service accounts may not read namespaces; rely on the list probe
+	case err != nil:
+		return fmt.Errorf("failed to check namespace %q: %w", c.namespace, err)
+	case !exists:
+		return fmt.Errorf("namespace %q not found", c.namespace)
+	}
+
+	if _, _, err := c.ListVirtualMachines(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		return fmt.Errorf("cannot list VirtualMachines in namespace %q: %w", c.namespace, err)
+	}
+	return nil
+}
+
 // DynamicClient returns the underlying dynamic client
 func (c *Client) DynamicClient() dynamic.Interface {
 	return c.dynamicClient
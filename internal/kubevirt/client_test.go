@@ -1,19 +1,42 @@
 package kubevirt
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/config"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
 )
 
 func newTestClient(handler http.Handler) (*Client, *httptest.Server) {
@@ -40,6 +63,57 @@ func newTestClient(handler http.Handler) (*Client, *httptest.Server) {
 	}, ts
 }
 
+// roundTripperFunc serves REST client requests without a server
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// newRoundTripperClient returns a client whose requests are answered by rt and
+// that retries failed requests up to maxRetries times without delay
+func newRoundTripperClient(rt http.RoundTripper, maxRetries int) *Client {
+	rc, err := rest.RESTClientFor(&rest.Config{
+		Host:      "http://kubevirt.test",
+		APIPath:   "/apis",
+		Transport: rt,
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &schema.GroupVersion{Group: "kubevirt.io", Version: "v1"},
+			NegotiatedSerializer: serializer.WithoutConversionCodecFactory{CodecFactory: kubevirtCodecs},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return &Client{
+		restClient:   rc,
+		namespace:    "default",
+		timeout:      5 * time.Second,
+		maxRetries:   maxRetries,
+		retryBackoff: time.Millisecond,
+	}
+}
+
+func jsonResponse(status int, obj interface{}) *http.Response {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func statusResponse(code int, message string) *http.Response {
+	return jsonResponse(code, &metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   metav1.StatusFailure,
+		Message:  message,
+		Code:     int32(code),
+	})
+}
+
 func writeJSON(w http.ResponseWriter, status int, obj interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -56,6 +130,155 @@ func writeError(w http.ResponseWriter, code int, message string) {
 }
 
 var _ = Describe("Client", func() {
+	Describe("ValidateNamespace", func() {
+		DescribeTable("should reject an invalid namespace",
+			func(namespace string) {
+				Expect(ValidateNamespace(namespace)).To(MatchError(ContainSubstring("namespace")))
+			},
+			Entry("empty", ""),
+			Entry("uppercase", "Team-A"),
+			Entry("region name with a dot", "us-east-1.prod"),
+			Entry("too long", strings.Repeat("a", 64)),
+		)
+
+		It("should accept a valid namespace", func() {
+			Expect(ValidateNamespace("us-east-1")).To(Succeed())
+		})
+	})
+
+	Describe("NewClientWithRetry", func() {
+		It("should return the client created on a later attempt", func() {
+			calls := 0
+			want := &Client{namespace: "default"}
+			c, err := retryNewClient(context.Background(), func() (*Client, error) {
+				calls++
+				if calls < 3 {
+					return nil, errors.New("connection refused")
+				}
+				return want, nil
+			}, 5, time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(BeIdenticalTo(want))
+			Expect(calls).To(Equal(3))
+		})
+
+		It("should give up with the last error after the configured attempts", func() {
+			calls := 0
+			_, err := retryNewClient(context.Background(), func() (*Client, error) {
+				calls++
+				return nil, errors.New("connection refused")
+			}, 3, time.Millisecond)
+			Expect(err).To(MatchError(ContainSubstring("connection refused")))
+			Expect(err).To(MatchError(ContainSubstring("3 attempts")))
+			Expect(calls).To(Equal(3))
+		})
+
+		It("should stop retrying when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			calls := 0
+			_, err := retryNewClient(ctx, func() (*Client, error) {
+				calls++
+				cancel()
+				return nil, errors.New("connection refused")
+			}, 5, time.Hour)
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should retry until the API server answers", func() {
+			var versionCalls atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/version" {
+					writeError(w, http.StatusNotFound, "not found")
+					return
+				}
+				if versionCalls.Add(1) < 3 {
+					writeError(w, http.StatusServiceUnavailable, "apiserver starting")
+					return
+				}
+				writeJSON(w, http.StatusOK, map[string]string{"major": "1", "minor": "33", "gitVersion": "v1.33.0"})
+			}))
+			defer ts.Close()
+
+			kubeconfig := filepath.Join(GinkgoT().TempDir(), "kubeconfig")
+			Expect(os.WriteFile(kubeconfig, []byte(fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+contexts:
+- name: test
+  context:
+    cluster: test
+current-context: test
+`, ts.URL)), 0o600)).To(Succeed())
+
+			factory, err := NewClientFactory(&config.KubernetesConfig{
+				Kubeconfig: kubeconfig,
+				Timeout:    5 * time.Second,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			c, err := NewClientWithRetry(context.Background(), factory, "default", 5, time.Millisecond)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).NotTo(BeNil())
+			Expect(versionCalls.Load()).To(BeEquivalentTo(3))
+		})
+
+		It("should not retry an invalid namespace", func() {
+			factory := newClientFactory(&config.KubernetesConfig{}, nil, &rest.RESTClient{},
+				dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), k8sfake.NewSimpleClientset())
+			_, err := NewClientWithRetry(context.Background(), factory, "Team-A", 5, time.Hour)
+			Expect(err).To(MatchError(ContainSubstring("namespace")))
+		})
+
+		It("should reject an invalid overhead without contacting the cluster", func() {
+			_, err := NewClientFactory(&config.KubernetesConfig{CPUOverhead: "lots"})
+			Expect(err).To(MatchError(ContainSubstring("overhead")))
+		})
+	})
+
+	Describe("ClientFactory", func() {
+		var factory *ClientFactory
+
+		BeforeEach(func() {
+			factory = newClientFactory(
+				&config.KubernetesConfig{Timeout: 5 * time.Second, VMCacheEnabled: true},
+				nil,
+				&rest.RESTClient{},
+				dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()),
+				k8sfake.NewSimpleClientset(),
+			)
+		})
+
+		It("should reuse the client for repeated calls in the same namespace", func() {
+			first, err := factory.Client("team-a")
+			Expect(err).NotTo(HaveOccurred())
+			second, err := factory.Client("team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(BeIdenticalTo(first))
+		})
+
+		It("should share connections between namespaces", func() {
+			a, err := factory.Client("team-a")
+			Expect(err).NotTo(HaveOccurred())
+			b, err := factory.Client("team-b")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(b).NotTo(BeIdenticalTo(a))
+			Expect(a.namespace).To(Equal("team-a"))
+			Expect(b.namespace).To(Equal("team-b"))
+			Expect(b.restClient).To(BeIdenticalTo(a.restClient))
+			Expect(b.coreClient).To(BeIdenticalTo(a.coreClient))
+			Expect(b.vmInformer).NotTo(BeIdenticalTo(a.vmInformer))
+		})
+
+		It("should reject an invalid namespace", func() {
+			_, err := factory.Client("Team-A")
+			Expect(err).To(MatchError(ContainSubstring("namespace")))
+		})
+	})
+
 	Describe("CreateVirtualMachine", func() {
 		It("should return created VM on success", func() {
 			responseVM := &kubevirtv1.VirtualMachine{
@@ -83,6 +306,131 @@ var _ = Describe("Client", func() {
 			_, err := c.CreateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{})
 			Expect(err).To(HaveOccurred())
 		})
+
+		Context("with retries configured", func() {
+			It("should retry server errors until the VM is created", func() {
+				var calls int32
+				c := newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					if atomic.AddInt32(&calls, 1) <= 2 {
+						return statusResponse(http.StatusInternalServerError, "etcd leader changed"), nil
+					}
+					return jsonResponse(http.StatusCreated, &kubevirtv1.VirtualMachine{
+						TypeMeta:   metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachine"},
+						ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"},
+					}), nil
+				}), 3)
+
+				result, err := c.CreateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Name).To(Equal("test-vm"))
+				Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+			})
+
+			It("should give up after maxRetries", func() {
+				var calls int32
+				c := newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					atomic.AddInt32(&calls, 1)
+					return statusResponse(http.StatusServiceUnavailable, "unavailable"), nil
+				}), 2)
+
+				_, err := c.CreateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{})
+				Expect(apierrors.IsServiceUnavailable(err)).To(BeTrue())
+				Expect(atomic.LoadInt32(&calls)).To(Equal(int32(3)))
+			})
+
+			It("should not retry a conflict", func() {
+				var calls int32
+				c := newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					atomic.AddInt32(&calls, 1)
+					return statusResponse(http.StatusConflict, "already exists"), nil
+				}), 3)
+
+				_, err := c.CreateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{})
+				Expect(apierrors.IsConflict(err)).To(BeTrue())
+				Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+			})
+
+			Context("when a retried create finds the VM already exists", func() {
+				vm := &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{
+					Name:   "dcm-vm-1",
+					Labels: map[string]string{constants.DCMLabelInstanceID: "vm-1"},
+				}}
+
+				// newCreateClient fails the first create ambiguously and reports
+				// the VM as existing afterwards, serving it with instanceID
+				newCreateClient := func(instanceID string, calls *int32) *Client {
+					return newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+						if r.Method == http.MethodGet {
+							return jsonResponse(http.StatusOK, &kubevirtv1.VirtualMachine{
+								TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachine"},
+								ObjectMeta: metav1.ObjectMeta{
+									Name:      "dcm-vm-1",
+									Namespace: "default",
+									Labels:    map[string]string{constants.DCMLabelInstanceID: instanceID},
+								},
+							}), nil
+						}
+						if atomic.AddInt32(calls, 1) == 1 {
+							return statusResponse(http.StatusGatewayTimeout, "timeout"), nil
+						}
+						return jsonResponse(http.StatusConflict, &apierrors.NewAlreadyExists(
+							schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachines"}, "dcm-vm-1").ErrStatus), nil
+					}), 3)
+				}
+
+				It("should return the VM when it has the same instance ID", func() {
+					var calls int32
+					c := newCreateClient("vm-1", &calls)
+
+					result, err := c.CreateVirtualMachine(context.Background(), vm.DeepCopy())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.Name).To(Equal("dcm-vm-1"))
+					Expect(atomic.LoadInt32(&calls)).To(Equal(int32(2)))
+				})
+
+				It("should report the conflict when the VM belongs to another instance", func() {
+					var calls int32
+					c := newCreateClient("vm-2", &calls)
+
+					_, err := c.CreateVirtualMachine(context.Background(), vm.DeepCopy())
+					Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+				})
+			})
+
+			It("should report an existing VM on the first attempt as a conflict", func() {
+				var gets int32
+				c := newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					if r.Method == http.MethodGet {
+						atomic.AddInt32(&gets, 1)
+					}
+					return jsonResponse(http.StatusConflict, &apierrors.NewAlreadyExists(
+						schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachines"}, "dcm-vm-1").ErrStatus), nil
+				}), 3)
+
+				_, err := c.CreateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "dcm-vm-1",
+						Labels: map[string]string{constants.DCMLabelInstanceID: "vm-1"},
+					},
+				})
+				Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+				Expect(atomic.LoadInt32(&gets)).To(BeZero())
+			})
+
+			It("should stop retrying once the context is done", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				var calls int32
+				c := newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					atomic.AddInt32(&calls, 1)
+					cancel()
+					return statusResponse(http.StatusInternalServerError, "internal error"), nil
+				}), 5)
+
+				_, err := c.CreateVirtualMachine(ctx, &kubevirtv1.VirtualMachine{})
+				Expect(err).To(HaveOccurred())
+				Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+			})
+		})
 	})
 
 	Describe("GetVirtualMachine", func() {
@@ -117,7 +465,7 @@ var _ = Describe("Client", func() {
 			defer ts.Close()
 
 			_, err := c.GetVirtualMachine(context.Background(), "vm-123")
-			Expect(err).To(HaveOccurred())
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
 			Expect(err.Error()).To(ContainSubstring("not found"))
 		})
 
@@ -130,6 +478,198 @@ var _ = Describe("Client", func() {
 			_, err := c.GetVirtualMachine(context.Background(), "vm-123")
 			Expect(err).To(HaveOccurred())
 		})
+
+		Context("coalescing repeated lookups", func() {
+			var (
+				calls   atomic.Int32
+				release chan struct{}
+				c       *Client
+				ts      *httptest.Server
+			)
+
+			BeforeEach(func() {
+				calls.Store(0)
+				release = make(chan struct{})
+				responseList := &kubevirtv1.VirtualMachineList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+					Items: []kubevirtv1.VirtualMachine{
+						{ObjectMeta: metav1.ObjectMeta{Name: "found-vm", Namespace: "default"}},
+					},
+				}
+				c, ts = newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls.Add(1)
+					<-release
+					writeJSON(w, http.StatusOK, responseList)
+				}))
+			})
+
+			AfterEach(func() {
+				ts.Close()
+			})
+
+			It("should share one cluster fetch between concurrent lookups for the same ID", func() {
+				const lookups = 10
+				var wg sync.WaitGroup
+				results := make(chan *kubevirtv1.VirtualMachine, lookups)
+				for i := 0; i < lookups; i++ {
+					wg.Add(1)
+					go func() {
+						defer GinkgoRecover()
+						defer wg.Done()
+						vm, err := c.GetVirtualMachine(context.Background(), "vm-123")
+						Expect(err).NotTo(HaveOccurred())
+						results <- vm
+					}()
+				}
+
+				Eventually(calls.Load).Should(BeEquivalentTo(1))
+				// Give the remaining lookups time to join the in-flight fetch
+				time.Sleep(50 * time.Millisecond)
+				close(release)
+				wg.Wait()
+				close(results)
+
+				Expect(calls.Load()).To(BeEquivalentTo(1))
+				seen := map[*kubevirtv1.VirtualMachine]bool{}
+				for vm := range results {
+					Expect(vm.Name).To(Equal("found-vm"))
+					Expect(seen[vm]).To(BeFalse(), "each caller must get its own copy")
+					seen[vm] = true
+				}
+			})
+
+			It("should reuse a fetched VM within the TTL and refetch after an update", func() {
+				close(release)
+				c.getCoalesceTTL = time.Minute
+
+				_, err := c.GetVirtualMachine(context.Background(), "vm-123")
+				Expect(err).NotTo(HaveOccurred())
+				_, err = c.GetVirtualMachine(context.Background(), "vm-123")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls.Load()).To(BeEquivalentTo(1))
+
+				c.forgetVirtualMachine("vm-123")
+				_, err = c.GetVirtualMachine(context.Background(), "vm-123")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls.Load()).To(BeEquivalentTo(2))
+			})
+
+			It("should not reuse a VM fetched while it was being changed", func() {
+				c.getCoalesceTTL = time.Minute
+
+				fetched := make(chan error, 1)
+				go func() {
+					_, err := c.GetVirtualMachine(context.Background(), "vm-123")
+					fetched <- err
+				}()
+				Eventually(calls.Load).Should(BeEquivalentTo(1))
+				c.forgetVirtualMachine("vm-123")
+				close(release)
+				Eventually(fetched).Should(Receive(BeNil()))
+
+				_, err := c.GetVirtualMachine(context.Background(), "vm-123")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(calls.Load()).To(BeEquivalentTo(2))
+			})
+
+			It("should drop expired VMs when remembering another", func() {
+				c.getCoalesceTTL = 10 * time.Millisecond
+				c.rememberVirtualMachine("vm-old", &kubevirtv1.VirtualMachine{}, 0)
+				time.Sleep(20 * time.Millisecond)
+
+				c.rememberVirtualMachine("vm-new", &kubevirtv1.VirtualMachine{}, 0)
+
+				Expect(c.recentGets).To(HaveLen(1))
+				Expect(c.recentGets).To(HaveKey("vm-new"))
+			})
+		})
+
+		Context("with the VM cache enabled", func() {
+			var (
+				c      *Client
+				ts     *httptest.Server
+				calls  int
+				cancel context.CancelFunc
+			)
+
+			BeforeEach(func() {
+				cachedVM := &kubevirtv1.VirtualMachine{
+					TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachine"},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            "cached-vm",
+						Namespace:       "default",
+						ResourceVersion: "1",
+						Labels: map[string]string{
+							constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+							constants.DCMLabelInstanceID: "vm-cached",
+						},
+					},
+				}
+				data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cachedVM)
+				Expect(err).NotTo(HaveOccurred())
+
+				calls = 0
+				c, ts = newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					calls++
+					writeJSON(w, http.StatusOK, &kubevirtv1.VirtualMachineList{
+						TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+						Items: []kubevirtv1.VirtualMachine{
+							{ObjectMeta: metav1.ObjectMeta{Name: "live-vm", Namespace: "default"}},
+						},
+					})
+				}))
+				c.dynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+					runtime.NewScheme(),
+					map[schema.GroupVersionResource]string{virtualMachineGVR: "VirtualMachineList"},
+					&unstructured.Unstructured{Object: data},
+				)
+				Expect(c.setupVMCache(0)).To(Succeed())
+
+				var ctx context.Context
+				ctx, cancel = context.WithCancel(context.Background())
+				Expect(c.StartVMCache(ctx)).To(Succeed())
+			})
+
+			AfterEach(func() {
+				cancel()
+				ts.Close()
+			})
+
+			It("should return the VM from the indexer after sync", func() {
+				result, err := c.GetVirtualMachine(context.Background(), "vm-cached")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Name).To(Equal("cached-vm"))
+				Expect(calls).To(Equal(0))
+			})
+
+			It("should fall back to a live list on cache miss", func() {
+				result, err := c.GetVirtualMachine(context.Background(), "vm-unknown")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Name).To(Equal("live-vm"))
+				Expect(calls).To(Equal(1))
+			})
+
+			It("should read live after a change until the cache holds a newer version", func() {
+				c.forgetVirtualMachine("vm-cached")
+
+				result, err := c.GetVirtualMachine(context.Background(), "vm-cached")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Name).To(Equal("live-vm"))
+				Expect(calls).To(Equal(1))
+
+				u, err := c.dynamicClient.Resource(virtualMachineGVR).Namespace("default").Get(context.Background(), "cached-vm", metav1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				u.SetResourceVersion("2")
+				_, err = c.dynamicClient.Resource(virtualMachineGVR).Namespace("default").Update(context.Background(), u, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() string {
+					result, err := c.GetVirtualMachine(context.Background(), "vm-cached")
+					Expect(err).NotTo(HaveOccurred())
+					return result.Name
+				}).Should(Equal("cached-vm"))
+			})
+		})
 	})
 
 	Describe("ListVirtualMachines", func() {
@@ -147,13 +687,35 @@ var _ = Describe("Client", func() {
 			}))
 			defer ts.Close()
 
-			items, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{})
+			items, _, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(items).To(HaveLen(2))
 			Expect(items[0].Name).To(Equal("vm-1"))
 			Expect(items[1].Name).To(Equal("vm-2"))
 		})
 
+		It("should request one page and return the continue token", func() {
+			responseList := &kubevirtv1.VirtualMachineList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				ListMeta: metav1.ListMeta{Continue: "next-page"},
+				Items:    []kubevirtv1.VirtualMachine{{ObjectMeta: metav1.ObjectMeta{Name: "vm-3"}}},
+			}
+
+			var query url.Values
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				writeJSON(w, http.StatusOK, responseList)
+			}))
+			defer ts.Close()
+
+			items, next, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{Limit: 1, Continue: "this-page"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(items).To(HaveLen(1))
+			Expect(next).To(Equal("next-page"))
+			Expect(query.Get("limit")).To(Equal("1"))
+			Expect(query.Get("continue")).To(Equal("this-page"))
+		})
+
 		It("should return empty list", func() {
 			responseList := &kubevirtv1.VirtualMachineList{
 				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
@@ -165,7 +727,7 @@ var _ = Describe("Client", func() {
 			}))
 			defer ts.Close()
 
-			items, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{})
+			items, _, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(items).To(BeEmpty())
 		})
@@ -176,12 +738,14 @@ var _ = Describe("Client", func() {
 			}))
 			defer ts.Close()
 
-			_, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{})
+			_, _, err := c.ListVirtualMachines(context.Background(), metav1.ListOptions{})
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
 	Describe("DeleteVirtualMachine", func() {
+		gracePeriod := func(seconds int64) *int64 { return &seconds }
+
 		It("should delete successfully", func() {
 			vmList := &kubevirtv1.VirtualMachineList{
 				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
@@ -200,19 +764,239 @@ var _ = Describe("Client", func() {
 			}))
 			defer ts.Close()
 
-			err := c.DeleteVirtualMachine(context.Background(), "vm-123")
+			err := c.DeleteVirtualMachine(context.Background(), "vm-123", nil)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		DescribeTable("should send the requested grace period",
+			func(gracePeriodSeconds *int64) {
+				vmList := &kubevirtv1.VirtualMachineList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+					Items: []kubevirtv1.VirtualMachine{
+						{ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"}},
+					},
+				}
+				var options metav1.DeleteOptions
+				c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.Method {
+					case http.MethodGet:
+						writeJSON(w, http.StatusOK, vmList)
+					case http.MethodDelete:
+						Expect(json.NewDecoder(r.Body).Decode(&options)).To(Succeed())
+						w.WriteHeader(http.StatusOK)
+					}
+				}))
+				defer ts.Close()
+
+				Expect(c.DeleteVirtualMachine(context.Background(), "vm-123", gracePeriodSeconds)).To(Succeed())
+				Expect(options.GracePeriodSeconds).To(Equal(gracePeriodSeconds))
+			},
+			Entry("graceful, keeping the grace period of the VM", nil),
+			Entry("graceful, with an override", gracePeriod(30)),
+			Entry("forced", gracePeriod(0)),
+		)
+
+		Context("when a retried delete finds the VM gone", func() {
+			newDeleteClient := func(firstStatus int, deletes *int32) *Client {
+				return newRoundTripperClient(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					if r.Method == http.MethodGet {
+						return jsonResponse(http.StatusOK, &kubevirtv1.VirtualMachineList{
+							TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+							Items: []kubevirtv1.VirtualMachine{
+								{ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"}},
+							},
+						}), nil
+					}
+					if atomic.AddInt32(deletes, 1) == 1 {
+						return statusResponse(firstStatus, "delete failed"), nil
+					}
+					return statusResponse(http.StatusNotFound, "not found"), nil
+				}), 3)
+			}
+
+			It("should report the delete as done after an ambiguous failure", func() {
+				var deletes int32
+				c := newDeleteClient(http.StatusGatewayTimeout, &deletes)
+
+				Expect(c.DeleteVirtualMachine(context.Background(), "vm-123", nil)).To(Succeed())
+				Expect(atomic.LoadInt32(&deletes)).To(Equal(int32(2)))
+			})
+
+			It("should report a VM missing on the first attempt as not found", func() {
+				var deletes int32
+				c := newDeleteClient(http.StatusNotFound, &deletes)
+
+				err := c.DeleteVirtualMachine(context.Background(), "vm-123", nil)
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(atomic.LoadInt32(&deletes)).To(Equal(int32(1)))
+			})
+		})
+
 		It("should return error when get-lookup fails", func() {
 			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				writeError(w, http.StatusInternalServerError, "internal error")
 			}))
 			defer ts.Close()
 
-			err := c.DeleteVirtualMachine(context.Background(), "vm-123")
+			err := c.DeleteVirtualMachine(context.Background(), "vm-123", nil)
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("should return a not-found error when no VM has the instance ID", func() {
+			var deleted bool
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					deleted = true
+				}
+				writeJSON(w, http.StatusOK, &kubevirtv1.VirtualMachineList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				})
+			}))
+			defer ts.Close()
+
+			err := c.DeleteVirtualMachine(context.Background(), "vm-123", nil)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			Expect(deleted).To(BeFalse())
+
+			_, statusCode := classifyKubernetesError(err, "Failed to delete virtual machine")
+			Expect(statusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("StartVirtualMachine", func() {
+		It("should call the start subresource of the VM", func() {
+			vmList := &kubevirtv1.VirtualMachineList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				Items: []kubevirtv1.VirtualMachine{
+					{ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"}},
+				},
+			}
+
+			var startPath string
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					writeJSON(w, http.StatusOK, vmList)
+				case http.MethodPut:
+					startPath = r.URL.Path
+					w.WriteHeader(http.StatusAccepted)
+				}
+			}))
+			defer ts.Close()
+
+			Expect(c.StartVirtualMachine(context.Background(), "vm-123")).To(Succeed())
+			Expect(startPath).To(Equal("/apis/subresources.kubevirt.io/v1/namespaces/default/virtualmachines/test-vm/start"))
+		})
+
+		It("should return the error when the VM cannot be started", func() {
+			vmList := &kubevirtv1.VirtualMachineList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				Items: []kubevirtv1.VirtualMachine{
+					{ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"}},
+				},
+			}
+
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					writeJSON(w, http.StatusOK, vmList)
+					return
+				}
+				writeError(w, http.StatusConflict, "VM is already running")
+			}))
+			defer ts.Close()
+
+			err := c.StartVirtualMachine(context.Background(), "vm-123")
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+		})
+
+		It("should return a not-found error when no VM has the instance ID", func() {
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, &kubevirtv1.VirtualMachineList{
+					TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				})
+			}))
+			defer ts.Close()
+
+			err := c.StartVirtualMachine(context.Background(), "vm-123")
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("StopVirtualMachine", func() {
+		It("should call the stop subresource of a running VM", func() {
+			vmList := &kubevirtv1.VirtualMachineList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				Items: []kubevirtv1.VirtualMachine{{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"},
+					Status:     kubevirtv1.VirtualMachineStatus{PrintableStatus: kubevirtv1.VirtualMachineStatusRunning},
+				}},
+			}
+
+			var stopPath string
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					writeJSON(w, http.StatusOK, vmList)
+				case http.MethodPut:
+					stopPath = r.URL.Path
+					w.WriteHeader(http.StatusAccepted)
+				}
+			}))
+			defer ts.Close()
+
+			Expect(c.StopVirtualMachine(context.Background(), "vm-123")).To(Succeed())
+			Expect(stopPath).To(Equal("/apis/subresources.kubevirt.io/v1/namespaces/default/virtualmachines/test-vm/stop"))
+		})
+
+		It("should not call KubeVirt for a VM that is already stopped", func() {
+			vmList := &kubevirtv1.VirtualMachineList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				Items: []kubevirtv1.VirtualMachine{{
+					ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"},
+					Status:     kubevirtv1.VirtualMachineStatus{PrintableStatus: kubevirtv1.VirtualMachineStatusStopped},
+				}},
+			}
+
+			var puts int
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					writeJSON(w, http.StatusOK, vmList)
+					return
+				}
+				puts++
+				writeError(w, http.StatusConflict, "VM is not running")
+			}))
+			defer ts.Close()
+
+			Expect(c.StopVirtualMachine(context.Background(), "vm-123")).To(Succeed())
+			Expect(puts).To(BeZero())
+		})
+	})
+
+	Describe("RestartVirtualMachine", func() {
+		It("should call the restart subresource of the VM", func() {
+			vmList := &kubevirtv1.VirtualMachineList{
+				TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+				Items: []kubevirtv1.VirtualMachine{
+					{ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "default"}},
+				},
+			}
+
+			var restartPath string
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodGet:
+					writeJSON(w, http.StatusOK, vmList)
+				case http.MethodPut:
+					restartPath = r.URL.Path
+					w.WriteHeader(http.StatusAccepted)
+				}
+			}))
+			defer ts.Close()
+
+			Expect(c.RestartVirtualMachine(context.Background(), "vm-123")).To(Succeed())
+			Expect(restartPath).To(Equal("/apis/subresources.kubevirt.io/v1/namespaces/default/virtualmachines/test-vm/restart"))
+		})
 	})
 
 	Describe("UpdateVirtualMachine", func() {
@@ -235,6 +1019,44 @@ var _ = Describe("Client", func() {
 			Expect(result.Name).To(Equal("updated-vm"))
 		})
 
+		It("should migrate the legacy running field to RunStrategy", func() {
+			var sent kubevirtv1.VirtualMachine
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(json.NewDecoder(r.Body).Decode(&sent)).To(Succeed())
+				writeJSON(w, http.StatusOK, &sent)
+			}))
+			defer ts.Close()
+
+			running := true
+			_, err := c.UpdateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "legacy-vm"},
+				Spec:       kubevirtv1.VirtualMachineSpec{Running: &running},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent.Spec.Running).To(BeNil())
+			Expect(sent.Spec.RunStrategy).NotTo(BeNil())
+			Expect(*sent.Spec.RunStrategy).To(Equal(kubevirtv1.RunStrategyAlways))
+		})
+
+		It("should keep an existing RunStrategy and drop running", func() {
+			var sent kubevirtv1.VirtualMachine
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(json.NewDecoder(r.Body).Decode(&sent)).To(Succeed())
+				writeJSON(w, http.StatusOK, &sent)
+			}))
+			defer ts.Close()
+
+			running := true
+			halted := kubevirtv1.RunStrategyHalted
+			_, err := c.UpdateVirtualMachine(context.Background(), &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "legacy-vm"},
+				Spec:       kubevirtv1.VirtualMachineSpec{Running: &running, RunStrategy: &halted},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sent.Spec.Running).To(BeNil())
+			Expect(*sent.Spec.RunStrategy).To(Equal(kubevirtv1.RunStrategyHalted))
+		})
+
 		It("should return error on API failure", func() {
 			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				writeError(w, http.StatusInternalServerError, "internal error")
@@ -248,10 +1070,544 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("CheckNamespaceAccess", func() {
+		emptyList := &kubevirtv1.VirtualMachineList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "kubevirt.io/v1", Kind: "VirtualMachineList"},
+		}
+		namespaceObject := func(name string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata":   map[string]interface{}{"name": name},
+			}}
+		}
+
+		It("should succeed when the namespace exists and is accessible", func() {
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, emptyList)
+			}))
+			defer ts.Close()
+			c.dynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), namespaceObject("default"))
+
+			exists, err := c.NamespaceExists(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeTrue())
+			Expect(c.CheckNamespaceAccess(context.Background())).To(Succeed())
+		})
+
+		It("should fail when the namespace is missing", func() {
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, emptyList)
+			}))
+			defer ts.Close()
+			c.dynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), namespaceObject("other"))
+
+			exists, err := c.NamespaceExists(context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exists).To(BeFalse())
+
+			err = c.CheckNamespaceAccess(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not found"))
+		})
+
+		It("should fail when VirtualMachines cannot be listed", func() {
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeError(w, http.StatusForbidden, "forbidden")
+			}))
+			defer ts.Close()
+			c.dynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), namespaceObject("default"))
+
+			err := c.CheckNamespaceAccess(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot list VirtualMachines"))
+		})
+
+		It("should cache the result for the configured TTL", func() {
+			calls := 0
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				writeJSON(w, http.StatusOK, emptyList)
+			}))
+			defer ts.Close()
+			c.dynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), namespaceObject("default"))
+			c.namespaceCheckTTL = time.Minute
+
+			Expect(c.CheckNamespaceAccess(context.Background())).To(Succeed())
+			Expect(c.CheckNamespaceAccess(context.Background())).To(Succeed())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should share one probe between concurrent callers", func() {
+			var calls int32
+			release := make(chan struct{})
+			c, ts := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				<-release
+				writeJSON(w, http.StatusOK, emptyList)
+			}))
+			defer ts.Close()
+			c.dynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), namespaceObject("default"))
+			c.namespaceCheckTTL = time.Minute
+
+			var wg sync.WaitGroup
+			errs := make(chan error, 5)
+			for range 5 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- c.CheckNamespaceAccess(context.Background())
+				}()
+			}
+			Eventually(func() int32 { return atomic.LoadInt32(&calls) }).Should(Equal(int32(1)))
+			close(release)
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(atomic.LoadInt32(&calls)).To(Equal(int32(1)))
+		})
+	})
+
 	Describe("DynamicClient", func() {
 		It("should return the dynamic client", func() {
 			c := &Client{}
 			Expect(c.DynamicClient()).To(BeNil())
 		})
 	})
+	Describe("ResolveNodePool", func() {
+		kvm := k8sv1.ResourceName("devices.kubevirt.io/kvm")
+
+		newNode := func(name string, nodeLabels map[string]string, kvmCount string) *k8sv1.Node {
+			node := &k8sv1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: nodeLabels}}
+			if kvmCount != "" {
+				node.Status.Allocatable = k8sv1.ResourceList{kvm: resource.MustParse(kvmCount)}
+			}
+			return node
+		}
+		newVM := func(runtimeClass string, selector map[string]string, requests k8sv1.ResourceList) *kubevirtv1.VirtualMachine {
+			vm := &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "dcm-test"},
+				Spec: kubevirtv1.VirtualMachineSpec{
+					Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+						Spec: kubevirtv1.VirtualMachineInstanceSpec{
+							NodeSelector: selector,
+							Domain: kubevirtv1.DomainSpec{
+								Resources: kubevirtv1.ResourceRequirements{Requests: requests},
+							},
+						},
+					},
+				},
+			}
+			if runtimeClass != "" {
+				vm.Annotations = map[string]string{constants.DCMAnnotationRuntimeClass: runtimeClass}
+			}
+			return vm
+		}
+
+		It("should apply runtime class scheduling and accept a matching node", func() {
+			rc := &nodev1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "vm-pool"},
+				Handler:    "kata",
+				Scheduling: &nodev1.Scheduling{
+					NodeSelector: map[string]string{"pool": "vms"},
+					Tolerations:  []k8sv1.Toleration{{Key: "dedicated", Value: "vms", Effect: k8sv1.TaintEffectNoSchedule}},
+				},
+			}
+			c := &Client{coreClient: k8sfake.NewSimpleClientset(rc,
+				newNode("general", map[string]string{"pool": "general"}, "110"),
+				newNode("vm-node", map[string]string{"pool": "vms"}, "110"),
+			)}
+			vm := newVM("vm-pool", nil, k8sv1.ResourceList{kvm: resource.MustParse("1")})
+
+			Expect(c.ResolveNodePool(context.Background(), vm)).To(Succeed())
+			Expect(vm.Spec.Template.Spec.NodeSelector).To(HaveKeyWithValue("pool", "vms"))
+			Expect(vm.Spec.Template.Spec.Tolerations).To(HaveLen(1))
+		})
+
+		It("should fail when the runtime class does not exist", func() {
+			c := &Client{coreClient: k8sfake.NewSimpleClientset()}
+
+			err := c.ResolveNodePool(context.Background(), newVM("missing", nil, nil))
+			Expect(err).To(MatchError(ErrNodePoolUnavailable))
+		})
+
+		It("should fail when no matching node has the extended resource", func() {
+			c := &Client{coreClient: k8sfake.NewSimpleClientset(
+				newNode("vm-node", map[string]string{"pool": "vms"}, ""),
+				newNode("kvm-node", map[string]string{"pool": "general"}, "110"),
+			)}
+			vm := newVM("", map[string]string{"pool": "vms"}, k8sv1.ResourceList{kvm: resource.MustParse("1")})
+
+			err := c.ResolveNodePool(context.Background(), vm)
+			Expect(err).To(MatchError(ErrNodePoolUnavailable))
+		})
+
+		It("should skip the capability check when nodes cannot be listed", func() {
+			cs := k8sfake.NewSimpleClientset()
+			cs.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", nil)
+			})
+			c := &Client{coreClient: cs}
+			vm := newVM("", map[string]string{"pool": "vms"}, nil)
+
+			Expect(c.ResolveNodePool(context.Background(), vm)).To(Succeed())
+		})
+
+		It("should require a node advertising enough of each vGPU resource", func() {
+			vgpu := k8sv1.ResourceName("nvidia.com/GRID_T4-1Q")
+			node := &k8sv1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "gpu-node"},
+				Status:     k8sv1.NodeStatus{Allocatable: k8sv1.ResourceList{vgpu: resource.MustParse("1")}},
+			}
+			c := &Client{coreClient: k8sfake.NewSimpleClientset(node)}
+
+			vm := newVM("", nil, nil)
+			vm.Spec.Template.Spec.Domain.Devices.GPUs = []kubevirtv1.GPU{{Name: "gpu0", DeviceName: string(vgpu)}}
+			Expect(c.ResolveNodePool(context.Background(), vm)).To(Succeed())
+
+			vm.Spec.Template.Spec.Domain.Devices.GPUs = append(vm.Spec.Template.Spec.Domain.Devices.GPUs,
+				kubevirtv1.GPU{Name: "gpu1", DeviceName: string(vgpu)})
+			Expect(c.ResolveNodePool(context.Background(), vm)).To(MatchError(ErrNodePoolUnavailable))
+		})
+
+		It("should do nothing without scheduling constraints", func() {
+			c := &Client{}
+			Expect(c.ResolveNodePool(context.Background(), newVM("", nil, nil))).To(Succeed())
+		})
+	})
+	Describe("CheckCapacity", func() {
+		newVM := func(cpu, memory string) *kubevirtv1.VirtualMachine {
+			return &kubevirtv1.VirtualMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "dcm-test"},
+				Spec: kubevirtv1.VirtualMachineSpec{
+					Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
+						Spec: kubevirtv1.VirtualMachineInstanceSpec{
+							Domain: kubevirtv1.DomainSpec{
+								Resources: kubevirtv1.ResourceRequirements{Requests: k8sv1.ResourceList{
+									k8sv1.ResourceCPU:    resource.MustParse(cpu),
+									k8sv1.ResourceMemory: resource.MustParse(memory),
+								}},
+							},
+						},
+					},
+				},
+			}
+		}
+		newQuota := func(hardMemory, usedMemory string) *k8sv1.ResourceQuota {
+			return &k8sv1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "vms"},
+				Status: k8sv1.ResourceQuotaStatus{
+					Hard: k8sv1.ResourceList{"requests.memory": resource.MustParse(hardMemory)},
+					Used: k8sv1.ResourceList{"requests.memory": resource.MustParse(usedMemory)},
+				},
+			}
+		}
+		newNode := func(cpu, memory string) *k8sv1.Node {
+			return &k8sv1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-" + cpu},
+				Status: k8sv1.NodeStatus{Allocatable: k8sv1.ResourceList{
+					k8sv1.ResourceCPU:    resource.MustParse(cpu),
+					k8sv1.ResourceMemory: resource.MustParse(memory),
+				}},
+			}
+		}
+		overhead := func(cpu, memory string) k8sv1.ResourceList {
+			list, err := ParseOverhead(cpu, memory)
+			Expect(err).NotTo(HaveOccurred())
+			return list
+		}
+
+		It("should include the memory overhead in the quota fit", func() {
+			cs := k8sfake.NewSimpleClientset(newQuota("4Gi", "2Gi"))
+			vm := newVM("1", "2Gi")
+
+			c := &Client{coreClient: cs, namespace: "vms"}
+			Expect(c.CheckCapacity(context.Background(), vm)).To(Succeed())
+
+			c.overhead = overhead("", "256Mi")
+			err := c.CheckCapacity(context.Background(), vm)
+			Expect(err).To(MatchError(ErrInsufficientCapacity))
+			Expect(err.Error()).To(ContainSubstring("requests.memory needs 2304Mi but only 2Gi remains"))
+		})
+
+		It("should include the CPU overhead in the node fit", func() {
+			cs := k8sfake.NewSimpleClientset(newNode("2", "8Gi"))
+			vm := newVM("2", "4Gi")
+
+			c := &Client{coreClient: cs, namespace: "vms"}
+			Expect(c.CheckCapacity(context.Background(), vm)).To(Succeed())
+
+			c.overhead = overhead("100m", "")
+			Expect(c.CheckCapacity(context.Background(), vm)).To(MatchError(ErrInsufficientCapacity))
+		})
+
+		It("should accept a VM when one node fits it with its overhead", func() {
+			cs := k8sfake.NewSimpleClientset(newNode("2", "8Gi"), newNode("4", "8Gi"))
+			c := &Client{coreClient: cs, namespace: "vms", overhead: overhead("100m", "256Mi")}
+
+			Expect(c.CheckCapacity(context.Background(), newVM("2", "4Gi"))).To(Succeed())
+		})
+
+		It("should skip checks whose objects cannot be listed", func() {
+			cs := k8sfake.NewSimpleClientset()
+			forbidden := func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{}, "", nil)
+			}
+			cs.PrependReactor("list", "resourcequotas", forbidden)
+			cs.PrependReactor("list", "nodes", forbidden)
+			c := &Client{coreClient: cs, namespace: "vms", overhead: overhead("100m", "256Mi")}
+
+			Expect(c.CheckCapacity(context.Background(), newVM("64", "1Ti"))).To(Succeed())
+		})
+
+		It("should reject invalid or negative overheads", func() {
+			_, err := ParseOverhead("lots", "")
+			Expect(err).To(HaveOccurred())
+			_, err = ParseOverhead("", "-1Gi")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	Describe("DeleteSecretsByInstanceID", func() {
+		It("should delete the managed secrets selected by the instance ID label", func() {
+			cs := k8sfake.NewSimpleClientset()
+			c := &Client{coreClient: cs, namespace: "vms", timeout: time.Second}
+
+			Expect(c.DeleteSecretsByInstanceID(context.Background(), "vm-1")).To(Succeed())
+
+			Expect(cs.Actions()).To(HaveLen(1))
+			action, ok := cs.Actions()[0].(k8stesting.DeleteCollectionAction)
+			Expect(ok).To(BeTrue())
+			Expect(action.GetNamespace()).To(Equal("vms"))
+			Expect(action.GetResource().Resource).To(Equal("secrets"))
+			Expect(action.GetListRestrictions().Labels.String()).To(Equal(
+				constants.DCMLabelInstanceID + "=vm-1," + constants.DCMLabelManagedBy + "=" + constants.DCMManagedByValue))
+		})
+
+		It("should wrap API errors", func() {
+			cs := k8sfake.NewSimpleClientset()
+			cs.PrependReactor("delete-collection", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", nil)
+			})
+			c := &Client{coreClient: cs, namespace: "vms", timeout: time.Second}
+
+			err := c.DeleteSecretsByInstanceID(context.Background(), "vm-1")
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+	})
+	Describe("GetDiskImports", func() {
+		It("should report pending and failing imports of the VM's data volumes", func() {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{dataVolumeGVR: "DataVolumeList"})
+			for name, status := range map[string]map[string]interface{}{
+				"dcm-vm-boot": {"phase": "ImportInProgress", "progress": "45.00%"},
+				"dcm-vm-data": {"phase": "ImportInProgress", "conditions": []interface{}{map[string]interface{}{
+					"type": "Running", "status": "False", "reason": "ImagePullFailed", "message": "Unable to pull image",
+				}}},
+				"dcm-vm-done": {"phase": "Succeeded", "progress": "100.0%"},
+			} {
+				dv := &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "cdi.kubevirt.io/v1beta1",
+					"kind":       "DataVolume",
+					"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+					"status":     status,
+				}}
+				_, err := dyn.Resource(dataVolumeGVR).Namespace("default").Create(context.Background(), dv, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			c := &Client{dynamicClient: dyn, namespace: "default", timeout: 5 * time.Second}
+
+			vm := &kubevirtv1.VirtualMachine{Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Volumes: []kubevirtv1.Volume{
+						{Name: "boot", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "dcm-vm-boot"}}},
+						{Name: "data", VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: "dcm-vm-data"}}},
+					},
+				}},
+			}}
+			for _, name := range []string{"dcm-vm-boot", "dcm-vm-data", "dcm-vm-done", "dcm-vm-missing"} {
+				vm.Spec.DataVolumeTemplates = append(vm.Spec.DataVolumeTemplates,
+					kubevirtv1.DataVolumeTemplateSpec{ObjectMeta: metav1.ObjectMeta{Name: name}})
+			}
+
+			imports, err := c.GetDiskImports(context.Background(), vm)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(imports).To(Equal([]DiskImport{
+				{Disk: "boot", Phase: "ImportInProgress", Progress: "45.00%"},
+				{Disk: "data", Phase: "ImportInProgress", Error: "Unable to pull image"},
+			}))
+
+			reason, message := DiskImportStatus(imports)
+			Expect(reason).To(Equal(DiskImportError))
+			Expect(message).To(Equal("disk data: Unable to pull image"))
+
+			reason, message = DiskImportStatus(imports[:1])
+			Expect(reason).To(Equal(DiskImportInProgress))
+			Expect(message).To(Equal("Importing disk boot: 45.00%"))
+		})
+
+		It("should not look up anything for a VM without data volume templates", func() {
+			Expect((&Client{}).GetDiskImports(context.Background(), &kubevirtv1.VirtualMachine{})).To(BeEmpty())
+		})
+	})
+
+	Describe("CheckDataVolumeSources", func() {
+		newDataVolumeClient := func(names ...string) *Client {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{dataVolumeGVR: "DataVolumeList"})
+			for _, name := range names {
+				dv := &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "cdi.kubevirt.io/v1beta1",
+					"kind":       "DataVolume",
+					"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+				}}
+				_, err := dyn.Resource(dataVolumeGVR).Namespace("default").Create(context.Background(), dv, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			return &Client{dynamicClient: dyn, namespace: "default", timeout: 5 * time.Second}
+		}
+		attaching := func(name string) *kubevirtv1.VirtualMachine {
+			return &kubevirtv1.VirtualMachine{Spec: kubevirtv1.VirtualMachineSpec{
+				Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					Volumes: []kubevirtv1.Volume{{
+						Name:         "boot",
+						VolumeSource: kubevirtv1.VolumeSource{DataVolume: &kubevirtv1.DataVolumeSource{Name: name}},
+					}},
+				}},
+			}}
+		}
+
+		It("should accept a VM attaching an existing data volume", func() {
+			c := newDataVolumeClient("fedora-golden")
+			Expect(c.CheckDataVolumeSources(context.Background(), attaching("fedora-golden"))).To(Succeed())
+		})
+
+		It("should report a missing data volume", func() {
+			c := newDataVolumeClient("fedora-golden")
+			err := c.CheckDataVolumeSources(context.Background(), attaching("ubuntu-golden"))
+			Expect(err).To(MatchError(ErrDataVolumeNotFound))
+			Expect(err).To(MatchError(ContainSubstring("ubuntu-golden")))
+		})
+
+		It("should require a shareable disk to attach a ReadWriteMany block volume", func() {
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{dataVolumeGVR: "DataVolumeList"})
+			for name, volumeMode := range map[string]string{"quorum-block": "Block", "quorum-fs": "Filesystem"} {
+				dv := &unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "cdi.kubevirt.io/v1beta1",
+					"kind":       "DataVolume",
+					"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+					"spec": map[string]interface{}{"storage": map[string]interface{}{
+						"accessModes": []interface{}{"ReadWriteMany"},
+						"volumeMode":  volumeMode,
+					}},
+				}}
+				_, err := dyn.Resource(dataVolumeGVR).Namespace("default").Create(context.Background(), dv, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			c := &Client{dynamicClient: dyn, namespace: "default", timeout: 5 * time.Second}
+			sharing := func(name string) *kubevirtv1.VirtualMachine {
+				vm := attaching(name)
+				shareable := true
+				vm.Spec.Template.Spec.Domain.Devices.Disks = []kubevirtv1.Disk{{Name: "boot", Shareable: &shareable}}
+				return vm
+			}
+
+			Expect(c.CheckDataVolumeSources(context.Background(), sharing("quorum-block"))).To(Succeed())
+			Expect(c.CheckDataVolumeSources(context.Background(), attaching("quorum-fs"))).To(Succeed())
+			Expect(c.CheckDataVolumeSources(context.Background(), sharing("quorum-fs"))).To(MatchError(ErrDataVolumeNotShareable))
+		})
+
+		It("should not look up data volumes created from templates", func() {
+			vm := attaching("dcm-vm-boot")
+			vm.Spec.DataVolumeTemplates = []kubevirtv1.DataVolumeTemplateSpec{{ObjectMeta: metav1.ObjectMeta{Name: "dcm-vm-boot"}}}
+			Expect((&Client{}).CheckDataVolumeSources(context.Background(), vm)).To(Succeed())
+		})
+	})
+
+	Describe("GetVirtualMachineUsage", func() {
+		const vmID = "00000000-0000-0000-0000-000000000060"
+
+		launcherPod := func(name string, phase k8sv1.PodPhase) *k8sv1.Pod {
+			return &k8sv1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels: map[string]string{
+						"kubevirt.io":                "virt-launcher",
+						constants.DCMLabelInstanceID: vmID,
+					},
+				},
+				Status: k8sv1.PodStatus{Phase: phase},
+			}
+		}
+		podMetrics := func(name string) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "metrics.k8s.io/v1beta1",
+				"kind":       "PodMetrics",
+				"metadata":   map[string]interface{}{"name": name, "namespace": "default"},
+				"timestamp":  "2026-10-15T12:00:00Z",
+				"window":     "30s",
+				"containers": []interface{}{
+					map[string]interface{}{"name": "compute", "usage": map[string]interface{}{"cpu": "200m", "memory": "900Mi"}},
+					map[string]interface{}{"name": "guest-console-log", "usage": map[string]interface{}{"cpu": "50m", "memory": "100Mi"}},
+				},
+			}}
+		}
+		newUsageClient := func(withMetricsAPI bool, objects ...*unstructured.Unstructured) (*Client, *k8sfake.Clientset) {
+			cs := k8sfake.NewSimpleClientset()
+			if withMetricsAPI {
+				cs.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+					{GroupVersion: "metrics.k8s.io/v1beta1"},
+				}
+			}
+			dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{podMetricsGVR: "PodMetricsList"})
+			for _, obj := range objects {
+				_, err := dyn.Resource(podMetricsGVR).Namespace("default").Create(context.Background(), obj, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			return &Client{coreClient: cs, dynamicClient: dyn, namespace: "default", timeout: 5 * time.Second}, cs
+		}
+
+		It("should sum the container usage of the running launcher pod", func() {
+			c, cs := newUsageClient(true, podMetrics("virt-launcher-abc"))
+			_, err := cs.CoreV1().Pods("default").Create(context.Background(), launcherPod("virt-launcher-old", k8sv1.PodFailed), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = cs.CoreV1().Pods("default").Create(context.Background(), launcherPod("virt-launcher-abc", k8sv1.PodRunning), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			usage, err := c.GetVirtualMachineUsage(context.Background(), vmID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(usage.CPU.String()).To(Equal("250m"))
+			Expect(usage.Memory.String()).To(Equal("1000Mi"))
+			Expect(usage.Window).To(Equal(30 * time.Second))
+			Expect(usage.Timestamp).To(Equal(time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)))
+		})
+
+		It("should report the metrics API as unavailable when it is not served", func() {
+			c, _ := newUsageClient(false)
+
+			_, err := c.GetVirtualMachineUsage(context.Background(), vmID)
+			Expect(err).To(MatchError(ErrMetricsUnavailable))
+		})
+
+		It("should return NotFound when the VM has no running launcher pod", func() {
+			c, _ := newUsageClient(true)
+
+			_, err := c.GetVirtualMachineUsage(context.Background(), vmID)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report metrics as unavailable when the pod has no sample yet", func() {
+			c, cs := newUsageClient(true)
+			_, err := cs.CoreV1().Pods("default").Create(context.Background(), launcherPod("virt-launcher-abc", k8sv1.PodRunning), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = c.GetVirtualMachineUsage(context.Background(), vmID)
+			Expect(err).To(MatchError(ErrMetricsUnavailable))
+		})
+	})
 })
@@ -0,0 +1,249 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+const (
+	// cloudInitVolumeName is the name of the NoCloud volume and disk carrying user data
+	cloudInitVolumeName = "cloudinitdisk"
+
+	// cloudInitSecretUserDataKey is the Secret key KubeVirt reads NoCloud user data from
+	cloudInitSecretUserDataKey = "userdata"
+
+	cloudConfigHeader = "#cloud-config\n"
+)
+
+// cloudInitSecretName returns the name of the Secret holding a VM's cloud-init user data
+func cloudInitSecretName(vmID string) string {
+	return virtualMachineName(vmID) + "-cloudinit"
+}
+
+// hostnameHint overrides the guest hostname, which otherwise comes from the metadata name
+const hostnameHint = "hostname"
+
+// guestHostname returns the hostname requested for the guest: the hostname
+// provider hint when set, falling back to the metadata name.
+func guestHostname(vmSpec *types.VMSpec) (string, error) {
+	hostname, err := hintedHostname(vmSpec)
+	if err != nil {
+		return "", err
+	}
+	if hostname == "" {
+		return vmSpec.Metadata.Name, nil
+	}
+	return hostname, nil
+}
+
+// hintedHostname returns the hostname set by the hostname provider hint, or an
+// empty string when the hint is not set
+func hintedHostname(vmSpec *types.VMSpec) (string, error) {
+	var hostname string
+	if _, err := decodeHint(vmSpec, hostnameHint, &hostname); err != nil {
+		return "", err
+	}
+	if hostname == "" {
+		return "", nil
+	}
+	if errs := validation.IsDNS1123Label(hostname); len(errs) > 0 {
+		return "", fmt.Errorf("invalid hostname %q: %s", hostname, strings.Join(errs, "; "))
+	}
+	return hostname, nil
+}
+
+// cloudConfigFromVMSpec builds the cloud-config document for a VMSpec. It returns
+// nil when there is nothing to configure.
+func cloudConfigFromVMSpec(vmSpec *types.VMSpec) (map[string]interface{}, error) {
+	cloudConfig := map[string]interface{}{}
+	hostname, err := guestHostname(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	if hostname != "" {
+		cloudConfig["hostname"] = hostname
+	}
+	if len(cloudConfig) == 0 {
+		return nil, nil
+	}
+	return cloudConfig, nil
+}
+
+// ParseCloudInitBase parses a base cloud-config document, e.g. organization
+// defaults such as NTP servers or CA certificates, that is merged into the
+// cloud-init of every VM. An empty document yields no base.
+func ParseCloudInitBase(data []byte) (map[string]interface{}, error) {
+	var base map[string]interface{}
+	if err := yaml.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("invalid cloud-config: %w", err)
+	}
+	if len(base) == 0 {
+		return nil, nil
+	}
+	return base, nil
+}
+
+// cloudConfig builds the cloud-config document of a VM by merging the
+// request-derived document over the configured base. It returns nil unless a
+// base is configured, the hostname hint is set or an SSH key is injected
+// through NoCloud, so that only VMs needing it get a cloud-init disk.
+func (m *Mapper) cloudConfig(vmSpec *types.VMSpec, vmID string) (map[string]interface{}, error) {
+	hostname, err := hintedHostname(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	if hostname == "" && m.cloudInitBase == nil {
+		accessCredentials, err := m.buildAccessCredentials(vmSpec, vmID)
+		if err != nil {
+			return nil, err
+		}
+		// NoCloud key propagation needs a cloud-init volume to inject the keys into
+		if !hasNoCloudPropagation(accessCredentials) {
+			return nil, nil
+		}
+	}
+
+	cloudConfig, err := cloudConfigFromVMSpec(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	if m.cloudInitBase != nil {
+		cloudConfig = mergeCloudConfig(m.cloudInitBase, cloudConfig)
+	}
+	if cloudConfig == nil {
+		cloudConfig = map[string]interface{}{}
+	}
+	return cloudConfig, nil
+}
+
+// mergeCloudConfig deep-merges overlay into a copy of base: maps are merged key
+// by key, lists are concatenated with the base entries first, and any other
+// overlay value replaces the base one.
+func mergeCloudConfig(base, overlay map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = mergeCloudConfigValue(nil, v)
+	}
+	for k, v := range overlay {
+		merged[k] = mergeCloudConfigValue(merged[k], v)
+	}
+	return merged
+}
+
+// mergeCloudConfigValue merges a single overlay value into a base value, which
+// is nil when the base does not set it
+func mergeCloudConfigValue(base, overlay interface{}) interface{} {
+	switch o := overlay.(type) {
+	case map[string]interface{}:
+		b, _ := base.(map[string]interface{})
+		return mergeCloudConfig(b, o)
+	case []interface{}:
+		b, _ := base.([]interface{})
+		merged := make([]interface{}, 0, len(b)+len(o))
+		merged = append(merged, b...)
+		for _, v := range o {
+			merged = append(merged, mergeCloudConfigValue(nil, v))
+		}
+		return merged
+	default:
+		return overlay
+	}
+}
+
+// generateCloudInitUserData renders a cloud-config document as NoCloud user data
+func generateCloudInitUserData(cloudConfig map[string]interface{}) (string, error) {
+	data, err := yaml.Marshal(cloudConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cloud-config: %w", err)
+	}
+	return cloudConfigHeader + string(data), nil
+}
+
+// buildCloudInitVolume creates the NoCloud volume for the given user data. When the
+// mapper stores user data in a Secret, the volume only references the Secret.
+func (m *Mapper) buildCloudInitVolume(userData, vmID string) kubevirtv1.Volume {
+	source := &kubevirtv1.CloudInitNoCloudSource{}
+	if m.cloudInitFromSecret {
+		source.UserDataSecretRef = &k8sv1.LocalObjectReference{Name: cloudInitSecretName(vmID)}
+	} else {
+		source.UserData = userData
+	}
+	return kubevirtv1.Volume{
+		Name: cloudInitVolumeName,
+		VolumeSource: kubevirtv1.VolumeSource{
+			CloudInitNoCloud: source,
+		},
+	}
+}
+
+// CloudInitSecret returns the Secret that carries a VM's cloud-init user data, or
+// nil when user data is inlined in the VM spec or there is none. The caller is
+// expected to create it alongside the VM and owner-reference it to the VM.
+func (m *Mapper) CloudInitSecret(vmSpec *types.VMSpec, vmID string) (*k8sv1.Secret, error) {
+	if !m.cloudInitFromSecret {
+		return nil, nil
+	}
+	cloudConfig, err := m.cloudConfig(vmSpec, vmID)
+	if err != nil || cloudConfig == nil {
+		return nil, err
+	}
+	userData, err := generateCloudInitUserData(cloudConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &k8sv1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cloudInitSecretName(vmID),
+			Namespace: m.namespace,
+			Labels: map[string]string{
+				constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+				constants.DCMLabelInstanceID: vmID,
+				constants.DCMLabelSecretType: constants.DCMSecretTypeCloudInit,
+			},
+		},
+		Type: k8sv1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			cloudInitSecretUserDataKey: []byte(userData),
+		},
+	}, nil
+}
+
+// OwnerReference returns a controller owner reference to the given VirtualMachine,
+// so that dependent resources are garbage collected with it.
+func OwnerReference(vm *kubevirtv1.VirtualMachine) metav1.OwnerReference {
+	controller := true
+	return metav1.OwnerReference{
+		APIVersion: kubevirtv1.VirtualMachineGroupVersionKind.GroupVersion().String(),
+		Kind:       kubevirtv1.VirtualMachineGroupVersionKind.Kind,
+		Name:       vm.Name,
+		UID:        vm.UID,
+		Controller: &controller,
+	}
+}
+
+// SetOwnerReference makes the VirtualMachine the controller owner of an object.
+// References to an earlier VM of the same name are replaced, so that
+// re-applying never adds a duplicate reference or a second controller.
+func SetOwnerReference(object *metav1.ObjectMeta, vm *kubevirtv1.VirtualMachine) {
+	references := make([]metav1.OwnerReference, 0, len(object.OwnerReferences)+1)
+	for _, reference := range object.OwnerReferences {
+		if reference.Kind == kubevirtv1.VirtualMachineGroupVersionKind.Kind && reference.Name == vm.Name {
+			continue
+		}
+		references = append(references, reference)
+	}
+	object.OwnerReferences = append(references, OwnerReference(vm))
+}
@@ -0,0 +1,125 @@
+package kubevirt
+
+import (
+	"time"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// readYourWritesWindow bounds how long the informer cache is skipped for a VM
+// this client changed, in case the change never produces a newer version
+const readYourWritesWindow = time.Minute
+
+// recentGet is a VirtualMachine fetched from the cluster shortly before
+type recentGet struct {
+	vm        *kubevirtv1.VirtualMachine
+	fetchedAt time.Time
+}
+
+// recentWrite is the version the informer cache held of a VirtualMachine when
+// this client changed it
+type recentWrite struct {
+	resourceVersion string
+	writtenAt       time.Time
+}
+
+// getRecentVirtualMachine returns a copy of a VM fetched within the coalescing
+// TTL, or nil.
+func (c *Client) getRecentVirtualMachine(vmID string) *kubevirtv1.VirtualMachine {
+	if c.getCoalesceTTL <= 0 {
+		return nil
+	}
+	c.recentGetsMu.Lock()
+	defer c.recentGetsMu.Unlock()
+
+	recent, ok := c.recentGets[vmID]
+	if !ok {
+		return nil
+	}
+	if time.Since(recent.fetchedAt) >= c.getCoalesceTTL {
+		delete(c.recentGets, vmID)
+		return nil
+	}
+	return recent.vm.DeepCopy()
+}
+
+// currentWriteEpoch returns the number of VM changes made by this client so
+// far, to be passed to rememberVirtualMachine once a fetch completes
+func (c *Client) currentWriteEpoch() uint64 {
+	c.recentGetsMu.Lock()
+	defer c.recentGetsMu.Unlock()
+	return c.writeEpoch
+}
+
+// rememberVirtualMachine records a fetched VM for the coalescing TTL, dropping
+// the VMs fetched before that. A VM is not recorded when this client changed
+// any VM since the fetch began at epoch, as it may predate the change.
+func (c *Client) rememberVirtualMachine(vmID string, vm *kubevirtv1.VirtualMachine, epoch uint64) {
+	if c.getCoalesceTTL <= 0 {
+		return
+	}
+	c.recentGetsMu.Lock()
+	defer c.recentGetsMu.Unlock()
+
+	now := time.Now()
+	for id, recent := range c.recentGets {
+		if now.Sub(recent.fetchedAt) >= c.getCoalesceTTL {
+			delete(c.recentGets, id)
+		}
+	}
+	if epoch != c.writeEpoch {
+		return
+	}
+	if c.recentGets == nil {
+		c.recentGets = map[string]recentGet{}
+	}
+	c.recentGets[vmID] = recentGet{vm: vm.DeepCopy(), fetchedAt: now}
+}
+
+// forgetVirtualMachine drops a recently fetched VM before it is changed, and
+// skips its informer cache entry until the cache holds a newer version
+func (c *Client) forgetVirtualMachine(vmID string) {
+	c.recentGetsMu.Lock()
+	delete(c.recentGets, vmID)
+	c.writeEpoch++
+	c.recentGetsMu.Unlock()
+
+	if c.vmInformer == nil {
+		return
+	}
+	var resourceVersion string
+	if u := c.cachedObject(vmID); u != nil {
+		resourceVersion = u.GetResourceVersion()
+	}
+
+	c.recentWritesMu.Lock()
+	defer c.recentWritesMu.Unlock()
+
+	now := time.Now()
+	for id, write := range c.recentWrites {
+		if now.Sub(write.writtenAt) >= readYourWritesWindow {
+			delete(c.recentWrites, id)
+		}
+	}
+	if c.recentWrites == nil {
+		c.recentWrites = map[string]recentWrite{}
+	}
+	c.recentWrites[vmID] = recentWrite{resourceVersion: resourceVersion, writtenAt: now}
+}
+
+// cacheBehindWrite reports whether the informer cache still holds the version
+// of a VM from before this client changed it
+func (c *Client) cacheBehindWrite(vmID, resourceVersion string) bool {
+	c.recentWritesMu.Lock()
+	defer c.recentWritesMu.Unlock()
+
+	write, ok := c.recentWrites[vmID]
+	if !ok {
+		return false
+	}
+	if resourceVersion != write.resourceVersion || time.Since(write.writtenAt) >= readYourWritesWindow {
+		delete(c.recentWrites, vmID)
+		return false
+	}
+	return true
+}
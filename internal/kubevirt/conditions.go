@@ -0,0 +1,57 @@
+package kubevirt
+
+import (
+	k8sv1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// errorStatuses are the printable statuses KubeVirt reports for a VM that
+// cannot make progress on its own
+var errorStatuses = map[kubevirtv1.VirtualMachinePrintableStatus]bool{
+	kubevirtv1.VirtualMachineStatusCrashLoopBackOff: true,
+	kubevirtv1.VirtualMachineStatusUnschedulable:    true,
+	kubevirtv1.VirtualMachineStatusErrImagePull:     true,
+	kubevirtv1.VirtualMachineStatusImagePullBackOff: true,
+	kubevirtv1.VirtualMachineStatusPvcNotFound:      true,
+	kubevirtv1.VirtualMachineStatusDataVolumeError:  true,
+}
+
+// terminatingMessage explains the Terminating reason of a VM deleted in the cluster
+const terminatingMessage = "VM is being deleted from the cluster"
+
+// VirtualMachineStatusReason explains why a VM is not running, based on the
+// conditions KubeVirt copies from its instance. A VM deleted in the cluster is
+// reported as Terminating, regardless of its conditions. A failure condition
+// takes precedence over an error printable status, whose details are taken
+// from the Ready condition. A VM whose changes only apply after a restart is
+// reported as RestartRequired. Both values are empty when nothing is wrong; a VM
+// that is merely starting or stopped is not reported.
+func VirtualMachineStatusReason(vm *kubevirtv1.VirtualMachine) (reason, message string) {
+	if vm.DeletionTimestamp != nil {
+		return string(kubevirtv1.VirtualMachineStatusTerminating), terminatingMessage
+	}
+	if c := findCondition(vm, kubevirtv1.VirtualMachineFailure); c != nil && c.Status == k8sv1.ConditionTrue {
+		return c.Reason, c.Message
+	}
+
+	if errorStatuses[vm.Status.PrintableStatus] {
+		if c := findCondition(vm, kubevirtv1.VirtualMachineReady); c != nil && c.Status != k8sv1.ConditionTrue {
+			message = c.Message
+		}
+		return string(vm.Status.PrintableStatus), message
+	}
+	if c := findCondition(vm, kubevirtv1.VirtualMachineRestartRequired); c != nil && c.Status == k8sv1.ConditionTrue {
+		return string(kubevirtv1.VirtualMachineRestartRequired), c.Message
+	}
+	return "", ""
+}
+
+// findCondition returns the VM condition of the given type, or nil
+func findCondition(vm *kubevirtv1.VirtualMachine, condType kubevirtv1.VirtualMachineConditionType) *kubevirtv1.VirtualMachineCondition {
+	for i := range vm.Status.Conditions {
+		if vm.Status.Conditions[i].Type == condType {
+			return &vm.Status.Conditions[i]
+		}
+	}
+	return nil
+}
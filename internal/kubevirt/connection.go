@@ -0,0 +1,65 @@
+package kubevirt
+
+import (
+	"strconv"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// ConnectionInfo is how a running VM is reached. The monitor records it on the
+// VM as annotations once the VM's instance is running, so that listing VMs
+// does not look it up in the cluster again.
+type ConnectionInfo struct {
+	// IP is the primary address of the VM
+	IP string
+	// SSHUser is the guest user the SSH public keys are installed for, when known
+	SSHUser string
+	// SSHSecretName names the Secret holding the SSH public keys
+	SSHSecretName string
+	// SSHNodePort is the node port SSH is published on, or zero
+	SSHNodePort int32
+}
+
+// Annotations returns the VM annotations recording the connection info, for a
+// merge patch. Empty fields map to nil, which removes a value recorded earlier.
+func (c ConnectionInfo) Annotations() map[string]*string {
+	annotations := map[string]*string{
+		constants.DCMAnnotationIPAddress:   nil,
+		constants.DCMAnnotationSSHUser:     nil,
+		constants.DCMAnnotationSSHSecret:   nil,
+		constants.DCMAnnotationSSHNodePort: nil,
+	}
+	for key, value := range map[string]string{
+		constants.DCMAnnotationIPAddress: c.IP,
+		constants.DCMAnnotationSSHUser:   c.SSHUser,
+		constants.DCMAnnotationSSHSecret: c.SSHSecretName,
+	} {
+		if value != "" {
+			annotations[key] = &value
+		}
+	}
+	if c.SSHNodePort != 0 {
+		port := strconv.Itoa(int(c.SSHNodePort))
+		annotations[constants.DCMAnnotationSSHNodePort] = &port
+	}
+	return annotations
+}
+
+// VirtualMachineConnectionInfo reads the connection info recorded on a VM, or
+// returns nil when none was recorded
+func VirtualMachineConnectionInfo(vm *kubevirtv1.VirtualMachine) *ConnectionInfo {
+	info := ConnectionInfo{
+		IP:            vm.Annotations[constants.DCMAnnotationIPAddress],
+		SSHUser:       vm.Annotations[constants.DCMAnnotationSSHUser],
+		SSHSecretName: vm.Annotations[constants.DCMAnnotationSSHSecret],
+	}
+	if port, err := strconv.ParseInt(vm.Annotations[constants.DCMAnnotationSSHNodePort], 10, 32); err == nil {
+		info.SSHNodePort = int32(port)
+	}
+	if info == (ConnectionInfo{}) {
+		return nil
+	}
+	return &info
+}
@@ -0,0 +1,79 @@
+package kubevirt
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// Provider hints controlling the guest CPU model and live migration
+const (
+	cpuModelHint      = "cpu_model"
+	liveMigrationHint = "live_migration"
+)
+
+// PassthroughMigrationPolicy decides what happens when a VM requests the
+// host-passthrough CPU model and live migration at the same time. Passthrough
+// exposes the exact host CPU, so such VMs cannot migrate between heterogeneous nodes.
+type PassthroughMigrationPolicy string
+
+const (
+	// PassthroughMigrationWarn keeps host-passthrough and logs a warning
+	PassthroughMigrationWarn PassthroughMigrationPolicy = "warn"
+	// PassthroughMigrationBlock keeps host-passthrough and disables live migration
+	PassthroughMigrationBlock PassthroughMigrationPolicy = "block"
+	// PassthroughMigrationHostModel replaces host-passthrough with host-model
+	PassthroughMigrationHostModel PassthroughMigrationPolicy = "host-model"
+)
+
+// ParsePassthroughMigrationPolicy validates a configured passthrough migration policy
+func ParsePassthroughMigrationPolicy(s string) (PassthroughMigrationPolicy, error) {
+	switch p := PassthroughMigrationPolicy(s); p {
+	case PassthroughMigrationWarn, PassthroughMigrationBlock, PassthroughMigrationHostModel:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown passthrough migration policy %q", s)
+	}
+}
+
+// applyCPUModel sets the guest CPU model and eviction strategy requested through
+// provider hints, resolving host-passthrough with live migration according to
+// the mapper's policy.
+func (m *Mapper) applyCPUModel(vmSpec *types.VMSpec, vmID string, spec *kubevirtv1.VirtualMachineInstanceSpec) error {
+	var model string
+	if _, err := decodeHint(vmSpec, cpuModelHint, &model); err != nil {
+		return err
+	}
+	var migrate bool
+	if _, err := decodeHint(vmSpec, liveMigrationHint, &migrate); err != nil {
+		return err
+	}
+
+	if migrate {
+		strategy := kubevirtv1.EvictionStrategyLiveMigrate
+		spec.EvictionStrategy = &strategy
+	}
+
+	if migrate && model == kubevirtv1.CPUModeHostPassthrough {
+		switch m.passthroughMigrationPolicy {
+		case PassthroughMigrationHostModel:
+			model = kubevirtv1.CPUModeHostModel
+		case PassthroughMigrationBlock:
+			zap.S().Warnw("host-passthrough CPU cannot live migrate between heterogeneous nodes, disabling live migration",
+				"vmID", vmID)
+			strategy := kubevirtv1.EvictionStrategyNone
+			spec.EvictionStrategy = &strategy
+		default:
+			zap.S().Warnw("host-passthrough CPU may fail to live migrate between heterogeneous nodes",
+				"vmID", vmID)
+		}
+	}
+
+	if model != "" {
+		spec.Domain.CPU = &kubevirtv1.CPU{Model: model}
+	}
+	return nil
+}
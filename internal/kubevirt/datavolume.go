@@ -0,0 +1,233 @@
+package kubevirt
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+var dataVolumeGVR = schema.GroupVersionResource{
+	Group:    "cdi.kubevirt.io",
+	Version:  "v1beta1",
+	Resource: "datavolumes",
+}
+
+// ErrDataVolumeNotFound is returned when a VM is created from a DataVolume
+// that does not exist
+var ErrDataVolumeNotFound = errors.New("data volume not found")
+
+// ErrDataVolumeNotShareable is returned when a shareable disk attaches a
+// DataVolume that is not a ReadWriteMany block volume
+var ErrDataVolumeNotShareable = errors.New("data volume cannot back a shareable disk")
+
+// DataVolumeSources returns the names of the existing DataVolumes a VM is
+// created from: those cloned by its data volume templates and those its
+// volumes attach directly.
+func DataVolumeSources(vm *kubevirtv1.VirtualMachine) []string {
+	var names []string
+	templated := map[string]bool{}
+	for _, t := range vm.Spec.DataVolumeTemplates {
+		templated[t.Name] = true
+		if t.Spec.Source != nil && t.Spec.Source.PVC != nil {
+			names = append(names, t.Spec.Source.PVC.Name)
+		}
+	}
+	if vm.Spec.Template != nil {
+		for _, vol := range vm.Spec.Template.Spec.Volumes {
+			if vol.DataVolume != nil && !templated[vol.DataVolume.Name] {
+				names = append(names, vol.DataVolume.Name)
+			}
+		}
+	}
+	return names
+}
+
+// CheckDataVolumeSources verifies that every existing DataVolume the VM is
+// created from is present in the namespace, and that those attached by
+// shareable disks are ReadWriteMany block volumes
+func (c *Client) CheckDataVolumeSources(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	names := DataVolumeSources(vm)
+	if len(names) == 0 {
+		return nil
+	}
+	shared := shareableDataVolumes(vm)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	for _, name := range names {
+		dv, err := c.dynamicClient.Resource(dataVolumeGVR).Namespace(c.namespace).Get(timeoutCtx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %q in namespace %s", ErrDataVolumeNotFound, name, c.namespace)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get data volume %q: %w", name, err)
+		}
+		if shared[name] && !dataVolumeShareable(dv) {
+			return fmt.Errorf("%w: %q must be a %s volume with %s access",
+				ErrDataVolumeNotShareable, name, k8sv1.PersistentVolumeBlock, k8sv1.ReadWriteMany)
+		}
+	}
+	return nil
+}
+
+// shareableDataVolumes returns the names of the DataVolumes attached by
+// shareable disks
+func shareableDataVolumes(vm *kubevirtv1.VirtualMachine) map[string]bool {
+	if vm.Spec.Template == nil {
+		return nil
+	}
+	shareable := map[string]bool{}
+	for _, disk := range vm.Spec.Template.Spec.Domain.Devices.Disks {
+		shareable[disk.Name] = disk.Shareable != nil && *disk.Shareable
+	}
+	names := map[string]bool{}
+	for _, vol := range vm.Spec.Template.Spec.Volumes {
+		if vol.DataVolume != nil && shareable[vol.Name] {
+			names[vol.DataVolume.Name] = true
+		}
+	}
+	return names
+}
+
+// dataVolumeShareable reports whether a DataVolume requests a ReadWriteMany
+// block volume, through either its storage or its pvc spec
+func dataVolumeShareable(dv *unstructured.Unstructured) bool {
+	for _, field := range []string{"storage", "pvc"} {
+		volumeMode, _, _ := unstructured.NestedString(dv.Object, "spec", field, "volumeMode")
+		accessModes, _, _ := unstructured.NestedStringSlice(dv.Object, "spec", field, "accessModes")
+		if volumeMode == string(k8sv1.PersistentVolumeBlock) && slices.Contains(accessModes, string(k8sv1.ReadWriteMany)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reasons reported by DiskImportStatus
+const (
+	DiskImportInProgress = "DiskImportInProgress"
+	DiskImportError      = "DiskImportError"
+)
+
+// dataVolumeErrorReasons are the reasons of a not-running DataVolume whose
+// import pod failed, such as when the source image cannot be pulled
+var dataVolumeErrorReasons = map[string]bool{
+	"Error":           true,
+	"ImagePullFailed": true,
+}
+
+// DiskImport is the state of a disk being populated from its data volume template
+type DiskImport struct {
+	// Disk is the name of the VM disk backed by the data volume
+	Disk string
+	// Phase is the CDI phase of the data volume, e.g. ImportInProgress
+	Phase string
+	// Progress is the completed percentage reported by CDI, e.g. "45.00%"
+	Progress string
+	// Error explains why the import is failing; empty while it progresses
+	Error string
+}
+
+// GetDiskImports returns the imports of the VM's data volume templates that
+// have not completed yet. Data volumes not created yet are skipped.
+func (c *Client) GetDiskImports(ctx context.Context, vm *kubevirtv1.VirtualMachine) ([]DiskImport, error) {
+	if len(vm.Spec.DataVolumeTemplates) == 0 {
+		return nil, nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var imports []DiskImport
+	for _, t := range vm.Spec.DataVolumeTemplates {
+		dv, err := c.dynamicClient.Resource(dataVolumeGVR).Namespace(c.namespace).Get(timeoutCtx, t.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get data volume %q: %w", t.Name, err)
+		}
+		if diskImport, ok := diskImportFromDataVolume(dv); ok {
+			diskImport.Disk = dataVolumeDisk(vm, t.Name)
+			imports = append(imports, diskImport)
+		}
+	}
+	return imports, nil
+}
+
+// diskImportFromDataVolume reads the import state of a data volume, reporting
+// false once it succeeded
+func diskImportFromDataVolume(dv *unstructured.Unstructured) (DiskImport, bool) {
+	phase, _, _ := unstructured.NestedString(dv.Object, "status", "phase")
+	if phase == "Succeeded" {
+		return DiskImport{}, false
+	}
+	progress, _, _ := unstructured.NestedString(dv.Object, "status", "progress")
+	diskImport := DiskImport{Phase: phase, Progress: progress}
+
+	conditions, _, _ := unstructured.NestedSlice(dv.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Running" || condition["status"] != "False" {
+			continue
+		}
+		reason, _ := condition["reason"].(string)
+		message, _ := condition["message"].(string)
+		if dataVolumeErrorReasons[reason] || phase == "Failed" {
+			diskImport.Error = message
+			if diskImport.Error == "" {
+				diskImport.Error = reason
+			}
+		}
+	}
+	if phase == "Failed" && diskImport.Error == "" {
+		diskImport.Error = phase
+	}
+	return diskImport, true
+}
+
+// dataVolumeDisk returns the name of the disk backed by a data volume
+func dataVolumeDisk(vm *kubevirtv1.VirtualMachine, dataVolume string) string {
+	if vm.Spec.Template != nil {
+		for _, vol := range vm.Spec.Template.Spec.Volumes {
+			if vol.DataVolume != nil && vol.DataVolume.Name == dataVolume {
+				return vol.Name
+			}
+		}
+	}
+	return dataVolume
+}
+
+// DiskImportStatus summarizes disk imports as a status reason and message. A
+// failing import takes precedence over imports in progress; both values are
+// empty without pending imports.
+func DiskImportStatus(imports []DiskImport) (reason, message string) {
+	var failing, progressing []string
+	for _, i := range imports {
+		switch {
+		case i.Error != "":
+			failing = append(failing, fmt.Sprintf("disk %s: %s", i.Disk, i.Error))
+		case i.Progress != "":
+			progressing = append(progressing, fmt.Sprintf("disk %s: %s", i.Disk, i.Progress))
+		default:
+			progressing = append(progressing, fmt.Sprintf("disk %s: %s", i.Disk, cmp.Or(i.Phase, "Pending")))
+		}
+	}
+	if len(failing) > 0 {
+		return DiskImportError, strings.Join(failing, "; ")
+	}
+	if len(progressing) > 0 {
+		return DiskImportInProgress, "Importing " + strings.Join(progressing, "; ")
+	}
+	return "", ""
+}
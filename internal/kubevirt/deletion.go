@@ -0,0 +1,44 @@
+package kubevirt
+
+import (
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// DeletedResource is a Kubernetes object removed along with a VM
+type DeletedResource struct {
+	Kind string
+	Name string
+}
+
+// DeletedResources lists the objects deleting a VM removes: the VM itself, its
+// running instance, and the data volumes, claims and secrets it owns. DataVolumes
+// attached without cloning are not owned by the VM and are kept. The port
+// Service is not referenced by the VM spec and is left to the caller.
+func DeletedResources(vm *kubevirtv1.VirtualMachine) []DeletedResource {
+	resources := []DeletedResource{{Kind: kubevirtv1.VirtualMachineGroupVersionKind.Kind, Name: vm.Name}}
+	if vm.Status.Created {
+		resources = append(resources, DeletedResource{Kind: kubevirtv1.VirtualMachineInstanceGroupVersionKind.Kind, Name: vm.Name})
+	}
+	for _, t := range vm.Spec.DataVolumeTemplates {
+		resources = append(resources, DeletedResource{Kind: "DataVolume", Name: t.Name})
+	}
+	if vm.Spec.Template == nil {
+		return resources
+	}
+
+	spec := vm.Spec.Template.Spec
+	for _, vol := range spec.Volumes {
+		switch {
+		case vol.PersistentVolumeClaim != nil:
+			resources = append(resources, DeletedResource{Kind: "PersistentVolumeClaim", Name: vol.PersistentVolumeClaim.ClaimName})
+		case vol.CloudInitNoCloud != nil && vol.CloudInitNoCloud.UserDataSecretRef != nil:
+			resources = append(resources, DeletedResource{Kind: "Secret", Name: vol.CloudInitNoCloud.UserDataSecretRef.Name})
+		}
+	}
+	for _, c := range spec.AccessCredentials {
+		if c.SSHPublicKey != nil && c.SSHPublicKey.Source.Secret != nil {
+			resources = append(resources, DeletedResource{Kind: "Secret", Name: c.SSHPublicKey.Source.Secret.SecretName})
+		}
+	}
+	return resources
+}
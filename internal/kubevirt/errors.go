@@ -3,6 +3,7 @@ package kubevirt
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
@@ -32,9 +33,13 @@ func classifyKubernetesError(err error, fallbackDetail string) (server.Error, in
 	case http.StatusConflict:
 		return problemError(http.StatusConflict, "Conflict", statusErr.ErrStatus.Message), http.StatusConflict
 	case http.StatusUnprocessableEntity:
-		return problemError(http.StatusUnprocessableEntity, "Validation Error", statusErr.ErrStatus.Message), http.StatusUnprocessableEntity
+		body := problemError(http.StatusUnprocessableEntity, "Validation Error", statusErr.ErrStatus.Message)
+		body.InvalidParams = invalidParams(statusErr)
+		return body, http.StatusUnprocessableEntity
 	case http.StatusBadRequest:
-		return problemError(http.StatusBadRequest, "Bad Request", statusErr.ErrStatus.Message), http.StatusBadRequest
+		body := problemError(http.StatusBadRequest, "Bad Request", statusErr.ErrStatus.Message)
+		body.InvalidParams = invalidParams(statusErr)
+		return body, http.StatusBadRequest
 	case http.StatusNotFound:
 		return problemError(http.StatusNotFound, "Not Found", statusErr.ErrStatus.Message), http.StatusNotFound
 	default:
@@ -42,6 +47,25 @@ func classifyKubernetesError(err error, fallbackDetail string) (server.Error, in
 	}
 }
 
+// invalidParams converts the field causes of a Kubernetes status error into
+// problem+json invalid params, or nil when it carries none
+func invalidParams(statusErr *apierrors.StatusError) *[]server.InvalidParam {
+	details := statusErr.ErrStatus.Details
+	if details == nil || len(details.Causes) == 0 {
+		return nil
+	}
+	params := make([]server.InvalidParam, 0, len(details.Causes))
+	for _, cause := range details.Causes {
+		param := server.InvalidParam{Name: cause.Field, Reason: cause.Message}
+		if cause.Type != "" {
+			causeType := string(cause.Type)
+			param.Type = &causeType
+		}
+		params = append(params, param)
+	}
+	return &params
+}
+
 // InternalServerError returns a problem+json error body and 500 status code.
 func InternalServerError(detail string) (server.Error, int) {
 	return problemError(http.StatusInternalServerError, "Internal Server Error", detail), http.StatusInternalServerError
@@ -52,6 +76,22 @@ func ValidationError(detail string) (server.Error, int) {
 	return problemError(http.StatusBadRequest, "Validation Error", detail), http.StatusBadRequest
 }
 
+// ConflictError returns a problem+json error body and 409 status code.
+func ConflictError(detail string) (server.Error, int) {
+	return problemError(http.StatusConflict, "Conflict", detail), http.StatusConflict
+}
+
+// PolicyViolationError returns a problem+json error body listing the policy
+// violations and 422 status code.
+func PolicyViolationError(violations []string) (server.Error, int) {
+	return problemError(http.StatusUnprocessableEntity, "Policy Violation", strings.Join(violations, "; ")), http.StatusUnprocessableEntity
+}
+
+// ServiceUnavailableError returns a problem+json error body and 503 status code.
+func ServiceUnavailableError(detail string) (server.Error, int) {
+	return problemError(http.StatusServiceUnavailable, "Service Unavailable", detail), http.StatusServiceUnavailable
+}
+
 // IsAlreadyExistsError checks if the error indicates a resource already exists.
 func IsAlreadyExistsError(err error) bool {
 	return apierrors.IsAlreadyExists(err)
@@ -114,9 +154,106 @@ func MapKubernetesErrorForList(err error) server.ListVMsResponseObject {
 	if err == nil {
 		return nil
 	}
+	if apierrors.IsResourceExpired(err) {
+		return &server.ListVMsdefaultApplicationProblemPlusJSONResponse{
+			Body:       problemError(http.StatusBadRequest, "Bad Request", "The page token has expired; list again from the first page"),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
 	body, statusCode := classifyKubernetesError(err, "Failed to list virtual machines")
 	return &server.ListVMsdefaultApplicationProblemPlusJSONResponse{
 		Body:       body,
 		StatusCode: statusCode,
 	}
 }
+
+// MapKubernetesErrorForUsage maps Kubernetes API and metrics errors to GetVMUsage responses.
+func MapKubernetesErrorForUsage(err error) server.GetVMUsageResponseObject {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ErrMetricsUnavailable) {
+		return server.GetVMUsage501ApplicationProblemPlusJSONResponse(
+			problemError(http.StatusNotImplemented, "Not Implemented", err.Error()))
+	}
+	body, statusCode := classifyKubernetesError(err, "Failed to retrieve virtual machine usage")
+	if statusCode == http.StatusNotFound {
+		return server.GetVMUsage404ApplicationProblemPlusJSONResponse(body)
+	}
+	return server.GetVMUsagedefaultApplicationProblemPlusJSONResponse{
+		Body:       body,
+		StatusCode: statusCode,
+	}
+}
+
+// MapKubernetesErrorForStart maps Kubernetes API errors to StartVM responses.
+func MapKubernetesErrorForStart(err error) server.StartVMResponseObject {
+	if err == nil {
+		return nil
+	}
+	body, statusCode := classifyKubernetesError(err, "Failed to start virtual machine")
+	switch statusCode {
+	case http.StatusNotFound:
+		return server.StartVM404ApplicationProblemPlusJSONResponse(body)
+	case http.StatusConflict:
+		return server.StartVM409ApplicationProblemPlusJSONResponse(body)
+	}
+	return server.StartVMdefaultApplicationProblemPlusJSONResponse{
+		Body:       body,
+		StatusCode: statusCode,
+	}
+}
+
+// MapKubernetesErrorForStop maps Kubernetes API errors to StopVM responses.
+func MapKubernetesErrorForStop(err error) server.StopVMResponseObject {
+	if err == nil {
+		return nil
+	}
+	body, statusCode := classifyKubernetesError(err, "Failed to stop virtual machine")
+	switch statusCode {
+	case http.StatusNotFound:
+		return server.StopVM404ApplicationProblemPlusJSONResponse(body)
+	case http.StatusConflict:
+		return server.StopVM409ApplicationProblemPlusJSONResponse(body)
+	}
+	return server.StopVMdefaultApplicationProblemPlusJSONResponse{
+		Body:       body,
+		StatusCode: statusCode,
+	}
+}
+
+// MapKubernetesErrorForRestart maps Kubernetes API errors to RestartVM responses.
+func MapKubernetesErrorForRestart(err error) server.RestartVMResponseObject {
+	if err == nil {
+		return nil
+	}
+	body, statusCode := classifyKubernetesError(err, "Failed to restart virtual machine")
+	switch statusCode {
+	case http.StatusNotFound:
+		return server.RestartVM404ApplicationProblemPlusJSONResponse(body)
+	case http.StatusConflict:
+		return server.RestartVM409ApplicationProblemPlusJSONResponse(body)
+	}
+	return server.RestartVMdefaultApplicationProblemPlusJSONResponse{
+		Body:       body,
+		StatusCode: statusCode,
+	}
+}
+
+// MapKubernetesErrorForResize maps Kubernetes API errors to ResizeVM responses.
+func MapKubernetesErrorForResize(err error) server.ResizeVMResponseObject {
+	if err == nil {
+		return nil
+	}
+	body, statusCode := classifyKubernetesError(err, "Failed to resize virtual machine")
+	switch statusCode {
+	case http.StatusNotFound:
+		return server.ResizeVM404ApplicationProblemPlusJSONResponse(body)
+	case http.StatusBadRequest:
+		return server.ResizeVM400ApplicationProblemPlusJSONResponse(body)
+	}
+	return server.ResizeVMdefaultApplicationProblemPlusJSONResponse{
+		Body:       body,
+		StatusCode: statusCode,
+	}
+}
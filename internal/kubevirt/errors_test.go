@@ -9,6 +9,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
@@ -117,6 +118,27 @@ var _ = Describe("Errors", func() {
 			errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
 			Expect(ok).To(BeTrue())
 			Expect(errResp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+			Expect(errResp.Body.InvalidParams).To(BeNil())
+		})
+
+		It("should report the causes of a validation error as invalid params", func() {
+			err := apierrors.NewInvalid(schema.GroupKind{Group: "kubevirt.io", Kind: "VirtualMachine"}, "dcm-vm", field.ErrorList{
+				field.Required(field.NewPath("spec", "template", "spec", "domain", "devices"), "must declare a disk"),
+				field.Invalid(field.NewPath("spec", "template", "spec", "domain", "memory", "guest"), "0", "must be greater than 0"),
+			})
+			resp := kubevirt.MapKubernetesError(err)
+
+			errResp, ok := resp.(*server.CreateVMdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusUnprocessableEntity))
+			Expect(errResp.Body.InvalidParams).NotTo(BeNil())
+
+			required := string(metav1.CauseTypeFieldValueRequired)
+			invalid := string(metav1.CauseTypeFieldValueInvalid)
+			Expect(*errResp.Body.InvalidParams).To(Equal([]server.InvalidParam{
+				{Name: "spec.template.spec.domain.devices", Reason: "Required value: must declare a disk", Type: &required},
+				{Name: "spec.template.spec.domain.memory.guest", Reason: `Invalid value: "0": must be greater than 0`, Type: &invalid},
+			}))
 		})
 
 		It("should map a bad request error to 400", func() {
@@ -210,5 +232,14 @@ var _ = Describe("Errors", func() {
 			Expect(ok).To(BeTrue())
 			Expect(errResp.StatusCode).To(Equal(http.StatusInternalServerError))
 		})
+
+		It("should map an expired page token to 400", func() {
+			err := apierrors.NewResourceExpired("continue token is too old")
+			resp := kubevirt.MapKubernetesErrorForList(err)
+
+			errResp, ok := resp.(*server.ListVMsdefaultApplicationProblemPlusJSONResponse)
+			Expect(ok).To(BeTrue())
+			Expect(errResp.StatusCode).To(Equal(http.StatusBadRequest))
+		})
 	})
 })
@@ -0,0 +1,138 @@
+package kubevirt
+
+import (
+	"fmt"
+	"sync"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/config"
+)
+
+// ClientFactory hands out namespace-scoped KubeVirt clients that share one set
+// of API connections. Clients are cached per namespace, so repeated calls for
+// the same namespace return the same client.
+type ClientFactory struct {
+	cfg           config.KubernetesConfig
+	overhead      k8sv1.ResourceList
+	restClient    *rest.RESTClient
+	dynamicClient dynamic.Interface
+	coreClient    kubernetes.Interface
+
+	mu      sync.Mutex
+	clients map[string]*Client
+}
+
+// NewClientFactory builds the KubeVirt REST, dynamic and core clients from the
+// configured kubeconfig, or from the in-cluster config when none is set
+func NewClientFactory(cfg *config.KubernetesConfig) (*ClientFactory, error) {
+	overhead, err := ParseOverhead(cfg.CPUOverhead, cfg.MemoryOverhead)
+	if err != nil {
+		return nil, err
+	}
+
+	var restConfig *rest.Config
+
+	if cfg.Kubeconfig != "" {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", cfg.Kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build config from kubeconfig file %s: %w", cfg.Kubeconfig, err)
+		}
+	} else {
+		restConfig, err = rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build in-cluster config: %w", err)
+		}
+	}
+
+	// Create typed REST client for KubeVirt API
+	kubevirtConfig := *restConfig
+	kubevirtConfig.GroupVersion = &schema.GroupVersion{Group: "kubevirt.io", Version: "v1"}
+	kubevirtConfig.APIPath = "/apis"
+	kubevirtConfig.NegotiatedSerializer = serializer.WithoutConversionCodecFactory{CodecFactory: kubevirtCodecs}
+	if kubevirtConfig.UserAgent == "" {
+		kubevirtConfig.UserAgent = rest.DefaultKubernetesUserAgent()
+	}
+
+	restClient, err := rest.RESTClientFor(&kubevirtConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create KubeVirt REST client: %w", err)
+	}
+
+	// Create dynamic client
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	// Create core client for Secrets and other core resources
+	coreClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create core client: %w", err)
+	}
+
+	return newClientFactory(cfg, overhead, restClient, dynamicClient, coreClient), nil
+}
+
+// newClientFactory creates a factory around already constructed API clients
+func newClientFactory(cfg *config.KubernetesConfig, overhead k8sv1.ResourceList, restClient *rest.RESTClient,
+	dynamicClient dynamic.Interface, coreClient kubernetes.Interface) *ClientFactory {
+	return &ClientFactory{
+		cfg:           *cfg,
+		overhead:      overhead,
+		restClient:    restClient,
+		dynamicClient: dynamicClient,
+		coreClient:    coreClient,
+		clients:       make(map[string]*Client),
+	}
+}
+
+// DynamicClient returns the dynamic client shared by the clients of every
+// namespace, for informers watching KubeVirt resources
+func (f *ClientFactory) DynamicClient() dynamic.Interface {
+	return f.dynamicClient
+}
+
+// Client returns the client for a namespace, creating it on first use. When
+// the VM cache is enabled the client's informer is set up but not started;
+// callers start it with StartVMCache.
+func (f *ClientFactory) Client(namespace string) (*Client, error) {
+	if err := ValidateNamespace(namespace); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if c, ok := f.clients[namespace]; ok {
+		return c, nil
+	}
+
+	c := &Client{
+		restClient:    f.restClient,
+		dynamicClient: f.dynamicClient,
+		coreClient:    f.coreClient,
+		namespace:     namespace,
+		timeout:       f.cfg.Timeout,
+		maxRetries:    f.cfg.MaxRetries,
+		retryBackoff:  defaultRetryBackoff,
+		overhead:      f.overhead,
+
+		namespaceCheckTTL: f.cfg.NamespaceCheckTTL,
+		getCoalesceTTL:    f.cfg.GetCoalesceTTL,
+	}
+	if f.cfg.VMCacheEnabled {
+		if err := c.setupVMCache(f.cfg.VMCacheResyncPeriod); err != nil {
+			return nil, err
+		}
+	}
+
+	f.clients[namespace] = c
+	return c, nil
+}
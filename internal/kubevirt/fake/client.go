@@ -0,0 +1,406 @@
+// Package fake provides an in-memory KubeVirt client for exercising handler
+// logic without a cluster.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+var (
+	virtualMachineResource = schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachines"}
+	secretResource         = schema.GroupResource{Resource: "secrets"}
+	serviceResource        = schema.GroupResource{Resource: "services"}
+	claimResource          = schema.GroupResource{Resource: "persistentvolumeclaims"}
+)
+
+// Client is an in-memory implementation of the KubeVirt client operations used by
+// the handlers. VirtualMachines, Secrets, Services and PersistentVolumeClaims are keyed by name, and VMs are looked
+// up by their DCM instance ID label like the real client. Objects are deep-copied
+// on the way in and out, so callers cannot mutate stored state.
+type Client struct {
+	namespace string
+
+	mu       sync.Mutex
+	vms      map[string]*kubevirtv1.VirtualMachine
+	secrets  map[string]*k8sv1.Secret
+	services map[string]*k8sv1.Service
+	claims   map[string]*k8sv1.PersistentVolumeClaim
+
+	// NamespaceAccessErr is returned by CheckNamespaceAccess
+	NamespaceAccessErr error
+	// NodePoolErr is returned by ResolveNodePool
+	NodePoolErr error
+	// CapacityErr is returned by CheckCapacity
+	CapacityErr error
+	// Usage holds the resource usage returned per DCM instance ID
+	Usage map[string]*kubevirt.ResourceUsage
+	// DataVolumes lists the names of the DataVolumes present in the namespace
+	DataVolumes []string
+	// DiskImports is returned as the pending imports of every VM with data volume templates
+	DiskImports []kubevirt.DiskImport
+}
+
+// NewClient creates an empty fake client for the given namespace
+func NewClient(namespace string) *Client {
+	return &Client{
+		namespace: namespace,
+		vms:       map[string]*kubevirtv1.VirtualMachine{},
+		secrets:   map[string]*k8sv1.Secret{},
+		services:  map[string]*k8sv1.Service{},
+		claims:    map[string]*k8sv1.PersistentVolumeClaim{},
+	}
+}
+
+// CreateVirtualMachine stores a new VirtualMachine
+func (c *Client) CreateVirtualMachine(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.vms[vm.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(virtualMachineResource, vm.Name)
+	}
+	stored := vm.DeepCopy()
+	stored.Namespace = c.namespace
+	stored.SetGroupVersionKind(kubevirtv1.VirtualMachineGroupVersionKind)
+	c.vms[vm.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+// GetVirtualMachine returns the VirtualMachine labelled with the DCM instance ID
+func (c *Client) GetVirtualMachine(_ context.Context, vmID string) (*kubevirtv1.VirtualMachine, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vm := c.findByInstanceID(vmID)
+	if vm == nil {
+		return nil, apierrors.NewNotFound(virtualMachineResource, vmID)
+	}
+	return vm.DeepCopy(), nil
+}
+
+// ListVirtualMachines returns the VirtualMachines matching the label selector
+// in name order. With a limit set it returns one page; the continue token is
+// the name of the last VM returned, and listing resumes after it.
+func (c *Client) ListVirtualMachines(_ context.Context, options metav1.ListOptions) ([]kubevirtv1.VirtualMachine, string, error) {
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		return nil, "", apierrors.NewBadRequest(fmt.Sprintf("invalid label selector: %v", err))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.vms))
+	for name := range c.vms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var vms []kubevirtv1.VirtualMachine
+	for _, name := range names {
+		vm := c.vms[name]
+		if name <= options.Continue || !selector.Matches(labels.Set(vm.Labels)) {
+			continue
+		}
+		if options.Limit > 0 && int64(len(vms)) == options.Limit {
+			return vms, vms[len(vms)-1].Name, nil
+		}
+		vms = append(vms, *vm.DeepCopy())
+	}
+	return vms, "", nil
+}
+
+// DeleteVirtualMachine removes the VirtualMachine labelled with the DCM instance ID
+func (c *Client) DeleteVirtualMachine(_ context.Context, vmID string, _ *int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vm := c.findByInstanceID(vmID)
+	if vm == nil {
+		return apierrors.NewNotFound(virtualMachineResource, vmID)
+	}
+	delete(c.vms, vm.Name)
+	return nil
+}
+
+// UpdateVirtualMachine replaces a stored VirtualMachine
+func (c *Client) UpdateVirtualMachine(_ context.Context, vm *kubevirtv1.VirtualMachine) (*kubevirtv1.VirtualMachine, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.vms[vm.Name]; !ok {
+		return nil, apierrors.NewNotFound(virtualMachineResource, vm.Name)
+	}
+	stored := vm.DeepCopy()
+	c.vms[vm.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+// StartVirtualMachine marks the VirtualMachine labelled with the DCM instance ID
+// as running, as KubeVirt reports it once the VM has started
+func (c *Client) StartVirtualMachine(_ context.Context, vmID string) error {
+	return c.setRunning(vmID, true)
+}
+
+// StopVirtualMachine marks the VirtualMachine labelled with the DCM instance ID
+// as stopped
+func (c *Client) StopVirtualMachine(_ context.Context, vmID string) error {
+	return c.setRunning(vmID, false)
+}
+
+// RestartVirtualMachine leaves a running VirtualMachine running, and fails
+// with a conflict for a stopped one like KubeVirt does
+func (c *Client) RestartVirtualMachine(_ context.Context, vmID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vm := c.findByInstanceID(vmID)
+	if vm == nil {
+		return apierrors.NewNotFound(virtualMachineResource, vmID)
+	}
+	if vm.Status.PrintableStatus != kubevirtv1.VirtualMachineStatusRunning {
+		return apierrors.NewConflict(virtualMachineResource, vm.Name, fmt.Errorf("VM is not running"))
+	}
+	return nil
+}
+
+func (c *Client) setRunning(vmID string, running bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vm := c.findByInstanceID(vmID)
+	if vm == nil {
+		return apierrors.NewNotFound(virtualMachineResource, vmID)
+	}
+	vm.Status.Created = running
+	vm.Status.Ready = running
+	vm.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusStopped
+	if running {
+		vm.Status.PrintableStatus = kubevirtv1.VirtualMachineStatusRunning
+	}
+	return nil
+}
+
+// GetVirtualMachineUsage returns the usage stored for the DCM instance ID
+func (c *Client) GetVirtualMachineUsage(_ context.Context, vmID string) (*kubevirt.ResourceUsage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usage, ok := c.Usage[vmID]
+	if !ok {
+		return nil, apierrors.NewNotFound(virtualMachineResource, vmID)
+	}
+	copied := *usage
+	return &copied, nil
+}
+
+// CheckNamespaceAccess returns NamespaceAccessErr
+func (c *Client) CheckNamespaceAccess(_ context.Context) error {
+	return c.NamespaceAccessErr
+}
+
+// ResolveNodePool returns NodePoolErr and leaves the VM untouched
+func (c *Client) ResolveNodePool(_ context.Context, _ *kubevirtv1.VirtualMachine) error {
+	return c.NodePoolErr
+}
+
+// CheckCapacity returns CapacityErr
+func (c *Client) CheckCapacity(_ context.Context, _ *kubevirtv1.VirtualMachine) error {
+	return c.CapacityErr
+}
+
+// CheckDataVolumeSources fails with kubevirt.ErrDataVolumeNotFound when the VM
+// is created from a DataVolume missing from DataVolumes
+func (c *Client) CheckDataVolumeSources(_ context.Context, vm *kubevirtv1.VirtualMachine) error {
+	for _, name := range kubevirt.DataVolumeSources(vm) {
+		if !slices.Contains(c.DataVolumes, name) {
+			return fmt.Errorf("%w: %q in namespace %s", kubevirt.ErrDataVolumeNotFound, name, c.namespace)
+		}
+	}
+	return nil
+}
+
+// GetDiskImports returns DiskImports for VMs with data volume templates
+func (c *Client) GetDiskImports(_ context.Context, vm *kubevirtv1.VirtualMachine) ([]kubevirt.DiskImport, error) {
+	if len(vm.Spec.DataVolumeTemplates) == 0 {
+		return nil, nil
+	}
+	return slices.Clone(c.DiskImports), nil
+}
+
+// CreateSecret stores a new Secret
+func (c *Client) CreateSecret(_ context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.secrets[secret.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(secretResource, secret.Name)
+	}
+	stored := secret.DeepCopy()
+	stored.Namespace = c.namespace
+	c.secrets[secret.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+// GetSecret returns a stored Secret by name
+func (c *Client) GetSecret(_ context.Context, name string) (*k8sv1.Secret, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	secret, ok := c.secrets[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(secretResource, name)
+	}
+	return secret.DeepCopy(), nil
+}
+
+// UpdateSecret replaces a stored Secret
+func (c *Client) UpdateSecret(_ context.Context, secret *k8sv1.Secret) (*k8sv1.Secret, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.secrets[secret.Name]; !ok {
+		return nil, apierrors.NewNotFound(secretResource, secret.Name)
+	}
+	stored := secret.DeepCopy()
+	c.secrets[secret.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+// DeleteSecret removes a stored Secret
+func (c *Client) DeleteSecret(_ context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.secrets[name]; !ok {
+		return apierrors.NewNotFound(secretResource, name)
+	}
+	delete(c.secrets, name)
+	return nil
+}
+
+// DeleteSecretsByInstanceID removes the stored DCM-managed Secrets labelled with
+// the DCM instance ID
+func (c *Client) DeleteSecretsByInstanceID(_ context.Context, vmID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, secret := range c.secrets {
+		if secret.Labels[constants.DCMLabelManagedBy] == constants.DCMManagedByValue &&
+			secret.Labels[constants.DCMLabelInstanceID] == vmID {
+			delete(c.secrets, name)
+		}
+	}
+	return nil
+}
+
+// Secret returns a copy of the stored Secret with the given name, or nil
+func (c *Client) Secret(name string) *k8sv1.Secret {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if secret, ok := c.secrets[name]; ok {
+		return secret.DeepCopy()
+	}
+	return nil
+}
+
+// CreateService stores a new Service
+func (c *Client) CreateService(_ context.Context, service *k8sv1.Service) (*k8sv1.Service, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.services[service.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(serviceResource, service.Name)
+	}
+	stored := service.DeepCopy()
+	stored.Namespace = c.namespace
+	c.services[service.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+// GetService returns a stored Service by name
+func (c *Client) GetService(_ context.Context, name string) (*k8sv1.Service, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	service, ok := c.services[name]
+	if !ok {
+		return nil, apierrors.NewNotFound(serviceResource, name)
+	}
+	return service.DeepCopy(), nil
+}
+
+// ListServices returns the stored Services labelled with the DCM instance ID
+func (c *Client) ListServices(_ context.Context, vmID string) ([]k8sv1.Service, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var services []k8sv1.Service
+	for _, service := range c.services {
+		if service.Labels[constants.DCMLabelManagedBy] == constants.DCMManagedByValue &&
+			service.Labels[constants.DCMLabelInstanceID] == vmID {
+			services = append(services, *service.DeepCopy())
+		}
+	}
+	return services, nil
+}
+
+// DeleteService removes a stored Service
+func (c *Client) DeleteService(_ context.Context, name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.services[name]; !ok {
+		return apierrors.NewNotFound(serviceResource, name)
+	}
+	delete(c.services, name)
+	return nil
+}
+
+// CreatePersistentVolumeClaim stores a new PersistentVolumeClaim
+func (c *Client) CreatePersistentVolumeClaim(_ context.Context, claim *k8sv1.PersistentVolumeClaim) (*k8sv1.PersistentVolumeClaim, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.claims[claim.Name]; ok {
+		return nil, apierrors.NewAlreadyExists(claimResource, claim.Name)
+	}
+	stored := claim.DeepCopy()
+	stored.Namespace = c.namespace
+	c.claims[claim.Name] = stored
+	return stored.DeepCopy(), nil
+}
+
+// PersistentVolumeClaim returns a stored PersistentVolumeClaim by name, or nil
+func (c *Client) PersistentVolumeClaim(name string) *k8sv1.PersistentVolumeClaim {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if claim, ok := c.claims[name]; ok {
+		return claim.DeepCopy()
+	}
+	return nil
+}
+
+func (c *Client) findByInstanceID(vmID string) *kubevirtv1.VirtualMachine {
+	for _, vm := range c.vms {
+		if vm.Labels[constants.DCMLabelInstanceID] == vmID {
+			return vm
+		}
+	}
+	return nil
+}
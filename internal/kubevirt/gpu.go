@@ -0,0 +1,62 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// gpusHint requests GPUs, including mediated devices (vGPUs), for a VM
+const gpusHint = "gpus"
+
+// gpuRequest is a single GPU declared in provider hints. DeviceName is the
+// resource name the device plugin advertises, e.g. nvidia.com/GRID_T4-1Q for a
+// vGPU profile. Display and RamFB only apply to vGPUs.
+type gpuRequest struct {
+	Name       string `json:"name,omitempty"`
+	DeviceName string `json:"device_name"`
+	Display    *bool  `json:"display,omitempty"`
+	RamFB      *bool  `json:"ramfb,omitempty"`
+}
+
+// buildGPUs returns the GPU devices requested through provider hints. Unnamed
+// GPUs are named gpu<index>.
+func buildGPUs(vmSpec *types.VMSpec) ([]kubevirtv1.GPU, error) {
+	var requests []gpuRequest
+	if _, err := decodeHint(vmSpec, gpusHint, &requests); err != nil {
+		return nil, err
+	}
+
+	gpus := make([]kubevirtv1.GPU, 0, len(requests))
+	seen := make(map[string]bool, len(requests))
+	for i, req := range requests {
+		if !strings.Contains(req.DeviceName, "/") {
+			return nil, fmt.Errorf("gpu %d: device_name must be a device plugin resource name, got %q", i, req.DeviceName)
+		}
+		name := req.Name
+		if name == "" {
+			name = fmt.Sprintf("gpu%d", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("gpu %d: duplicate name %q", i, name)
+		}
+		seen[name] = true
+
+		gpu := kubevirtv1.GPU{
+			Name:       name,
+			DeviceName: req.DeviceName,
+		}
+		if req.Display != nil || req.RamFB != nil {
+			display := &kubevirtv1.VGPUDisplayOptions{Enabled: req.Display}
+			if req.RamFB != nil {
+				display.RamFB = &kubevirtv1.FeatureState{Enabled: req.RamFB}
+			}
+			gpu.VirtualGPUOptions = &kubevirtv1.VGPUOptions{Display: display}
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus, nil
+}
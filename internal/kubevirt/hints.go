@@ -0,0 +1,90 @@
+package kubevirt
+
+import (
+	"encoding/json"
+	"fmt"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// providerHintsKey is the provider_hints entry read by this provider
+const providerHintsKey = "kubevirt"
+
+// containerDisksHint lists ordered container-disk layers that replace the boot disk
+const containerDisksHint = "container_disks"
+
+// containerDiskLayer is a single container-disk layer declared in provider hints
+type containerDiskLayer struct {
+	Name     string `json:"name,omitempty"`
+	Image    string `json:"image"`
+	Bootable bool   `json:"bootable,omitempty"`
+}
+
+// kubevirtHints returns the provider hints addressed to this provider, if any
+func kubevirtHints(vmSpec *types.VMSpec) map[string]interface{} {
+	if vmSpec == nil || vmSpec.ProviderHints == nil {
+		return nil
+	}
+	return (*vmSpec.ProviderHints)[providerHintsKey]
+}
+
+// decodeHint decodes a single provider hint into out. It reports whether the
+// hint was present.
+func decodeHint(vmSpec *types.VMSpec, key string, out interface{}) (bool, error) {
+	raw, ok := kubevirtHints(vmSpec)[key]
+	if !ok || raw == nil {
+		return false, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return true, fmt.Errorf("invalid provider hint %s: %w", key, err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return true, fmt.Errorf("invalid provider hint %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// containerDiskLayers returns the container-disk layers requested for the boot
+// disk in declaration order. Layers without a name are named after the boot disk
+// and their position. At least one layer must be bootable.
+func containerDiskLayers(vmSpec *types.VMSpec) ([]containerDiskLayer, error) {
+	var layers []containerDiskLayer
+	found, err := decodeHint(vmSpec, containerDisksHint, &layers)
+	if err != nil || !found {
+		return nil, err
+	}
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("provider hint %s must declare at least one layer", containerDisksHint)
+	}
+
+	// Layer names share the disk namespace with the remaining data disks
+	bootName := bootDiskName(vmSpec)
+	seen := make(map[string]bool, len(layers))
+	for i, disk := range vmSpec.Storage.Disks {
+		if i > 0 {
+			seen[disk.Name] = true
+		}
+	}
+	bootable := false
+	for i := range layers {
+		if layers[i].Image == "" {
+			return nil, fmt.Errorf("container disk layer %d: image is required", i)
+		}
+		if layers[i].Name == "" {
+			layers[i].Name = bootName
+			if i > 0 {
+				layers[i].Name = fmt.Sprintf("%s-layer-%d", bootName, i)
+			}
+		}
+		if seen[layers[i].Name] {
+			return nil, fmt.Errorf("container disk layer %d: duplicate name %q", i, layers[i].Name)
+		}
+		seen[layers[i].Name] = true
+		bootable = bootable || layers[i].Bootable
+	}
+	if !bootable {
+		return nil, fmt.Errorf("provider hint %s must declare at least one bootable layer", containerDisksHint)
+	}
+	return layers, nil
+}
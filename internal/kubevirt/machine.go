@@ -0,0 +1,35 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// machineTypeHint selects the emulated machine type of a single VM
+const machineTypeHint = "machine_type"
+
+// machine resolves the machine type for a VM, preferring the machine_type
+// provider hint over the mapper default. Without either, the machine type is
+// left to the cluster default.
+func (m *Mapper) machine(vmSpec *types.VMSpec) (*kubevirtv1.Machine, error) {
+	machineType := m.machineType
+	var hint string
+	found, err := decodeHint(vmSpec, machineTypeHint, &hint)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		if strings.TrimSpace(hint) == "" || strings.ContainsAny(hint, " \t\n") {
+			return nil, fmt.Errorf("invalid provider hint %s: %q", machineTypeHint, hint)
+		}
+		machineType = hint
+	}
+	if machineType == "" {
+		return nil, nil
+	}
+	return &kubevirtv1.Machine{Type: machineType}, nil
+}
@@ -0,0 +1,46 @@
+package kubevirt
+
+import (
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// applyMaintenanceProfile prepares a VM for node maintenance when the
+// maintenance-ready profile is enabled: the VM is evicted by live migration
+// and annotated so that drain tooling can tell it apart. Live migration needs
+// every provisioned volume to be ReadWriteMany; attached DataVolumes are not
+// provisioned by the VM and are assumed to be shareable.
+func (m *Mapper) applyMaintenanceProfile(vm *kubevirtv1.VirtualMachine, backends map[string]diskStorage) error {
+	if !m.maintenanceReady {
+		return nil
+	}
+
+	for name, storage := range backends {
+		if storage.Backend != diskBackendPersistentVolumeClaim && storage.Backend != diskBackendDataVolume {
+			continue
+		}
+		if storage.AccessMode != "" && storage.AccessMode != string(k8sv1.ReadWriteMany) {
+			return fmt.Errorf("disk storage %q: maintenance-ready VMs require %s storage, not %s",
+				name, k8sv1.ReadWriteMany, storage.AccessMode)
+		}
+	}
+
+	spec := &vm.Spec.Template.Spec
+	if spec.EvictionStrategy != nil && *spec.EvictionStrategy != kubevirtv1.EvictionStrategyLiveMigrate {
+		return fmt.Errorf("maintenance-ready VMs must live migrate, but the requested CPU model disables live migration")
+	}
+	strategy := kubevirtv1.EvictionStrategyLiveMigrate
+	spec.EvictionStrategy = &strategy
+
+	for _, meta := range []*map[string]string{&vm.Annotations, &vm.Spec.Template.ObjectMeta.Annotations} {
+		if *meta == nil {
+			*meta = map[string]string{}
+		}
+		(*meta)[constants.DCMAnnotationMaintenanceReady] = "true"
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"strings"
 
+	"go.uber.org/zap"
 	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,36 +15,372 @@ import (
 	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
 )
 
+// defaultDataDiskCapacity is used for data disks that do not request a capacity
+const defaultDataDiskCapacity = "10Gi"
+
+// defaultGuestOSType is the guest OS used when a request omits it
+const defaultGuestOSType = "cirros"
+
+// defaultMachineType is the machine type of VMs unless configured otherwise
+const defaultMachineType = "q35"
+
 // Mapper handles conversion from VMSpec to KubeVirt VirtualMachine resources
 type Mapper struct {
-	namespace string
+	namespace           string
+	storageGranularity  resource.Quantity
+	cloudInitFromSecret bool
+
+	passthroughMigrationPolicy PassthroughMigrationPolicy
+	nodePool                   NodePool
+	sshKeyPropagationDefault   SSHKeyPropagation
+	defaultGuestOS             string
+	requireGuestOS             bool
+	runStrategyDefault         kubevirtv1.VirtualMachineRunStrategy
+	machineType                string
+	scratchDiskRatio           float64
+	subdomainDefault           string
+	minBootDiskCapacity        resource.Quantity
+	strictBootDiskCapacity     bool
+	maxSSHKeys                 int
+	maxSSHKeyBytes             int
+	maintenanceReady           bool
+	serialChannelsDefault      []string
+	interfaceModels            map[string]string
+	cloudInitBase              map[string]interface{}
+	tpmDefault                 TPMMode
+	vmStateStorageClass        string
+	sshKeySecretLayout         SSHKeySecretLayout
+	portServiceType            k8sv1.ServiceType
+	sshTargetPort              int32
+	schedulerName              string
+	accessLabels               map[string]string
+	accessAnnotations          map[string]string
+	memoryRequestRatio         float64
+}
+
+// MapperOption configures a Mapper.
+type MapperOption func(*Mapper)
+
+// SetStorageGranularity sets the allocation granularity that requested disk
+// capacities are rounded up to. A zero quantity disables rounding.
+func SetStorageGranularity(q resource.Quantity) MapperOption {
+	return func(m *Mapper) {
+		m.storageGranularity = q
+	}
+}
+
+// SetCloudInitFromSecret stores cloud-init user data in a Secret referenced by the
+// VM instead of inlining it in the VM spec.
+func SetCloudInitFromSecret(enabled bool) MapperOption {
+	return func(m *Mapper) {
+		m.cloudInitFromSecret = enabled
+	}
+}
+
+// SetPassthroughMigrationPolicy sets how host-passthrough CPUs are handled for
+// VMs that request live migration.
+func SetPassthroughMigrationPolicy(p PassthroughMigrationPolicy) MapperOption {
+	return func(m *Mapper) {
+		m.passthroughMigrationPolicy = p
+	}
+}
+
+// SetNodePool sets the default node pool VMs are scheduled onto. A node_pool
+// provider hint replaces it for a single VM.
+func SetNodePool(pool NodePool) MapperOption {
+	return func(m *Mapper) {
+		m.nodePool = pool
+	}
+}
+
+// SetSSHKeyPropagation sets the default method used to propagate SSH public keys
+// to guests. An ssh_key_propagation provider hint overrides it for a single VM.
+func SetSSHKeyPropagation(p SSHKeyPropagation) MapperOption {
+	return func(m *Mapper) {
+		m.sshKeyPropagationDefault = p
+	}
+}
+
+// SetDefaultGuestOS sets the guest OS type used when a request omits it
+func SetDefaultGuestOS(osType string) MapperOption {
+	return func(m *Mapper) {
+		m.defaultGuestOS = osType
+	}
+}
+
+// SetRequireGuestOS rejects requests that omit the guest OS type instead of
+// falling back to the default guest OS.
+func SetRequireGuestOS(required bool) MapperOption {
+	return func(m *Mapper) {
+		m.requireGuestOS = required
+	}
+}
+
+// SetRunStrategy sets the default run strategy of new VMs. A run_strategy
+// provider hint overrides it for a single VM.
+func SetRunStrategy(strategy kubevirtv1.VirtualMachineRunStrategy) MapperOption {
+	return func(m *Mapper) {
+		m.runStrategyDefault = strategy
+	}
+}
+
+// SetMachineType sets the default machine type of new VMs. An empty type
+// leaves it to the cluster default. A machine_type provider hint overrides it
+// for a single VM.
+func SetMachineType(machineType string) MapperOption {
+	return func(m *Mapper) {
+		m.machineType = machineType
+	}
+}
+
+// SetScratchDiskRatio attaches a scratch disk, e.g. for swap, sized as the given
+// multiple of the requested memory to every VM. Zero disables it.
+func SetScratchDiskRatio(ratio float64) MapperOption {
+	return func(m *Mapper) {
+		m.scratchDiskRatio = ratio
+	}
+}
+
+// SetSubdomain sets the default DNS subdomain of new VMs. An empty subdomain
+// disables DNS records. A subdomain provider hint overrides it for a single VM.
+func SetSubdomain(subdomain string) MapperOption {
+	return func(m *Mapper) {
+		m.subdomainDefault = subdomain
+	}
+}
+
+// SetMinBootDiskCapacity sets the smallest capacity of a boot disk imported
+// into a data volume. Smaller requests are raised to it with a warning, or
+// rejected when strict is set. A zero quantity disables the minimum.
+func SetMinBootDiskCapacity(q resource.Quantity, strict bool) MapperOption {
+	return func(m *Mapper) {
+		m.minBootDiskCapacity = q
+		m.strictBootDiskCapacity = strict
+	}
+}
+
+// SetSSHKeyLimits bounds the number and total size in bytes of the SSH public
+// keys a VM may carry. A zero value disables the corresponding limit.
+func SetSSHKeyLimits(maxKeys, maxBytes int) MapperOption {
+	return func(m *Mapper) {
+		m.maxSSHKeys = maxKeys
+		m.maxSSHKeyBytes = maxBytes
+	}
+}
+
+// SetMaintenanceReady applies the maintenance-ready profile to every VM, so
+// that node drains live migrate VMs instead of shutting them down.
+func SetMaintenanceReady(enabled bool) MapperOption {
+	return func(m *Mapper) {
+		m.maintenanceReady = enabled
+	}
+}
+
+// SetSerialChannels sets the virtio-serial channels attached to VMs that do
+// not request their own. The names must be validated with ParseSerialChannels.
+func SetSerialChannels(channels []string) MapperOption {
+	return func(m *Mapper) {
+		m.serialChannelsDefault = channels
+	}
+}
+
+// SetInterfaceModels sets the default NIC model per guest OS type; other guests
+// get DefaultInterfaceModel. The models must be validated with
+// ParseInterfaceModels.
+func SetInterfaceModels(models map[string]string) MapperOption {
+	return func(m *Mapper) {
+		m.interfaceModels = models
+	}
+}
+
+// SetCloudInitBase sets a base cloud-config merged into the cloud-init of every
+// VM, with the request-derived configuration layered over it. The document
+// must be parsed with ParseCloudInitBase.
+func SetCloudInitBase(base map[string]interface{}) MapperOption {
+	return func(m *Mapper) {
+		m.cloudInitBase = base
+	}
+}
+
+// SetTPM sets the default TPM mode of new VMs, which a tpm provider hint
+// overrides for a single VM. vmStateStorageClass names the storage class
+// KubeVirt keeps persistent TPM state in; without it persistent TPMs are
+// rejected.
+func SetTPM(mode TPMMode, vmStateStorageClass string) MapperOption {
+	return func(m *Mapper) {
+		m.tpmDefault = mode
+		m.vmStateStorageClass = vmStateStorageClass
+	}
+}
+
+// SetSSHKeySecretLayout sets whether multiple SSH public keys are stored
+// joined under one Secret entry or each under its own entry.
+func SetSSHKeySecretLayout(layout SSHKeySecretLayout) MapperOption {
+	return func(m *Mapper) {
+		m.sshKeySecretLayout = layout
+	}
+}
+
+// SetPortServiceType sets the type of the Service publishing guest ports. An
+// expose_ports provider hint may request another type for a single VM.
+func SetPortServiceType(serviceType k8sv1.ServiceType) MapperOption {
+	return func(m *Mapper) {
+		m.portServiceType = serviceType
+	}
+}
+
+// SetSSHTargetPort sets the guest port that SSH, published on Service port 22,
+// is forwarded to. An expose_ports entry may set its own target port.
+func SetSSHTargetPort(port int32) MapperOption {
+	return func(m *Mapper) {
+		m.sshTargetPort = port
+	}
+}
+
+// SetSchedulerName sets the scheduler VM launcher pods are scheduled by; empty
+// uses the default scheduler. The scheduler_name provider hint overrides it.
+func SetSchedulerName(name string) MapperOption {
+	return func(m *Mapper) {
+		m.schedulerName = name
+	}
+}
+
+// SetAccessMetadata sets extra labels and annotations for the SSH key Secret
+// and port Service of each VM, such as those a backup or audit tool selects on.
+func SetAccessMetadata(labels, annotations map[string]string) MapperOption {
+	return func(m *Mapper) {
+		m.accessLabels = labels
+		m.accessAnnotations = annotations
+	}
+}
+
+// SetMemoryRequestRatio sets the memory VM launcher pods request as a fraction
+// of the guest memory. 1 requests the full guest memory; lower values let the
+// scheduler overcommit node memory.
+func SetMemoryRequestRatio(ratio float64) MapperOption {
+	return func(m *Mapper) {
+		m.memoryRequestRatio = ratio
+	}
 }
 
 // NewMapper creates a new mapper instance
-func NewMapper(namespace string) *Mapper {
-	return &Mapper{
-		namespace: namespace,
+func NewMapper(namespace string, opts ...MapperOption) *Mapper {
+	m := &Mapper{
+		namespace:                  namespace,
+		passthroughMigrationPolicy: PassthroughMigrationWarn,
+		sshKeyPropagationDefault:   SSHKeyPropagationNoCloud,
+		defaultGuestOS:             defaultGuestOSType,
+		runStrategyDefault:         kubevirtv1.RunStrategyAlways,
+		machineType:                defaultMachineType,
+		tpmDefault:                 TPMNone,
+		sshKeySecretLayout:         SSHKeySecretJoined,
+		portServiceType:            k8sv1.ServiceTypeNodePort,
+		sshTargetPort:              sshServicePort,
+		memoryRequestRatio:         1,
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	return m
+}
+
+// vmNamePrefix is prepended to the DCM instance ID to form the VirtualMachine name
+const vmNamePrefix = "dcm-"
+
+// virtualMachineName derives the VirtualMachine name from the full DCM instance ID,
+// so distinct IDs never map to the same name and re-creates are idempotent.
+func virtualMachineName(vmID string) string {
+	return vmNamePrefix + strings.ToLower(vmID)
 }
 
 // VMSpecToVirtualMachine converts a DCM VMSpec to a typed KubeVirt VirtualMachine
 func (m *Mapper) VMSpecToVirtualMachine(vmSpec *types.VMSpec, vmID string) (*kubevirtv1.VirtualMachine, error) {
-	runStrategy := kubevirtv1.RunStrategyAlways
+	vmSpec, err := m.withGuestOS(vmSpec, vmID)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := containerDiskLayers(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	resources, memory, err := m.buildResources(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	backends, err := diskStorageBackends(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) > 0 {
+		if storage, ok := backends[bootDiskName(vmSpec)]; ok && storage.Backend != diskBackendContainerDisk {
+			return nil, fmt.Errorf("provider hint %s conflicts with the %s backend of the boot disk", containerDisksHint, storage.Backend)
+		}
+	}
+	disks := m.buildDisks(vmSpec, layers)
+	volumes, err := m.buildVolumes(vmSpec, vmID, layers, backends)
+	if err != nil {
+		return nil, err
+	}
+	dataVolumeTemplates, err := m.buildDataVolumeTemplates(vmSpec, vmID, backends)
+	if err != nil {
+		return nil, err
+	}
+	scratchDisk, scratchVolume, err := m.buildScratchDisk(vmSpec, *memory.Guest)
+	if err != nil {
+		return nil, err
+	}
+	if scratchDisk != nil {
+		disks = append(disks, *scratchDisk)
+		volumes = append(volumes, *scratchVolume)
+	}
+	accessCredentials, err := m.buildAccessCredentials(vmSpec, vmID)
+	if err != nil {
+		return nil, err
+	}
+	cloudConfig, err := m.cloudConfig(vmSpec, vmID)
+	if err != nil {
+		return nil, err
+	}
+	if cloudConfig != nil {
+		userData, err := generateCloudInitUserData(cloudConfig)
+		if err != nil {
+			return nil, err
+		}
+		disks = append(disks, kubevirtv1.Disk{
+			Name: cloudInitVolumeName,
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: kubevirtv1.DiskBusVirtio,
+				},
+			},
+		})
+		volumes = append(volumes, m.buildCloudInitVolume(userData, vmID))
+	}
+
+	runStrategy, err := m.runStrategy(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	machine, err := m.machine(vmSpec)
+	if err != nil {
+		return nil, err
+	}
 	vm := &kubevirtv1.VirtualMachine{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "kubevirt.io/v1",
 			Kind:       "VirtualMachine",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "dcm-",
-			Namespace:    m.namespace,
+			Name:      virtualMachineName(vmID),
+			Namespace: m.namespace,
 			Labels: map[string]string{
 				constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
 				constants.DCMLabelInstanceID: vmID,
 			},
 		},
 		Spec: kubevirtv1.VirtualMachineSpec{
-			RunStrategy: &runStrategy,
+			RunStrategy:         &runStrategy,
+			DataVolumeTemplates: dataVolumeTemplates,
 			Template: &kubevirtv1.VirtualMachineInstanceTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: map[string]string{
@@ -53,50 +390,117 @@ func (m *Mapper) VMSpecToVirtualMachine(vmSpec *types.VMSpec, vmID string) (*kub
 				},
 				Spec: kubevirtv1.VirtualMachineInstanceSpec{
 					Domain: kubevirtv1.DomainSpec{
-						Devices:   m.buildDevices(vmSpec),
-						Resources: m.buildResources(vmSpec),
-						Machine: &kubevirtv1.Machine{
-							Type: "q35",
-						},
+						Devices:   m.buildDevices(disks),
+						Resources: resources,
+						Memory:    memory,
+						Machine:   machine,
 					},
-					Networks: m.buildNetworks(),
-					Volumes:  m.buildVolumes(vmSpec),
+					Networks:          m.buildNetworks(),
+					Volumes:           volumes,
+					AccessCredentials: accessCredentials,
 				},
 			},
 		},
 	}
 
+	gpus, err := buildGPUs(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+	vm.Spec.Template.Spec.Domain.Devices.GPUs = gpus
+
+	if err := m.applyCPUModel(vmSpec, vmID, &vm.Spec.Template.Spec); err != nil {
+		return nil, err
+	}
+	if err := m.applyInterfaceModel(vmSpec, &vm.Spec.Template.Spec); err != nil {
+		return nil, err
+	}
+	if err := m.applyTPM(vmSpec, &vm.Spec.Template.Spec); err != nil {
+		return nil, err
+	}
+	if err := m.applyNodePool(vmSpec, vm); err != nil {
+		return nil, err
+	}
+	if err := m.applySchedulerName(vmSpec, &vm.Spec.Template.Spec); err != nil {
+		return nil, err
+	}
+	annotateSSHPublicKey(vmSpec, vm)
+	if err := m.applySubdomain(vmSpec, vm.Spec.Template); err != nil {
+		return nil, err
+	}
+	if err := m.applySerialChannels(vmSpec, vm.Spec.Template); err != nil {
+		return nil, err
+	}
+	if err := m.applyShareableDisks(vm, backends); err != nil {
+		return nil, err
+	}
+	if err := m.applyMaintenanceProfile(vm, backends); err != nil {
+		return nil, err
+	}
+
 	return vm, nil
 }
 
+// normalizeRunStrategy migrates the legacy spec.running field to the equivalent
+// RunStrategy. KubeVirt rejects objects that set both, so running is always cleared.
+func normalizeRunStrategy(vm *kubevirtv1.VirtualMachine) {
+	if vm == nil || vm.Spec.Running == nil {
+		return
+	}
+	if vm.Spec.RunStrategy == nil {
+		runStrategy := kubevirtv1.RunStrategyHalted
+		if *vm.Spec.Running {
+			runStrategy = kubevirtv1.RunStrategyAlways
+		}
+		vm.Spec.RunStrategy = &runStrategy
+	}
+	vm.Spec.Running = nil
+}
+
 // buildDevices creates the device specification
-func (m *Mapper) buildDevices(vmSpec *types.VMSpec) kubevirtv1.Devices {
+func (m *Mapper) buildDevices(disks []kubevirtv1.Disk) kubevirtv1.Devices {
 	return kubevirtv1.Devices{
-		Disks:      m.buildDisks(vmSpec),
+		Disks:      disks,
 		Interfaces: m.buildInterfaces(),
 	}
 }
 
-// buildResources creates the resource specification
-func (m *Mapper) buildResources(vmSpec *types.VMSpec) kubevirtv1.ResourceRequirements {
-	requests := k8sv1.ResourceList{
-		k8sv1.ResourceCPU: resource.MustParse(fmt.Sprintf("%d", vmSpec.Vcpu.Count)),
+// buildResources creates the resource specification and the guest memory. The
+// pod memory request is the configured fraction of the guest memory.
+func (m *Mapper) buildResources(vmSpec *types.VMSpec) (kubevirtv1.ResourceRequirements, *kubevirtv1.Memory, error) {
+	guest, request, err := m.memoryResources(vmSpec.Memory.Size)
+	if err != nil {
+		return kubevirtv1.ResourceRequirements{}, nil, err
 	}
-
-	if memorySize, err := m.parseMemorySize(vmSpec.Memory.Size); err == nil {
-		requests[k8sv1.ResourceMemory] = resource.MustParse(memorySize)
+	requests := k8sv1.ResourceList{
+		k8sv1.ResourceCPU:    resource.MustParse(fmt.Sprintf("%d", vmSpec.Vcpu.Count)),
+		k8sv1.ResourceMemory: request,
 	}
 
 	return kubevirtv1.ResourceRequirements{
 		Requests: requests,
+	}, &kubevirtv1.Memory{Guest: &guest}, nil
+}
+
+// bootDiskName returns the name of the disk that carries the guest OS image
+func bootDiskName(vmSpec *types.VMSpec) string {
+	if len(vmSpec.Storage.Disks) > 0 {
+		return vmSpec.Storage.Disks[0].Name
 	}
+	return "boot"
 }
 
-// buildDisks creates the disk specifications
-func (m *Mapper) buildDisks(vmSpec *types.VMSpec) []kubevirtv1.Disk {
+// buildDisks creates the disk specifications. When container-disk layers are
+// requested they take the place of the first disk, in declaration order.
+func (m *Mapper) buildDisks(vmSpec *types.VMSpec, layers []containerDiskLayer) []kubevirtv1.Disk {
 	var disks []kubevirtv1.Disk
 
 	for i, disk := range vmSpec.Storage.Disks {
+		if i == 0 && len(layers) > 0 {
+			disks = append(disks, m.buildLayerDisks(layers)...)
+			continue
+		}
+
 		d := kubevirtv1.Disk{
 			Name: disk.Name,
 			DiskDevice: kubevirtv1.DiskDevice{
@@ -117,6 +521,9 @@ func (m *Mapper) buildDisks(vmSpec *types.VMSpec) []kubevirtv1.Disk {
 
 	// If no disks defined, create a default boot disk
 	if len(disks) == 0 {
+		if len(layers) > 0 {
+			return m.buildLayerDisks(layers)
+		}
 		bootOrder := uint(1)
 		disks = append(disks, kubevirtv1.Disk{
 			Name: "boot",
@@ -132,27 +539,80 @@ func (m *Mapper) buildDisks(vmSpec *types.VMSpec) []kubevirtv1.Disk {
 	return disks
 }
 
-// buildVolumes creates the volume specifications
-func (m *Mapper) buildVolumes(vmSpec *types.VMSpec) []kubevirtv1.Volume {
+// buildLayerDisks creates one disk per container-disk layer. Bootable layers are
+// assigned boot orders in declaration order.
+func (m *Mapper) buildLayerDisks(layers []containerDiskLayer) []kubevirtv1.Disk {
+	disks := make([]kubevirtv1.Disk, 0, len(layers))
+	nextBootOrder := uint(1)
+	for _, layer := range layers {
+		d := kubevirtv1.Disk{
+			Name: layer.Name,
+			DiskDevice: kubevirtv1.DiskDevice{
+				Disk: &kubevirtv1.DiskTarget{
+					Bus: kubevirtv1.DiskBusVirtio,
+				},
+			},
+		}
+		if layer.Bootable {
+			bootOrder := nextBootOrder
+			d.BootOrder = &bootOrder
+			nextBootOrder++
+		}
+		disks = append(disks, d)
+	}
+	return disks
+}
+
+// buildVolumes creates the volume specifications, matching the disk order
+// produced by buildDisks
+func (m *Mapper) buildVolumes(vmSpec *types.VMSpec, vmID string, layers []containerDiskLayer, backends map[string]diskStorage) ([]kubevirtv1.Volume, error) {
 	var volumes []kubevirtv1.Volume
 
 	for i, disk := range vmSpec.Storage.Disks {
+		if i == 0 && len(layers) > 0 {
+			volumes = append(volumes, m.buildLayerVolumes(layers)...)
+			continue
+		}
+
 		vol := kubevirtv1.Volume{
 			Name: disk.Name,
 		}
 
-		// For boot disk, use container disk for OS images
-		if i == 0 || disk.Name == "boot" {
+		backend := backends[disk.Name].Backend
+		if backend == "" {
+			backend = defaultDiskBackend(isBootDisk(i, disk))
+		}
+		switch backend {
+		case diskBackendContainerDisk:
+			// For boot disk, use container disk for OS images
 			vol.VolumeSource = kubevirtv1.VolumeSource{
 				ContainerDisk: &kubevirtv1.ContainerDiskSource{
 					Image: m.getContainerDiskImage(vmSpec.GuestOs),
 				},
 			}
-		} else {
-			// For data disks, create empty disk with default size
+		case diskBackendPersistentVolumeClaim:
+			vol.VolumeSource = kubevirtv1.VolumeSource{
+				PersistentVolumeClaim: &kubevirtv1.PersistentVolumeClaimVolumeSource{
+					PersistentVolumeClaimVolumeSource: k8sv1.PersistentVolumeClaimVolumeSource{
+						ClaimName: diskClaimName(vmID, disk.Name),
+					},
+				},
+			}
+		case diskBackendDataVolume:
+			vol.VolumeSource = kubevirtv1.VolumeSource{
+				DataVolume: &kubevirtv1.DataVolumeSource{
+					Name: backends[disk.Name].dataVolumeName(vmID, disk.Name),
+				},
+			}
+		default:
+			// For data disks, create empty disk with the requested size
+			capacity, err := m.diskCapacity(disk)
+			if err != nil {
+				return nil, err
+			}
 			vol.VolumeSource = kubevirtv1.VolumeSource{
 				EmptyDisk: &kubevirtv1.EmptyDiskSource{
-					Capacity: resource.MustParse("10Gi"),
+					Capacity: capacity,
 				},
 			}
 		}
@@ -162,6 +622,9 @@ func (m *Mapper) buildVolumes(vmSpec *types.VMSpec) []kubevirtv1.Volume {
 
 	// If no volumes defined, create a default boot volume
 	if len(volumes) == 0 {
+		if len(layers) > 0 {
+			return m.buildLayerVolumes(layers), nil
+		}
 		volumes = append(volumes, kubevirtv1.Volume{
 			Name: "boot",
 			VolumeSource: kubevirtv1.VolumeSource{
@@ -172,6 +635,22 @@ func (m *Mapper) buildVolumes(vmSpec *types.VMSpec) []kubevirtv1.Volume {
 		})
 	}
 
+	return volumes, nil
+}
+
+// buildLayerVolumes creates one container-disk volume per layer
+func (m *Mapper) buildLayerVolumes(layers []containerDiskLayer) []kubevirtv1.Volume {
+	volumes := make([]kubevirtv1.Volume, 0, len(layers))
+	for _, layer := range layers {
+		volumes = append(volumes, kubevirtv1.Volume{
+			Name: layer.Name,
+			VolumeSource: kubevirtv1.VolumeSource{
+				ContainerDisk: &kubevirtv1.ContainerDiskSource{
+					Image: layer.Image,
+				},
+			},
+		})
+	}
 	return volumes
 }
 
@@ -194,7 +673,7 @@ func (m *Mapper) buildInterfaces() []kubevirtv1.Interface {
 	return []kubevirtv1.Interface{
 		{
 			Name:  "default",
-			Model: "virtio",
+			Model: DefaultInterfaceModel,
 			InterfaceBindingMethod: kubevirtv1.InterfaceBindingMethod{
 				Masquerade: &kubevirtv1.InterfaceMasquerade{},
 			},
@@ -202,62 +681,173 @@ func (m *Mapper) buildInterfaces() []kubevirtv1.Interface {
 	}
 }
 
-// getContainerDiskImage maps guest OS to container disk image
+// withGuestOS returns the spec with the default guest OS filled in when the
+// request omits it, or an error when the mapper requires an explicit guest OS.
+// The caller's spec is left untouched.
+func (m *Mapper) withGuestOS(vmSpec *types.VMSpec, vmID string) (*types.VMSpec, error) {
+	if strings.TrimSpace(vmSpec.GuestOs.Type) != "" {
+		return vmSpec, nil
+	}
+	if m.requireGuestOS {
+		return nil, fmt.Errorf("guest_os.type is required")
+	}
+	zap.S().Warnw("Guest OS omitted, using the default guest OS",
+		"vm_id", vmID, "guest_os", m.defaultGuestOS)
+	defaulted := *vmSpec
+	defaulted.GuestOs.Type = m.defaultGuestOS
+	return &defaulted, nil
+}
+
+// guestOSImages are the container disk images of the guest OS types the
+// provider has built-in images for
+var guestOSImages = map[string]string{
+	"ubuntu": "quay.io/kubevirt/ubuntu-container-disk-demo:latest",
+	"centos": "quay.io/kubevirt/centos-container-disk-demo:latest",
+	"fedora": "quay.io/kubevirt/fedora-container-disk-demo:latest",
+	"cirros": "quay.io/kubevirt/cirros-container-disk-demo:latest",
+}
+
+// getContainerDiskImage maps guest OS to container disk image, falling back
+// to cirros for unknown types
 func (m *Mapper) getContainerDiskImage(guestOS types.GuestOS) string {
-	switch strings.ToLower(guestOS.Type) {
-	case "ubuntu":
-		return "quay.io/kubevirt/ubuntu-container-disk-demo:latest"
-	case "centos":
-		return "quay.io/kubevirt/centos-container-disk-demo:latest"
-	case "fedora":
-		return "quay.io/kubevirt/fedora-container-disk-demo:latest"
-	case "cirros":
-		return "quay.io/kubevirt/cirros-container-disk-demo:latest"
-	default:
-		return "quay.io/kubevirt/cirros-container-disk-demo:latest"
+	if image, ok := guestOSImages[strings.ToLower(guestOS.Type)]; ok {
+		return image
 	}
+	return guestOSImages["cirros"]
 }
 
-// parseMemorySize converts memory size string to Kubernetes resource format
+// memoryUnits maps user-facing memory unit suffixes to Kubernetes quantity
+// suffixes. Memory is sized in binary units, so GB and MB are read as Gi and Mi.
+// Binary units are listed first so that "GiB" is not mistaken for "B".
+var memoryUnits = []struct {
+	suffix string
+	unit   string
+}{
+	{"KIB", "Ki"},
+	{"MIB", "Mi"},
+	{"GIB", "Gi"},
+	{"TIB", "Ti"},
+	{"KB", "Ki"},
+	{"MB", "Mi"},
+	{"GB", "Gi"},
+	{"TB", "Ti"},
+}
+
+// parseMemorySize converts a memory size string to a canonical Kubernetes
+// quantity string such as "2Gi". Plain numbers are sizes in Mi, and Kubernetes
+// quantities are kept as they are, so VirtualMachineToVMSpec reports the same string.
 func (m *Mapper) parseMemorySize(sizeStr string) (string, error) {
+	quantity, err := parseMemoryQuantity(sizeStr)
+	if err != nil {
+		return "", err
+	}
+	return quantity.String(), nil
+}
+
+// parseMemoryQuantity parses a memory size string into a Kubernetes quantity
+func parseMemoryQuantity(sizeStr string) (resource.Quantity, error) {
 	sizeStr = strings.TrimSpace(sizeStr)
 
-	// First try to parse as a valid Kubernetes quantity
-	if quantity, err := resource.ParseQuantity(sizeStr); err == nil {
-		return quantity.String(), nil
+	// A plain number is a size in Mi rather than a byte count
+	if _, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
+		sizeStr += "Mi"
 	}
 
-	// Handle common non-Kubernetes formats
-	upperStr := strings.ToUpper(sizeStr)
-
-	// Convert decimal GB to Gi
-	if strings.HasSuffix(upperStr, "GB") {
-		numStr := strings.TrimSuffix(upperStr, "GB")
-		num, err := strconv.ParseFloat(numStr, 64)
-		if err != nil {
-			return "", fmt.Errorf("invalid GB value: %s", numStr)
+	quantity, err := resource.ParseQuantity(sizeStr)
+	if err != nil {
+		upperStr := strings.ToUpper(sizeStr)
+		parsed := false
+		for _, u := range memoryUnits {
+			if !strings.HasSuffix(upperStr, u.suffix) {
+				continue
+			}
+			numStr := strings.TrimSpace(sizeStr[:len(sizeStr)-len(u.suffix)])
+			if quantity, err = resource.ParseQuantity(numStr + u.unit); err != nil {
+				return resource.Quantity{}, fmt.Errorf("invalid %s value: %s", u.suffix, numStr)
+			}
+			parsed = true
+			break
+		}
+		if !parsed {
+			return resource.Quantity{}, fmt.Errorf("unable to parse memory size: %s", sizeStr)
 		}
-		giValue := num * 1000 * 1000 * 1000 / (1024 * 1024 * 1024)
-		return resource.NewQuantity(int64(giValue*1024*1024*1024), resource.BinarySI).String(), nil
 	}
 
-	// Convert decimal MB to Mi
-	if strings.HasSuffix(upperStr, "MB") {
-		numStr := strings.TrimSuffix(upperStr, "MB")
-		num, err := strconv.ParseFloat(numStr, 64)
-		if err != nil {
-			return "", fmt.Errorf("invalid MB value: %s", numStr)
-		}
-		miValue := num * 1000 * 1000 / (1024 * 1024)
-		return resource.NewQuantity(int64(miValue*1024*1024), resource.BinarySI).String(), nil
+	if quantity.Sign() <= 0 {
+		return resource.Quantity{}, fmt.Errorf("memory size must be positive: %s", sizeStr)
 	}
+	return quantity, nil
+}
 
-	// If just a number, assume Mi
-	if num, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
-		return resource.NewQuantity(num*1024*1024, resource.BinarySI).String(), nil
+// storageUnits maps user-facing storage unit suffixes to Kubernetes quantity suffixes.
+// Binary units are listed first so that "GiB" is not mistaken for "B".
+var storageUnits = []struct {
+	suffix string
+	unit   string
+}{
+	{"KIB", "Ki"},
+	{"MIB", "Mi"},
+	{"GIB", "Gi"},
+	{"TIB", "Ti"},
+	{"KB", "k"},
+	{"MB", "M"},
+	{"GB", "G"},
+	{"TB", "T"},
+}
+
+// parseStorageSize converts a disk capacity string to a Kubernetes quantity.
+// Decimal units (GB, TB) and binary units (GiB, TiB) are honored as written, and
+// the result is rounded up to the configured storage allocation granularity.
+func (m *Mapper) parseStorageSize(sizeStr string) (resource.Quantity, error) {
+	quantity, err := parseStorageQuantity(sizeStr)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+
+	return m.roundToGranularity(quantity), nil
+}
+
+// roundToGranularity rounds a capacity up to the storage allocation granularity
+func (m *Mapper) roundToGranularity(quantity resource.Quantity) resource.Quantity {
+	granularity := m.storageGranularity.Value()
+	if granularity <= 0 {
+		return quantity
+	}
+	bytes := quantity.Value()
+	if rem := bytes % granularity; rem != 0 {
+		bytes += granularity - rem
+	}
+	return *resource.NewQuantity(bytes, resource.BinarySI)
+}
+
+// parseStorageQuantity parses a disk capacity string into a Kubernetes quantity
+func parseStorageQuantity(sizeStr string) (resource.Quantity, error) {
+	sizeStr = strings.TrimSpace(sizeStr)
+
+	quantity, err := resource.ParseQuantity(sizeStr)
+	if err != nil {
+		upperStr := strings.ToUpper(sizeStr)
+		parsed := false
+		for _, u := range storageUnits {
+			if !strings.HasSuffix(upperStr, u.suffix) {
+				continue
+			}
+			numStr := strings.TrimSpace(sizeStr[:len(sizeStr)-len(u.suffix)])
+			if quantity, err = resource.ParseQuantity(numStr + u.unit); err != nil {
+				return resource.Quantity{}, fmt.Errorf("invalid %s value: %s", u.suffix, numStr)
+			}
+			parsed = true
+			break
+		}
+		if !parsed {
+			return resource.Quantity{}, fmt.Errorf("unable to parse storage size: %s", sizeStr)
+		}
 	}
 
-	return "", fmt.Errorf("unable to parse memory size: %s", sizeStr)
+	if quantity.Sign() <= 0 {
+		return resource.Quantity{}, fmt.Errorf("storage size must be positive: %s", sizeStr)
+	}
+	return quantity, nil
 }
 
 // VirtualMachineToVMSpec converts a typed KubeVirt VirtualMachine back to DCM VMSpec format
@@ -278,7 +868,7 @@ func (m *Mapper) VirtualMachineToVMSpec(vm *kubevirtv1.VirtualMachine) (*types.V
 	}
 
 	// Extract memory information
-	if memQty, ok := domain.Resources.Requests[k8sv1.ResourceMemory]; ok {
+	if memQty, ok := guestMemory(&domain); ok {
 		vmSpec.Memory = types.Memory{Size: memQty.String()}
 	}
 
@@ -291,15 +881,41 @@ func (m *Mapper) VirtualMachineToVMSpec(vm *kubevirtv1.VirtualMachine) (*types.V
 	}
 	vmSpec.GuestOs = types.GuestOS{Type: guestOS}
 
-	// Extract disk information
+	// Extract disk information, skipping the provider-managed cloud-init and scratch disks
 	var disks []types.Disk
 	for _, d := range domain.Devices.Disks {
+		if d.Name == cloudInitVolumeName || d.Name == scratchDiskName {
+			continue
+		}
 		disks = append(disks, types.Disk{Name: d.Name})
 	}
 	if len(disks) == 0 {
 		disks = append(disks, types.Disk{Name: "boot"})
 	}
 	vmSpec.Storage = types.Storage{Disks: disks}
+	vmSpec.Access = accessFromVirtualMachine(vm)
+
+	// Preserve persistent disk backends, serial channels and the TPM so the spec round-trips
+	hints := map[string]interface{}{}
+	if backends := diskStorageFromVirtualMachine(vm); len(backends) > 0 {
+		hints[diskStorageHint] = backends
+	}
+	if channels := vm.Spec.Template.ObjectMeta.Annotations[constants.DCMAnnotationSerialChannels]; channels != "" {
+		hints[serialChannelsHint] = strings.Split(channels, ",")
+	}
+	if mode := tpmModeFromVirtualMachine(vm); mode != "" {
+		hints[tpmHint] = string(mode)
+	}
+	if len(hints) > 0 {
+		vmSpec.ProviderHints = &types.ProviderHints{providerHintsKey: hints}
+	}
+
+	if status := virtualMachineStatus(vm); status != "" {
+		vmSpec.Status = &status
+	}
+	if _, message := VirtualMachineStatusReason(vm); message != "" {
+		vmSpec.StatusMessage = &message
+	}
 
 	return vmSpec, nil
 }
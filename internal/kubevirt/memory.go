@@ -0,0 +1,45 @@
+package kubevirt
+
+import (
+	"fmt"
+	"math"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// ValidateMemoryRequestRatio checks the fraction of the guest memory that VM
+// launcher pods request. It must be above zero and at most one: the request
+// never exceeds what the guest can use.
+func ValidateMemoryRequestRatio(ratio float64) error {
+	if ratio <= 0 || ratio > 1 || math.IsNaN(ratio) {
+		return fmt.Errorf("invalid memory request ratio %v: must be above 0 and at most 1", ratio)
+	}
+	return nil
+}
+
+// memoryResources parses a requested memory size into the memory the guest
+// sees and the memory its launcher pod requests from the scheduler
+func (m *Mapper) memoryResources(size string) (guest, request resource.Quantity, err error) {
+	memorySize, err := m.parseMemorySize(size)
+	if err != nil {
+		return resource.Quantity{}, resource.Quantity{}, err
+	}
+	guest = resource.MustParse(memorySize)
+	if m.memoryRequestRatio <= 0 || m.memoryRequestRatio >= 1 {
+		return guest, guest.DeepCopy(), nil
+	}
+	bytes := int64(math.Ceil(float64(guest.Value()) * m.memoryRequestRatio))
+	return guest, *resource.NewQuantity(bytes, resource.BinarySI), nil
+}
+
+// guestMemory returns the memory the guest of a VM sees. VMs created before
+// guest memory was set explicitly fall back to their memory request.
+func guestMemory(domain *kubevirtv1.DomainSpec) (resource.Quantity, bool) {
+	if domain.Memory != nil && domain.Memory.Guest != nil {
+		return *domain.Memory.Guest, true
+	}
+	quantity, ok := domain.Resources.Requests[k8sv1.ResourceMemory]
+	return quantity, ok
+}
@@ -0,0 +1,72 @@
+package kubevirt
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// interfaceModelHint selects the emulated NIC model of a VM, overriding the
+// default of its guest OS
+const interfaceModelHint = "interface_model"
+
+// DefaultInterfaceModel is the NIC model of guests without a configured default
+const DefaultInterfaceModel = kubevirtv1.VirtIO
+
+// interfaceModels are the NIC models KubeVirt can emulate. Guests without
+// virtio drivers, such as older Windows releases, need e1000e or rtl8139.
+var interfaceModels = []string{"e1000", "e1000e", "igb", "ne2k_pci", "pcnet", "rtl8139", kubevirtv1.VirtIO}
+
+// ParseInterfaceModel validates a NIC model
+func ParseInterfaceModel(s string) (string, error) {
+	model := strings.ToLower(s)
+	if !slices.Contains(interfaceModels, model) {
+		return "", fmt.Errorf("unsupported interface model %q: must be one of %s", s, strings.Join(interfaceModels, ", "))
+	}
+	return model, nil
+}
+
+// ParseInterfaceModels validates the default NIC models of guest OS types
+func ParseInterfaceModels(models map[string]string) (map[string]string, error) {
+	parsed := make(map[string]string, len(models))
+	for guestOS, s := range models {
+		model, err := ParseInterfaceModel(s)
+		if err != nil {
+			return nil, fmt.Errorf("guest OS %s: %w", guestOS, err)
+		}
+		parsed[strings.ToLower(guestOS)] = model
+	}
+	return parsed, nil
+}
+
+// interfaceModel resolves the NIC model of a VM, preferring the
+// interface_model provider hint over the default of its guest OS
+func (m *Mapper) interfaceModel(vmSpec *types.VMSpec) (string, error) {
+	var model string
+	if _, err := decodeHint(vmSpec, interfaceModelHint, &model); err != nil {
+		return "", err
+	}
+	if model != "" {
+		return ParseInterfaceModel(model)
+	}
+	if model, ok := m.interfaceModels[strings.ToLower(vmSpec.GuestOs.Type)]; ok {
+		return model, nil
+	}
+	return DefaultInterfaceModel, nil
+}
+
+// applyInterfaceModel sets the NIC model of every interface of the VM
+func (m *Mapper) applyInterfaceModel(vmSpec *types.VMSpec, spec *kubevirtv1.VirtualMachineInstanceSpec) error {
+	model, err := m.interfaceModel(vmSpec)
+	if err != nil {
+		return err
+	}
+	for i := range spec.Domain.Devices.Interfaces {
+		spec.Domain.Devices.Interfaces[i].Model = model
+	}
+	return nil
+}
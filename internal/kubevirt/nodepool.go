@@ -0,0 +1,181 @@
+package kubevirt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// nodePoolHint selects the node pool for a single VM, replacing the configured pool
+const nodePoolHint = "node_pool"
+
+// ErrNodePoolUnavailable is returned when no node in the cluster can satisfy the
+// requested node pool.
+var ErrNodePoolUnavailable = errors.New("node pool unavailable")
+
+// NodePool describes a set of nodes reserved for VM workloads. RuntimeClass names
+// a RuntimeClass whose scheduling constraints are applied to the VM, NodeSelector
+// adds node labels to match and Resources requests extended resources such as
+// devices.kubevirt.io/kvm.
+type NodePool struct {
+	RuntimeClass string            `json:"runtime_class,omitempty"`
+	NodeSelector map[string]string `json:"node_selector,omitempty"`
+	Resources    map[string]string `json:"resources,omitempty"`
+}
+
+// isZero reports whether the pool places no constraints on scheduling
+func (p NodePool) isZero() bool {
+	return p.RuntimeClass == "" && len(p.NodeSelector) == 0 && len(p.Resources) == 0
+}
+
+// applyNodePool applies the configured or hinted node pool to the VM. Runtime
+// class scheduling is resolved against the cluster by Client.ResolveNodePool.
+func (m *Mapper) applyNodePool(vmSpec *types.VMSpec, vm *kubevirtv1.VirtualMachine) error {
+	// The hint merges into copies, so that it never changes the defaults of
+	// later VMs
+	pool := NodePool{
+		RuntimeClass: m.nodePool.RuntimeClass,
+		NodeSelector: maps.Clone(m.nodePool.NodeSelector),
+		Resources:    maps.Clone(m.nodePool.Resources),
+	}
+	if _, err := decodeHint(vmSpec, nodePoolHint, &pool); err != nil {
+		return err
+	}
+	if pool.isZero() {
+		return nil
+	}
+
+	spec := &vm.Spec.Template.Spec
+	if len(pool.NodeSelector) > 0 {
+		if spec.NodeSelector == nil {
+			spec.NodeSelector = make(map[string]string, len(pool.NodeSelector))
+		}
+		for k, v := range pool.NodeSelector {
+			spec.NodeSelector[k] = v
+		}
+	}
+
+	for name, value := range pool.Resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil || quantity.Sign() <= 0 {
+			return fmt.Errorf("invalid node pool resource %s: %q", name, value)
+		}
+		// Extended resources cannot be overcommitted, so requests must equal limits
+		resources := &spec.Domain.Resources
+		resources.Requests[k8sv1.ResourceName(name)] = quantity
+		if resources.Limits == nil {
+			resources.Limits = k8sv1.ResourceList{}
+		}
+		resources.Limits[k8sv1.ResourceName(name)] = quantity
+	}
+
+	if pool.RuntimeClass != "" {
+		if vm.Annotations == nil {
+			vm.Annotations = map[string]string{}
+		}
+		vm.Annotations[constants.DCMAnnotationRuntimeClass] = pool.RuntimeClass
+	}
+	return nil
+}
+
+// isExtendedResource reports whether name is an extended resource, i.e. a
+// domain-prefixed resource outside the kubernetes.io namespace.
+func isExtendedResource(name k8sv1.ResourceName) bool {
+	return strings.Contains(string(name), "/") && !strings.Contains(string(name), "kubernetes.io/")
+}
+
+// ResolveNodePool applies the scheduling constraints of the VM's runtime class and
+// verifies that at least one node matches its node selector and has the
+// requested extended resources and GPU devices allocatable. If nodes cannot be listed the
+// discovery check is skipped.
+func (c *Client) ResolveNodePool(ctx context.Context, vm *kubevirtv1.VirtualMachine) error {
+	if vm.Spec.Template == nil {
+		return nil
+	}
+	spec := &vm.Spec.Template.Spec
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if name := vm.Annotations[constants.DCMAnnotationRuntimeClass]; name != "" {
+		rc, err := c.coreClient.NodeV1().RuntimeClasses().Get(timeoutCtx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: runtime class %q not found", ErrNodePoolUnavailable, name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get runtime class %q: %w", name, err)
+		}
+		if rc.Scheduling != nil {
+			for k, v := range rc.Scheduling.NodeSelector {
+				if existing, ok := spec.NodeSelector[k]; ok && existing != v {
+					return fmt.Errorf("%w: node selector %s=%s conflicts with runtime class %q", ErrNodePoolUnavailable, k, existing, name)
+				}
+				if spec.NodeSelector == nil {
+					spec.NodeSelector = map[string]string{}
+				}
+				spec.NodeSelector[k] = v
+			}
+			spec.Tolerations = append(spec.Tolerations, rc.Scheduling.Tolerations...)
+		}
+	}
+
+	extended := k8sv1.ResourceList{}
+	for name, quantity := range spec.Domain.Resources.Requests {
+		if isExtendedResource(name) {
+			extended[name] = quantity
+		}
+	}
+	// GPUs and vGPUs are claimed by device plugin resource name, one per device
+	for _, gpu := range spec.Domain.Devices.GPUs {
+		name := k8sv1.ResourceName(gpu.DeviceName)
+		count := extended[name]
+		count.Add(resource.MustParse("1"))
+		extended[name] = count
+	}
+	if len(spec.NodeSelector) == 0 && len(extended) == 0 {
+		return nil
+	}
+
+	nodes, err := c.coreClient.CoreV1().Nodes().List(timeoutCtx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(spec.NodeSelector).String(),
+	})
+	if apierrors.IsForbidden(err) {
+		zap.S().Warnw("Cannot list nodes, skipping node pool capability check", "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	for _, node := range nodes.Items {
+		if nodeHasResources(&node, extended) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no node matches selector %v with allocatable resources %v",
+		ErrNodePoolUnavailable, spec.NodeSelector, extended)
+}
+
+// nodeHasResources reports whether the node's allocatable covers each requested quantity
+func nodeHasResources(node *k8sv1.Node, requests k8sv1.ResourceList) bool {
+	for name, quantity := range requests {
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok || allocatable.Cmp(quantity) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/config"
+)
+
+// Policy is the default config-driven create policy. Zero-valued limits are
+// not enforced.
+type Policy struct {
+	allowedGuestOS  []string
+	maxVCPU         int
+	maxMemory       resource.Quantity
+	maxDiskCapacity resource.Quantity
+	requiredLabels  []string
+}
+
+// NewPolicy builds the create policy from configuration
+func NewPolicy(cfg *config.PolicyConfig) (*Policy, error) {
+	p := &Policy{
+		maxVCPU:        cfg.MaxVCPU,
+		requiredLabels: cfg.RequiredLabels,
+	}
+	for _, guestOS := range cfg.AllowedGuestOS {
+		p.allowedGuestOS = append(p.allowedGuestOS, strings.ToLower(strings.TrimSpace(guestOS)))
+	}
+	if cfg.MaxMemory != "" {
+		q, err := parseMemoryQuantity(cfg.MaxMemory)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max memory: %w", err)
+		}
+		p.maxMemory = q
+	}
+	if cfg.MaxDiskCapacity != "" {
+		q, err := parseStorageQuantity(cfg.MaxDiskCapacity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max disk capacity: %w", err)
+		}
+		p.maxDiskCapacity = q
+	}
+	return p, nil
+}
+
+// Check returns every way the VM spec violates the policy. A request without
+// a guest OS is not checked against the allowed list, since the configured
+// default guest OS applies to it.
+func (p *Policy) Check(_ context.Context, vmSpec *types.VMSpec) []string {
+	var violations []string
+
+	if len(p.allowedGuestOS) > 0 && vmSpec.GuestOs.Type != "" {
+		allowed := false
+		for _, guestOS := range p.allowedGuestOS {
+			if strings.EqualFold(vmSpec.GuestOs.Type, guestOS) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			violations = append(violations, fmt.Sprintf("guest OS %q is not allowed (allowed: %s)",
+				vmSpec.GuestOs.Type, strings.Join(p.allowedGuestOS, ", ")))
+		}
+	}
+
+	if p.maxVCPU > 0 && vmSpec.Vcpu.Count > p.maxVCPU {
+		violations = append(violations, fmt.Sprintf("vcpu count %d exceeds the maximum of %d", vmSpec.Vcpu.Count, p.maxVCPU))
+	}
+
+	// Malformed sizes are left for the mapper to reject
+	if !p.maxMemory.IsZero() {
+		if q, err := parseMemoryQuantity(vmSpec.Memory.Size); err == nil && q.Cmp(p.maxMemory) > 0 {
+			violations = append(violations, fmt.Sprintf("memory %s exceeds the maximum of %s", vmSpec.Memory.Size, p.maxMemory.String()))
+		}
+	}
+	if !p.maxDiskCapacity.IsZero() {
+		for _, disk := range vmSpec.Storage.Disks {
+			if disk.Capacity == "" {
+				continue
+			}
+			if q, err := parseStorageQuantity(disk.Capacity); err == nil && q.Cmp(p.maxDiskCapacity) > 0 {
+				violations = append(violations, fmt.Sprintf("disk %q capacity %s exceeds the maximum of %s",
+					disk.Name, disk.Capacity, p.maxDiskCapacity.String()))
+			}
+		}
+	}
+
+	var labels map[string]string
+	if vmSpec.Metadata.Labels != nil {
+		labels = *vmSpec.Metadata.Labels
+	}
+	for _, key := range p.requiredLabels {
+		if labels[key] == "" {
+			violations = append(violations, fmt.Sprintf("required metadata label %q is missing", key))
+		}
+	}
+
+	return violations
+}
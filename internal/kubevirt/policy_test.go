@@ -0,0 +1,124 @@
+package kubevirt_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/config"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+var _ = Describe("Policy", func() {
+	var vmSpec *v1alpha1.VMSpec
+
+	BeforeEach(func() {
+		vmSpec = &v1alpha1.VMSpec{
+			ServiceType: v1alpha1.Vm,
+			Metadata: v1alpha1.ServiceMetadata{
+				Name:   "web-01",
+				Labels: &map[string]string{"team": "infra"},
+			},
+			GuestOs: v1alpha1.GuestOS{Type: "Fedora"},
+			Vcpu:    v1alpha1.Vcpu{Count: 2},
+			Memory:  v1alpha1.Memory{Size: "4GB"},
+			Storage: v1alpha1.Storage{
+				Disks: []v1alpha1.Disk{
+					{Name: "boot", Capacity: "20GB"},
+					{Name: "data", Capacity: "100Gi"},
+				},
+			},
+		}
+	})
+
+	newPolicy := func(cfg config.PolicyConfig) *kubevirt.Policy {
+		p, err := kubevirt.NewPolicy(&cfg)
+		Expect(err).NotTo(HaveOccurred())
+		return p
+	}
+
+	It("should allow anything without limits", func() {
+		Expect(newPolicy(config.PolicyConfig{}).Check(context.Background(), vmSpec)).To(BeEmpty())
+	})
+
+	It("should allow a VM within every limit", func() {
+		p := newPolicy(config.PolicyConfig{
+			AllowedGuestOS:  []string{"fedora", "ubuntu"},
+			MaxVCPU:         2,
+			MaxMemory:       "4Gi",
+			MaxDiskCapacity: "100Gi",
+			RequiredLabels:  []string{"team"},
+		})
+		Expect(p.Check(context.Background(), vmSpec)).To(BeEmpty())
+	})
+
+	It("should report every violation", func() {
+		p := newPolicy(config.PolicyConfig{
+			AllowedGuestOS:  []string{"ubuntu"},
+			MaxVCPU:         1,
+			MaxMemory:       "2Gi",
+			MaxDiskCapacity: "50Gi",
+			RequiredLabels:  []string{"team", "cost-center"},
+		})
+
+		Expect(p.Check(context.Background(), vmSpec)).To(ConsistOf(
+			`guest OS "Fedora" is not allowed (allowed: ubuntu)`,
+			"vcpu count 2 exceeds the maximum of 1",
+			"memory 4GB exceeds the maximum of 2Gi",
+			`disk "data" capacity 100Gi exceeds the maximum of 50Gi`,
+			`required metadata label "cost-center" is missing`,
+		))
+	})
+
+	It("should not check an omitted guest OS against the allowed list", func() {
+		vmSpec.GuestOs.Type = ""
+		p := newPolicy(config.PolicyConfig{AllowedGuestOS: []string{"ubuntu"}})
+		Expect(p.Check(context.Background(), vmSpec)).To(BeEmpty())
+	})
+
+	It("should reject malformed limits", func() {
+		_, err := kubevirt.NewPolicy(&config.PolicyConfig{MaxMemory: "lots"})
+		Expect(err).To(MatchError(ContainSubstring("max memory")))
+		_, err = kubevirt.NewPolicy(&config.PolicyConfig{MaxDiskCapacity: "lots"})
+		Expect(err).To(MatchError(ContainSubstring("max disk capacity")))
+	})
+})
+
+var _ = Describe("Capabilities", func() {
+	It("should reflect the configured limits and enabled features", func() {
+		policy, err := kubevirt.NewPolicy(&config.PolicyConfig{
+			AllowedGuestOS:  []string{"Fedora", "ubuntu"},
+			MaxVCPU:         8,
+			MaxMemory:       "32Gi",
+			MaxDiskCapacity: "500Gi",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		mapper := kubevirt.NewMapper("default",
+			kubevirt.SetMaintenanceReady(true),
+			kubevirt.SetSSHKeyPropagation(kubevirt.SSHKeyPropagationQemuGuestAgent),
+		)
+
+		c := kubevirt.NewCapabilities(mapper, policy)
+		Expect(c.GuestOS).To(Equal([]string{"fedora", "ubuntu"}))
+		Expect(c.MaxVCPU).To(Equal(8))
+		Expect(c.MaxMemory).To(Equal("32Gi"))
+		Expect(c.MaxDiskCapacity).To(Equal("500Gi"))
+		Expect(c.DefaultSSHKeyPropagation).To(Equal("qemu-guest-agent"))
+		Expect(c.SSHKeyPropagation).To(ConsistOf("nocloud", "qemu-guest-agent"))
+		Expect(c.PortServiceTypes).To(ConsistOf("NodePort", "LoadBalancer", "ClusterIP"))
+		Expect(c.DiskBuses).To(Equal([]string{"virtio"}))
+		Expect(c.LiveMigration).To(BeTrue())
+		Expect(c.Snapshots).To(BeFalse())
+	})
+
+	It("should list every built-in guest OS without limits by default", func() {
+		c := kubevirt.NewCapabilities(kubevirt.NewMapper("default"), nil)
+		Expect(c.GuestOS).To(Equal([]string{"centos", "cirros", "fedora", "ubuntu"}))
+		Expect(c.MaxVCPU).To(BeZero())
+		Expect(c.MaxMemory).To(BeEmpty())
+		Expect(c.DefaultSSHKeyPropagation).To(Equal("nocloud"))
+		Expect(c.LiveMigration).To(BeFalse())
+	})
+})
@@ -0,0 +1,190 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// exposePortsHint lists the guest ports published through a Service
+const exposePortsHint = "expose_ports"
+
+// sshServicePort is the Service port SSH is published on. It forwards to the
+// mapper's SSH target port unless a request sets the target port itself.
+const sshServicePort = 22
+
+// IsSSHServicePort reports whether a Service port publishes SSH, whichever
+// guest port it forwards to
+func IsSSHServicePort(port k8sv1.ServicePort) bool {
+	return port.Port == sshServicePort && (port.Protocol == "" || port.Protocol == k8sv1.ProtocolTCP)
+}
+
+// exposedPort is a single guest port declared in provider hints. Port is the
+// Service port, TargetPort the port the guest listens on (defaulting to Port)
+// and NodePort an optional fixed node port.
+type exposedPort struct {
+	Port       int32  `json:"port"`
+	TargetPort int32  `json:"target_port,omitempty"`
+	NodePort   int32  `json:"node_port,omitempty"`
+	Protocol   string `json:"protocol,omitempty"`
+	Type       string `json:"type,omitempty"`
+}
+
+// validPort reports whether p is a valid TCP/UDP port number
+func validPort(p int32) bool {
+	return p >= 1 && p <= 65535
+}
+
+// ValidateSSHTargetPort checks the guest port SSH is forwarded to
+func ValidateSSHTargetPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port %d out of range", port)
+	}
+	return nil
+}
+
+// PortServiceName returns the name of the Service exposing a VM's guest ports
+func PortServiceName(vmID string) string {
+	return virtualMachineName(vmID) + "-ports"
+}
+
+// ParseServiceType validates the type of the Service publishing guest ports:
+// NodePort, LoadBalancer for an external address on cloud providers, or
+// ClusterIP for in-cluster access only
+func ParseServiceType(s string) (k8sv1.ServiceType, error) {
+	switch strings.ToLower(s) {
+	case "nodeport":
+		return k8sv1.ServiceTypeNodePort, nil
+	case "loadbalancer":
+		return k8sv1.ServiceTypeLoadBalancer, nil
+	case "clusterip":
+		return k8sv1.ServiceTypeClusterIP, nil
+	default:
+		return "", fmt.Errorf("unsupported service type %q: must be NodePort, LoadBalancer or ClusterIP", s)
+	}
+}
+
+// exposedPorts returns the guest ports requested through provider hints.
+// Protocols default to TCP and service types to the mapper default; all ports
+// must share one service type since they are published by a single Service.
+func (m *Mapper) exposedPorts(vmSpec *types.VMSpec) ([]exposedPort, k8sv1.ServiceType, error) {
+	var ports []exposedPort
+	found, err := decodeHint(vmSpec, exposePortsHint, &ports)
+	if err != nil || !found || len(ports) == 0 {
+		return nil, "", err
+	}
+
+	var serviceType k8sv1.ServiceType
+	seen := make(map[string]bool, len(ports))
+	nodePorts := make(map[string]bool, len(ports))
+	for i := range ports {
+		if !validPort(ports[i].Port) {
+			return nil, "", fmt.Errorf("exposed port %d: port %d out of range", i, ports[i].Port)
+		}
+		if ports[i].TargetPort != 0 && !validPort(ports[i].TargetPort) {
+			return nil, "", fmt.Errorf("exposed port %d: target port %d out of range", i, ports[i].TargetPort)
+		}
+		if ports[i].NodePort != 0 && !validPort(ports[i].NodePort) {
+			return nil, "", fmt.Errorf("exposed port %d: node port %d out of range", i, ports[i].NodePort)
+		}
+
+		protocol := k8sv1.ProtocolTCP
+		if ports[i].Protocol != "" {
+			protocol = k8sv1.Protocol(strings.ToUpper(ports[i].Protocol))
+		}
+		switch protocol {
+		case k8sv1.ProtocolTCP, k8sv1.ProtocolUDP, k8sv1.ProtocolSCTP:
+		default:
+			return nil, "", fmt.Errorf("exposed port %d: unsupported protocol %q", i, ports[i].Protocol)
+		}
+		ports[i].Protocol = string(protocol)
+
+		portType := m.portServiceType
+		if ports[i].Type != "" {
+			if portType, err = ParseServiceType(ports[i].Type); err != nil {
+				return nil, "", fmt.Errorf("exposed port %d: %w", i, err)
+			}
+		}
+		if serviceType != "" && portType != serviceType {
+			return nil, "", fmt.Errorf("provider hint %s must use a single service type", exposePortsHint)
+		}
+		serviceType = portType
+		if ports[i].NodePort != 0 && portType == k8sv1.ServiceTypeClusterIP {
+			return nil, "", fmt.Errorf("exposed port %d: node port requires a NodePort or LoadBalancer service", i)
+		}
+
+		if ports[i].TargetPort == 0 {
+			ports[i].TargetPort = ports[i].Port
+			if ports[i].Port == sshServicePort && protocol == k8sv1.ProtocolTCP {
+				ports[i].TargetPort = m.sshTargetPort
+			}
+		}
+
+		key := fmt.Sprintf("%s/%d", protocol, ports[i].Port)
+		if seen[key] {
+			return nil, "", fmt.Errorf("exposed port %d: duplicate port %s", i, key)
+		}
+		seen[key] = true
+		if ports[i].NodePort != 0 {
+			nodeKey := fmt.Sprintf("%s/%d", protocol, ports[i].NodePort)
+			if nodePorts[nodeKey] {
+				return nil, "", fmt.Errorf("exposed port %d: duplicate node port %s", i, nodeKey)
+			}
+			nodePorts[nodeKey] = true
+		}
+	}
+	return ports, serviceType, nil
+}
+
+// PortService returns the Service publishing the guest ports requested in the
+// expose_ports provider hint, or nil when none were requested. The Service
+// selects the VM's launcher pod, and the caller is expected to create it once
+// the VM exists and owner-reference it to the VM.
+func (m *Mapper) PortService(vmSpec *types.VMSpec, vmID string) (*k8sv1.Service, error) {
+	ports, serviceType, err := m.exposedPorts(vmSpec)
+	if err != nil || ports == nil {
+		return nil, err
+	}
+
+	servicePorts := make([]k8sv1.ServicePort, 0, len(ports))
+	for _, p := range ports {
+		servicePorts = append(servicePorts, k8sv1.ServicePort{
+			Name:       fmt.Sprintf("%s-%d", strings.ToLower(p.Protocol), p.Port),
+			Protocol:   k8sv1.Protocol(p.Protocol),
+			Port:       p.Port,
+			TargetPort: intstr.FromInt32(p.TargetPort),
+			NodePort:   p.NodePort,
+		})
+	}
+
+	service := &k8sv1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PortServiceName(vmID),
+			Namespace: m.namespace,
+			Labels: map[string]string{
+				constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+				constants.DCMLabelInstanceID: vmID,
+			},
+		},
+		Spec: k8sv1.ServiceSpec{
+			Type: serviceType,
+			// Launcher pods carry the VM template labels
+			Selector: map[string]string{
+				constants.DCMLabelInstanceID: vmID,
+			},
+			Ports: servicePorts,
+		},
+	}
+	m.applyAccessMetadata(&service.ObjectMeta)
+	return service, nil
+}
@@ -0,0 +1,32 @@
+package kubevirt
+
+import (
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// Provenance identifies the provider build and catalog schema a VM was mapped
+// with, so mapping regressions can be traced back to their source.
+type Provenance struct {
+	ProviderVersion string
+	SchemaVersion   string
+}
+
+// AnnotateProvenance records the originating request ID and the provenance on
+// the VM. Empty values are left out.
+func AnnotateProvenance(vm *kubevirtv1.VirtualMachine, p Provenance, requestID string) {
+	for key, value := range map[string]string{
+		constants.DCMAnnotationRequestID:       requestID,
+		constants.DCMAnnotationProviderVersion: p.ProviderVersion,
+		constants.DCMAnnotationSchemaVersion:   p.SchemaVersion,
+	} {
+		if value == "" {
+			continue
+		}
+		if vm.Annotations == nil {
+			vm.Annotations = map[string]string{}
+		}
+		vm.Annotations[key] = value
+	}
+}
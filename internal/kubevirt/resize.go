@@ -0,0 +1,63 @@
+package kubevirt
+
+import (
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// ResizeVirtualMachine sets the vCPU count and memory size of the VM and reports
+// whether either changed. Memory sets both the guest memory and the pod memory
+// request derived from it. Nil values leave the corresponding resource
+// unchanged. Only the VM template is updated, so a running instance keeps its
+// current resources until it is restarted.
+func (m *Mapper) ResizeVirtualMachine(vm *kubevirtv1.VirtualMachine, vcpu *int, memory *string) (bool, error) {
+	if vcpu == nil && memory == nil {
+		return false, fmt.Errorf("at least one of vcpu and memory must be set")
+	}
+	if vm.Spec.Template == nil {
+		return false, fmt.Errorf("virtual machine %s has no template", vm.Name)
+	}
+
+	requests := k8sv1.ResourceList{}
+	if vcpu != nil {
+		if *vcpu < 1 {
+			return false, fmt.Errorf("invalid vcpu count %d: must be at least 1", *vcpu)
+		}
+		requests[k8sv1.ResourceCPU] = resource.MustParse(fmt.Sprintf("%d", *vcpu))
+	}
+	var guest *resource.Quantity
+	if memory != nil {
+		guestMemorySize, request, err := m.memoryResources(*memory)
+		if err != nil {
+			return false, err
+		}
+		requests[k8sv1.ResourceMemory] = request
+		guest = &guestMemorySize
+	}
+
+	domain := &vm.Spec.Template.Spec.Domain
+	changed := false
+	if guest != nil {
+		if current, ok := guestMemory(domain); !ok || current.Cmp(*guest) != 0 {
+			changed = true
+		}
+		if domain.Memory == nil {
+			domain.Memory = &kubevirtv1.Memory{}
+		}
+		domain.Memory.Guest = guest
+	}
+	if domain.Resources.Requests == nil {
+		domain.Resources.Requests = k8sv1.ResourceList{}
+	}
+	for name, quantity := range requests {
+		if current, ok := domain.Resources.Requests[name]; ok && current.Cmp(quantity) == 0 {
+			continue
+		}
+		domain.Resources.Requests[name] = quantity
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,68 @@
+package kubevirt
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+)
+
+const (
+	// defaultRetryBackoff is the delay before the first retry of a failed request
+	defaultRetryBackoff = 200 * time.Millisecond
+	// maxRetryBackoff caps the exponential backoff between retries
+	maxRetryBackoff = 5 * time.Second
+)
+
+// withRetry runs fn, retrying up to maxRetries times with exponential backoff
+// while it fails with a retryable error. Each attempt gets its own request
+// timeout, and retries stop early when ctx is done.
+func (c *Client) withRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoff := c.retryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := fn(timeoutCtx)
+		cancel()
+		if err == nil || attempt >= c.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// isRetryable reports whether a failed request may succeed when repeated:
+// network errors, throttling and server-side errors. Client errors such as
+// not found, conflict or invalid fail immediately.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) {
+		code := int(statusErr.Status().Code)
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+
+	if utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,59 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// runStrategyHint selects the run strategy of a single VM
+const runStrategyHint = "run_strategy"
+
+// statusStopped is reported for VMs that KubeVirt has not yet given a status
+// and that are not started automatically
+const statusStopped = "Stopped"
+
+// ParseRunStrategy validates a run strategy. Always starts the VM on create and
+// keeps it running; Manual creates it stopped and only starts or stops it on an
+// explicit request.
+func ParseRunStrategy(s string) (kubevirtv1.VirtualMachineRunStrategy, error) {
+	switch strings.ToLower(s) {
+	case "always":
+		return kubevirtv1.RunStrategyAlways, nil
+	case "manual":
+		return kubevirtv1.RunStrategyManual, nil
+	default:
+		return "", fmt.Errorf("unknown run strategy %q", s)
+	}
+}
+
+// runStrategy resolves the run strategy for a VM, preferring the run_strategy
+// provider hint over the mapper default.
+func (m *Mapper) runStrategy(vmSpec *types.VMSpec) (kubevirtv1.VirtualMachineRunStrategy, error) {
+	var strategy string
+	if _, err := decodeHint(vmSpec, runStrategyHint, &strategy); err != nil {
+		return "", err
+	}
+	if strategy == "" {
+		return m.runStrategyDefault, nil
+	}
+	return ParseRunStrategy(strategy)
+}
+
+// virtualMachineStatus returns the printable status of a VM. Before KubeVirt
+// reports one, VMs that are not started automatically are reported as stopped.
+func virtualMachineStatus(vm *kubevirtv1.VirtualMachine) string {
+	if vm.Status.PrintableStatus != "" {
+		return string(vm.Status.PrintableStatus)
+	}
+	if vm.Spec.RunStrategy != nil {
+		switch *vm.Spec.RunStrategy {
+		case kubevirtv1.RunStrategyManual, kubevirtv1.RunStrategyHalted:
+			return statusStopped
+		}
+	}
+	return ""
+}
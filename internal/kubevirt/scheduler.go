@@ -0,0 +1,43 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// schedulerNameHint selects the scheduler of a single VM, replacing the configured one
+const schedulerNameHint = "scheduler_name"
+
+// ValidateSchedulerName checks that a scheduler name is usable on a pod, which
+// requires a non-empty DNS subdomain
+func ValidateSchedulerName(name string) error {
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("invalid scheduler name %q: %s", name, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// applySchedulerName sets the scheduler of the VM's launcher pod, preferring
+// the scheduler_name provider hint over the mapper default. Without either the
+// default cluster scheduler is used.
+func (m *Mapper) applySchedulerName(vmSpec *types.VMSpec, spec *kubevirtv1.VirtualMachineInstanceSpec) error {
+	name := m.schedulerName
+	var hint string
+	found, err := decodeHint(vmSpec, schedulerNameHint, &hint)
+	if err != nil {
+		return err
+	}
+	if found {
+		if err := ValidateSchedulerName(hint); err != nil {
+			return fmt.Errorf("provider hint %s: %w", schedulerNameHint, err)
+		}
+		name = hint
+	}
+	spec.SchedulerName = name
+	return nil
+}
@@ -0,0 +1,49 @@
+package kubevirt
+
+import (
+	"fmt"
+	"math"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// scratchDiskName is the name of the provider-managed swap/scratch volume and disk
+const scratchDiskName = "scratch"
+
+// buildScratchDisk creates the scratch disk and its empty-disk volume, sized as
+// the configured multiple of the guest memory. It returns nil values when
+// scratch disks are disabled.
+func (m *Mapper) buildScratchDisk(vmSpec *types.VMSpec, memory resource.Quantity) (*kubevirtv1.Disk, *kubevirtv1.Volume, error) {
+	if m.scratchDiskRatio <= 0 {
+		return nil, nil, nil
+	}
+	for _, disk := range vmSpec.Storage.Disks {
+		if disk.Name == scratchDiskName {
+			return nil, nil, fmt.Errorf("disk name %q is reserved for the scratch disk", scratchDiskName)
+		}
+	}
+
+	bytes := int64(math.Ceil(float64(memory.Value()) * m.scratchDiskRatio))
+	capacity := m.roundToGranularity(*resource.NewQuantity(bytes, resource.BinarySI))
+
+	disk := &kubevirtv1.Disk{
+		Name: scratchDiskName,
+		DiskDevice: kubevirtv1.DiskDevice{
+			Disk: &kubevirtv1.DiskTarget{
+				Bus: kubevirtv1.DiskBusVirtio,
+			},
+		},
+	}
+	volume := &kubevirtv1.Volume{
+		Name: scratchDiskName,
+		VolumeSource: kubevirtv1.VolumeSource{
+			EmptyDisk: &kubevirtv1.EmptyDiskSource{
+				Capacity: capacity,
+			},
+		},
+	}
+	return disk, volume, nil
+}
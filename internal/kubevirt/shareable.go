@@ -0,0 +1,36 @@
+package kubevirt
+
+import (
+	"fmt"
+
+	k8sv1 "k8s.io/api/core/v1"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+)
+
+// applyShareableDisks marks the disks requested as shareable so that several
+// VMs of a guest cluster can attach the same block device. Provisioned volumes
+// must be ReadWriteMany; attached DataVolumes are checked by
+// CheckDataVolumeSources. Host caching is disabled because it is not safe
+// with concurrent writers.
+func (m *Mapper) applyShareableDisks(vm *kubevirtv1.VirtualMachine, backends map[string]diskStorage) error {
+	for name, storage := range backends {
+		if !storage.Shareable || storage.attachesDataVolume() {
+			continue
+		}
+		modes := m.accessModes(storage)
+		if len(modes) != 1 || modes[0] != k8sv1.ReadWriteMany {
+			return fmt.Errorf("disk storage %q: shareable disk requires %s access", name, k8sv1.ReadWriteMany)
+		}
+	}
+
+	disks := vm.Spec.Template.Spec.Domain.Devices.Disks
+	for i := range disks {
+		if !backends[disks[i].Name].Shareable {
+			continue
+		}
+		shareable := true
+		disks[i].Shareable = &shareable
+		disks[i].Cache = kubevirtv1.CacheNone
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package kubevirt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxStartupBackoff caps the exponential backoff between client creation attempts
+const maxStartupBackoff = 30 * time.Second
+
+// NewClientWithRetry gets the client of a namespace from the factory and checks
+// that it reaches the API server, making up to attempts tries with exponential
+// backoff starting at backoff, so a cluster that is briefly unreachable at
+// boot does not stop the provider. Only failures to reach the API server are
+// retried; an invalid namespace fails immediately, and retries stop early when
+// ctx is done.
+func NewClientWithRetry(ctx context.Context, factory *ClientFactory, namespace string, attempts int, backoff time.Duration) (*Client, error) {
+	c, err := factory.Client(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return retryNewClient(ctx, func() (*Client, error) {
+		if err := c.checkServerVersion(ctx); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}, attempts, backoff)
+}
+
+// checkServerVersion reads the version of the API server, which any client may
+// do, to tell whether the cluster is reachable. Creating a client alone never
+// contacts it.
+func (c *Client) checkServerVersion(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if err := c.coreClient.Discovery().RESTClient().Get().AbsPath("/version").Do(timeoutCtx).Error(); err != nil {
+		return fmt.Errorf("failed to reach the Kubernetes API server: %w", err)
+	}
+	return nil
+}
+
+// retryNewClient calls newClient until it succeeds or attempts are exhausted,
+// returning the last error
+func retryNewClient(ctx context.Context, newClient func() (*Client, error), attempts int, backoff time.Duration) (*Client, error) {
+	attempts = max(attempts, 1)
+	for attempt := 1; ; attempt++ {
+		c, err := newClient()
+		if err == nil {
+			return c, nil
+		}
+		if attempt >= attempts {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		zap.S().Warnw("Failed to create KubeVirt client, retrying",
+			"attempt", attempt, "maxAttempts", attempts, "backoff", backoff, "error", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("stopped after %d attempts: %w", attempt, err)
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, maxStartupBackoff)
+	}
+}
@@ -0,0 +1,403 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+	cdiv1 "kubevirt.io/containerized-data-importer-api/pkg/apis/core/v1beta1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// diskStorageHint selects the storage backend of individual disks by name
+const diskStorageHint = "disk_storage"
+
+// Disk storage backends. The boot disk defaults to a container disk and data
+// disks to an empty disk; both are ephemeral and lose their data on restart.
+const (
+	diskBackendContainerDisk         = "containerDisk"
+	diskBackendEmptyDisk             = "emptyDisk"
+	diskBackendPersistentVolumeClaim = "persistentVolumeClaim"
+	diskBackendDataVolume            = "dataVolume"
+)
+
+// diskStorage is the storage backend of a single disk declared in provider hints
+type diskStorage struct {
+	Backend      string `json:"backend"`
+	StorageClass string `json:"storage_class,omitempty"`
+	// DataVolume names an existing DataVolume, such as a golden image, that a
+	// dataVolume disk is created from
+	DataVolume string `json:"data_volume,omitempty"`
+	// Clone copies DataVolume into a volume owned by the VM; false attaches the
+	// DataVolume itself. Defaults to true.
+	Clone *bool `json:"clone,omitempty"`
+	// AccessMode of a provisioned volume: ReadWriteOnce or ReadWriteMany
+	AccessMode string `json:"access_mode,omitempty"`
+	// VolumeMode of a provisioned volume: Filesystem or Block
+	VolumeMode string `json:"volume_mode,omitempty"`
+	// Shareable lets several VMs attach the disk at once, as clustered guests
+	// with shared-disk failover do. It requires a ReadWriteMany block volume.
+	Shareable bool `json:"shareable,omitempty"`
+}
+
+// attachesDataVolume reports whether the disk uses an existing DataVolume directly
+func (s diskStorage) attachesDataVolume() bool {
+	return s.DataVolume != "" && s.Clone != nil && !*s.Clone
+}
+
+// dataVolumeName returns the name of the DataVolume backing a dataVolume disk
+func (s diskStorage) dataVolumeName(vmID, diskName string) string {
+	if s.attachesDataVolume() {
+		return s.DataVolume
+	}
+	return diskClaimName(vmID, diskName)
+}
+
+// isBootDisk reports whether the disk at position i carries the guest OS
+func isBootDisk(i int, disk types.Disk) bool {
+	return i == 0 || disk.Name == "boot"
+}
+
+// diskClaimName returns the name of the claim backing a persistent disk
+func diskClaimName(vmID, diskName string) string {
+	return virtualMachineName(vmID) + "-" + diskName
+}
+
+// diskStorageBackends returns the storage backends requested through provider
+// hints, keyed by disk name. Persistent volume claims are provisioned empty, so
+// they cannot back the boot disk; data volumes import the guest OS image instead.
+func diskStorageBackends(vmSpec *types.VMSpec) (map[string]diskStorage, error) {
+	var backends map[string]diskStorage
+	if _, err := decodeHint(vmSpec, diskStorageHint, &backends); err != nil {
+		return nil, err
+	}
+
+	for name, storage := range backends {
+		index := -1
+		for i, disk := range vmSpec.Storage.Disks {
+			if disk.Name == name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("disk storage %q: no such disk", name)
+		}
+		boot := isBootDisk(index, vmSpec.Storage.Disks[index])
+
+		if storage.DataVolume != "" {
+			if !strings.EqualFold(storage.Backend, diskBackendDataVolume) {
+				return nil, fmt.Errorf("disk storage %q: data volume source requires the %s backend", name, diskBackendDataVolume)
+			}
+			if errs := validation.IsDNS1123Subdomain(storage.DataVolume); len(errs) > 0 {
+				return nil, fmt.Errorf("disk storage %q: invalid data volume name %q: %s", name, storage.DataVolume, strings.Join(errs, ", "))
+			}
+			if storage.attachesDataVolume() && storage.StorageClass != "" {
+				return nil, fmt.Errorf("disk storage %q: storage class does not apply to an attached data volume", name)
+			}
+		} else if storage.Clone != nil {
+			return nil, fmt.Errorf("disk storage %q: clone requires a data volume source", name)
+		}
+
+		if storage.AccessMode != "" {
+			mode, err := parseAccessMode(storage.AccessMode)
+			if err != nil {
+				return nil, fmt.Errorf("disk storage %q: %w", name, err)
+			}
+			if storage.attachesDataVolume() {
+				return nil, fmt.Errorf("disk storage %q: access mode does not apply to an attached data volume", name)
+			}
+			storage.AccessMode = string(mode)
+		}
+		if storage.VolumeMode != "" {
+			mode, err := parseVolumeMode(storage.VolumeMode)
+			if err != nil {
+				return nil, fmt.Errorf("disk storage %q: %w", name, err)
+			}
+			if storage.attachesDataVolume() {
+				return nil, fmt.Errorf("disk storage %q: volume mode does not apply to an attached data volume", name)
+			}
+			storage.VolumeMode = string(mode)
+		}
+		if storage.Shareable {
+			if boot {
+				return nil, fmt.Errorf("disk storage %q: boot disk cannot be shareable", name)
+			}
+			if !storage.attachesDataVolume() && storage.VolumeMode != string(k8sv1.PersistentVolumeBlock) {
+				return nil, fmt.Errorf("disk storage %q: shareable disk requires the %s volume mode", name, k8sv1.PersistentVolumeBlock)
+			}
+		}
+
+		switch strings.ToLower(storage.Backend) {
+		case "", strings.ToLower(diskBackendContainerDisk), strings.ToLower(diskBackendEmptyDisk):
+			if storage.Backend != "" && !strings.EqualFold(storage.Backend, defaultDiskBackend(boot)) {
+				return nil, fmt.Errorf("disk storage %q: backend %s is not supported for this disk", name, storage.Backend)
+			}
+			if storage.StorageClass != "" {
+				return nil, fmt.Errorf("disk storage %q: storage class requires a persistent backend", name)
+			}
+			if storage.AccessMode != "" || storage.VolumeMode != "" || storage.Shareable {
+				return nil, fmt.Errorf("disk storage %q: access mode, volume mode and shareable require a persistent backend", name)
+			}
+			storage.Backend = defaultDiskBackend(boot)
+		case strings.ToLower(diskBackendPersistentVolumeClaim):
+			if boot {
+				return nil, fmt.Errorf("disk storage %q: boot disk cannot use an empty %s", name, diskBackendPersistentVolumeClaim)
+			}
+			storage.Backend = diskBackendPersistentVolumeClaim
+		case strings.ToLower(diskBackendDataVolume):
+			storage.Backend = diskBackendDataVolume
+		default:
+			return nil, fmt.Errorf("disk storage %q: unsupported backend %q", name, storage.Backend)
+		}
+		backends[name] = storage
+	}
+	return backends, nil
+}
+
+// parseAccessMode validates a requested volume access mode
+func parseAccessMode(s string) (k8sv1.PersistentVolumeAccessMode, error) {
+	for _, mode := range []k8sv1.PersistentVolumeAccessMode{k8sv1.ReadWriteOnce, k8sv1.ReadWriteMany} {
+		if strings.EqualFold(s, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported access mode %q", s)
+}
+
+// parseVolumeMode validates a requested volume mode
+func parseVolumeMode(s string) (k8sv1.PersistentVolumeMode, error) {
+	for _, mode := range []k8sv1.PersistentVolumeMode{k8sv1.PersistentVolumeFilesystem, k8sv1.PersistentVolumeBlock} {
+		if strings.EqualFold(s, string(mode)) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported volume mode %q", s)
+}
+
+// volumeMode returns the volume mode a provisioned volume is requested with,
+// or nil to leave it to the storage class
+func volumeMode(storage diskStorage) *k8sv1.PersistentVolumeMode {
+	if storage.VolumeMode == "" {
+		return nil
+	}
+	mode := k8sv1.PersistentVolumeMode(storage.VolumeMode)
+	return &mode
+}
+
+// accessModes returns the access modes a provisioned volume is requested with.
+// Without an explicit mode, claims are ReadWriteOnce and data volumes defer to
+// the storage profile, unless the maintenance-ready profile requires ReadWriteMany.
+func (m *Mapper) accessModes(storage diskStorage) []k8sv1.PersistentVolumeAccessMode {
+	switch {
+	case storage.AccessMode != "":
+		return []k8sv1.PersistentVolumeAccessMode{k8sv1.PersistentVolumeAccessMode(storage.AccessMode)}
+	case m.maintenanceReady:
+		return []k8sv1.PersistentVolumeAccessMode{k8sv1.ReadWriteMany}
+	case storage.Backend == diskBackendPersistentVolumeClaim:
+		return []k8sv1.PersistentVolumeAccessMode{k8sv1.ReadWriteOnce}
+	default:
+		return nil
+	}
+}
+
+// defaultDiskBackend returns the backend used for disks without a storage hint
+func defaultDiskBackend(boot bool) string {
+	if boot {
+		return diskBackendContainerDisk
+	}
+	return diskBackendEmptyDisk
+}
+
+// diskCapacity returns the requested capacity of a disk, or the default data
+// disk capacity when none was requested
+func (m *Mapper) diskCapacity(disk types.Disk) (resource.Quantity, error) {
+	if disk.Capacity == "" {
+		return resource.MustParse(defaultDataDiskCapacity), nil
+	}
+	capacity, err := m.parseStorageSize(disk.Capacity)
+	if err != nil {
+		return resource.Quantity{}, fmt.Errorf("disk %q: %w", disk.Name, err)
+	}
+	return capacity, nil
+}
+
+// buildDataVolumeTemplates creates the data volumes backing dataVolume disks.
+// A boot disk imports the guest OS image from its container disk registry,
+// unless the disk is cloned from an existing DataVolume. Attached DataVolumes
+// are not templated.
+func (m *Mapper) buildDataVolumeTemplates(vmSpec *types.VMSpec, vmID string, backends map[string]diskStorage) ([]kubevirtv1.DataVolumeTemplateSpec, error) {
+	var templates []kubevirtv1.DataVolumeTemplateSpec
+	for i, disk := range vmSpec.Storage.Disks {
+		storage := backends[disk.Name]
+		if storage.Backend != diskBackendDataVolume || storage.attachesDataVolume() {
+			continue
+		}
+		capacity, err := m.diskCapacity(disk)
+		if err != nil {
+			return nil, err
+		}
+
+		source := &cdiv1.DataVolumeSource{Blank: &cdiv1.DataVolumeBlankImage{}}
+		if isBootDisk(i, disk) {
+			url := "docker://" + m.getContainerDiskImage(vmSpec.GuestOs)
+			source = &cdiv1.DataVolumeSource{Registry: &cdiv1.DataVolumeSourceRegistry{URL: &url}}
+			if capacity, err = m.bootDiskCapacity(vmID, disk, capacity); err != nil {
+				return nil, err
+			}
+		}
+		if storage.DataVolume != "" {
+			// CDI clones a DataVolume through the claim of the same name
+			source = &cdiv1.DataVolumeSource{PVC: &cdiv1.DataVolumeSourcePVC{
+				Namespace: m.namespace,
+				Name:      storage.DataVolume,
+			}}
+		}
+
+		templates = append(templates, kubevirtv1.DataVolumeTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: diskClaimName(vmID, disk.Name),
+				Labels: map[string]string{
+					constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+					constants.DCMLabelInstanceID: vmID,
+				},
+			},
+			Spec: cdiv1.DataVolumeSpec{
+				Source: source,
+				Storage: &cdiv1.StorageSpec{
+					AccessModes: m.accessModes(storage),
+					VolumeMode:  volumeMode(storage),
+					Resources: k8sv1.ResourceRequirements{
+						Requests: k8sv1.ResourceList{k8sv1.ResourceStorage: capacity},
+					},
+					StorageClassName: storageClassName(storage.StorageClass),
+				},
+			},
+		})
+	}
+	return templates, nil
+}
+
+// bootDiskCapacity enforces the minimum capacity of a boot disk, which must
+// hold the imported guest OS image
+func (m *Mapper) bootDiskCapacity(vmID string, disk types.Disk, capacity resource.Quantity) (resource.Quantity, error) {
+	if m.minBootDiskCapacity.IsZero() || capacity.Cmp(m.minBootDiskCapacity) >= 0 {
+		return capacity, nil
+	}
+	if m.strictBootDiskCapacity {
+		return resource.Quantity{}, fmt.Errorf("boot disk %q: capacity %s is below the minimum of %s",
+			disk.Name, capacity.String(), m.minBootDiskCapacity.String())
+	}
+	zap.S().Warnw("Raising boot disk capacity to the minimum",
+		"vmID", vmID,
+		"disk", disk.Name,
+		"requested", capacity.String(),
+		"minimum", m.minBootDiskCapacity.String())
+	return m.minBootDiskCapacity.DeepCopy(), nil
+}
+
+// PersistentVolumeClaims returns the claims backing persistentVolumeClaim disks,
+// in disk declaration order. The caller is expected to create them once the VM
+// exists and owner-reference them to the VM.
+func (m *Mapper) PersistentVolumeClaims(vmSpec *types.VMSpec, vmID string) ([]*k8sv1.PersistentVolumeClaim, error) {
+	backends, err := diskStorageBackends(vmSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims []*k8sv1.PersistentVolumeClaim
+	for _, disk := range vmSpec.Storage.Disks {
+		storage := backends[disk.Name]
+		if storage.Backend != diskBackendPersistentVolumeClaim {
+			continue
+		}
+		capacity, err := m.diskCapacity(disk)
+		if err != nil {
+			return nil, err
+		}
+
+		claims = append(claims, &k8sv1.PersistentVolumeClaim{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "PersistentVolumeClaim",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      diskClaimName(vmID, disk.Name),
+				Namespace: m.namespace,
+				Labels: map[string]string{
+					constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+					constants.DCMLabelInstanceID: vmID,
+				},
+			},
+			Spec: k8sv1.PersistentVolumeClaimSpec{
+				AccessModes: m.accessModes(storage),
+				VolumeMode:  volumeMode(storage),
+				Resources: k8sv1.VolumeResourceRequirements{
+					Requests: k8sv1.ResourceList{k8sv1.ResourceStorage: capacity},
+				},
+				StorageClassName: storageClassName(storage.StorageClass),
+			},
+		})
+	}
+	return claims, nil
+}
+
+// storageClassName returns nil for an empty class so the cluster default applies
+func storageClassName(class string) *string {
+	if class == "" {
+		return nil
+	}
+	return &class
+}
+
+// diskStorageFromVirtualMachine recovers the non-default disk storage backends
+// of a VM, keyed by disk name
+func diskStorageFromVirtualMachine(vm *kubevirtv1.VirtualMachine) map[string]diskStorage {
+	templates := make(map[string]kubevirtv1.DataVolumeTemplateSpec, len(vm.Spec.DataVolumeTemplates))
+	for _, t := range vm.Spec.DataVolumeTemplates {
+		templates[t.Name] = t
+	}
+
+	backends := map[string]diskStorage{}
+	for _, vol := range vm.Spec.Template.Spec.Volumes {
+		switch {
+		case vol.PersistentVolumeClaim != nil:
+			backends[vol.Name] = diskStorage{Backend: diskBackendPersistentVolumeClaim}
+		case vol.DataVolume != nil:
+			storage := diskStorage{Backend: diskBackendDataVolume}
+			if t, ok := templates[vol.DataVolume.Name]; ok {
+				if t.Spec.Storage != nil && t.Spec.Storage.StorageClassName != nil {
+					storage.StorageClass = *t.Spec.Storage.StorageClassName
+				}
+				if t.Spec.Storage != nil && len(t.Spec.Storage.AccessModes) > 0 {
+					storage.AccessMode = string(t.Spec.Storage.AccessModes[0])
+				}
+				if t.Spec.Storage != nil && t.Spec.Storage.VolumeMode != nil {
+					storage.VolumeMode = string(*t.Spec.Storage.VolumeMode)
+				}
+				if t.Spec.Source != nil && t.Spec.Source.PVC != nil {
+					storage.DataVolume = t.Spec.Source.PVC.Name
+				}
+			} else {
+				clone := false
+				storage.DataVolume = vol.DataVolume.Name
+				storage.Clone = &clone
+			}
+			backends[vol.Name] = storage
+		}
+	}
+	for _, disk := range vm.Spec.Template.Spec.Domain.Devices.Disks {
+		if storage, ok := backends[disk.Name]; ok && disk.Shareable != nil && *disk.Shareable {
+			storage.Shareable = true
+			backends[disk.Name] = storage
+		}
+	}
+	return backends
+}
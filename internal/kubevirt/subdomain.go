@@ -0,0 +1,133 @@
+package kubevirt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// subdomainHint places a single VM in a DNS subdomain
+const subdomainHint = "subdomain"
+
+// ErrHostnameInUse is returned when a VM would resolve to the same DNS name as
+// another VM in its subdomain
+var ErrHostnameInUse = errors.New("hostname in use")
+
+// subdomain resolves the DNS subdomain of a VM, preferring the subdomain
+// provider hint over the mapper default. An empty subdomain disables DNS records.
+func (m *Mapper) subdomain(vmSpec *types.VMSpec) (string, error) {
+	subdomain := m.subdomainDefault
+	var hint string
+	found, err := decodeHint(vmSpec, subdomainHint, &hint)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		subdomain = hint
+	}
+	if subdomain == "" {
+		return "", nil
+	}
+	if err := ValidateSubdomain(subdomain); err != nil {
+		return "", err
+	}
+	return subdomain, nil
+}
+
+// ValidateSubdomain checks that a subdomain is a valid DNS-1123 label, which
+// it must be to name the Service governing it
+func ValidateSubdomain(subdomain string) error {
+	if errs := validation.IsDNS1123Label(subdomain); len(errs) > 0 {
+		return fmt.Errorf("invalid subdomain %q: %s", subdomain, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// applySubdomain places the VM in its DNS subdomain, making it resolvable as
+// <hostname>.<subdomain>.<namespace>.svc once the governing Service exists.
+// The hostname defaults to the VM name unless the hostname hint is set.
+func (m *Mapper) applySubdomain(vmSpec *types.VMSpec, template *kubevirtv1.VirtualMachineInstanceTemplateSpec) error {
+	subdomain, err := m.subdomain(vmSpec)
+	if err != nil || subdomain == "" {
+		return err
+	}
+	hostname, err := guestHostname(vmSpec)
+	if err != nil {
+		return err
+	}
+
+	template.Spec.Subdomain = subdomain
+	template.Spec.Hostname = hostname
+	template.ObjectMeta.Labels[constants.DCMLabelSubdomain] = subdomain
+	return nil
+}
+
+// SubdomainService returns the headless Service governing the DNS subdomain of
+// a VM, or nil when the VM is not placed in one. The Service is shared by every
+// VM in the subdomain, so the caller is expected to create it only if it does
+// not exist yet and not to owner-reference it to a single VM.
+func (m *Mapper) SubdomainService(vmSpec *types.VMSpec) (*k8sv1.Service, error) {
+	subdomain, err := m.subdomain(vmSpec)
+	if err != nil || subdomain == "" {
+		return nil, err
+	}
+
+	return &k8sv1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      subdomain,
+			Namespace: m.namespace,
+			Labels: map[string]string{
+				constants.DCMLabelManagedBy: constants.DCMManagedByValue,
+				constants.DCMLabelSubdomain: subdomain,
+			},
+		},
+		Spec: k8sv1.ServiceSpec{
+			ClusterIP: k8sv1.ClusterIPNone,
+			// Launcher pods carry the VM template labels
+			Selector: map[string]string{
+				constants.DCMLabelSubdomain: subdomain,
+			},
+		},
+	}, nil
+}
+
+// HostnameConflict fails with ErrHostnameInUse when another of the existing VMs
+// has the same hostname in the same subdomain as vm, so both would resolve to
+// one <hostname>.<subdomain> DNS name. VMs outside a subdomain get no DNS record
+// and never conflict.
+func HostnameConflict(vm *kubevirtv1.VirtualMachine, existing []kubevirtv1.VirtualMachine) error {
+	hostname, subdomain := vmHostname(vm)
+	if hostname == "" || subdomain == "" {
+		return nil
+	}
+	for i := range existing {
+		if existing[i].Name == vm.Name {
+			continue
+		}
+		if h, d := vmHostname(&existing[i]); h == hostname && d == subdomain {
+			return fmt.Errorf("%w: hostname %q is already used by %s in subdomain %q",
+				ErrHostnameInUse, hostname, existing[i].Name, subdomain)
+		}
+	}
+	return nil
+}
+
+// vmHostname returns the hostname and subdomain the template of a VM sets
+func vmHostname(vm *kubevirtv1.VirtualMachine) (hostname, subdomain string) {
+	if vm.Spec.Template == nil {
+		return "", ""
+	}
+	return vm.Spec.Template.Spec.Hostname, vm.Spec.Template.Spec.Subdomain
+}
@@ -0,0 +1,88 @@
+package kubevirt
+
+import (
+	"fmt"
+	"strings"
+
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	types "github.com/dcm-project/kubevirt-service-provider/api/v1alpha1"
+)
+
+// tpmHint selects the virtual TPM of a single VM, e.g. for Windows 11 or
+// attestation workloads
+const tpmHint = "tpm"
+
+// TPMMode selects whether and how a VM is given a virtual TPM
+type TPMMode string
+
+const (
+	// TPMNone attaches no TPM
+	TPMNone TPMMode = "none"
+	// TPMEphemeral attaches a TPM whose state is lost when the VM stops
+	TPMEphemeral TPMMode = "ephemeral"
+	// TPMPersistent attaches a TPM whose state is kept across reboots
+	TPMPersistent TPMMode = "persistent"
+)
+
+// ParseTPMMode validates a TPM mode. An empty mode attaches no TPM.
+func ParseTPMMode(s string) (TPMMode, error) {
+	switch mode := TPMMode(strings.ToLower(s)); mode {
+	case "":
+		return TPMNone, nil
+	case TPMNone, TPMEphemeral, TPMPersistent:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown TPM mode %q: must be %s, %s or %s", s, TPMNone, TPMEphemeral, TPMPersistent)
+	}
+}
+
+// tpmMode resolves the TPM mode of a VM, preferring the tpm provider hint over
+// the mapper default.
+func (m *Mapper) tpmMode(vmSpec *types.VMSpec) (TPMMode, error) {
+	var mode string
+	found, err := decodeHint(vmSpec, tpmHint, &mode)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return m.tpmDefault, nil
+	}
+	return ParseTPMMode(mode)
+}
+
+// applyTPM attaches the VM's virtual TPM. KubeVirt keeps the state of a
+// persistent TPM in a volume of its VM state storage class, so persistent TPMs
+// are rejected unless the mapper was told that class is configured.
+func (m *Mapper) applyTPM(vmSpec *types.VMSpec, spec *kubevirtv1.VirtualMachineInstanceSpec) error {
+	mode, err := m.tpmMode(vmSpec)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case TPMEphemeral:
+		spec.Domain.Devices.TPM = &kubevirtv1.TPMDevice{}
+	case TPMPersistent:
+		if m.vmStateStorageClass == "" {
+			return fmt.Errorf("a persistent TPM requires a VM state storage class, which is not configured")
+		}
+		persistent := true
+		spec.Domain.Devices.TPM = &kubevirtv1.TPMDevice{Persistent: &persistent}
+	}
+	return nil
+}
+
+// tpmModeFromVirtualMachine returns the TPM mode of a VM, or an empty mode when
+// it has no TPM
+func tpmModeFromVirtualMachine(vm *kubevirtv1.VirtualMachine) TPMMode {
+	tpm := vm.Spec.Template.Spec.Domain.Devices.TPM
+	switch {
+	case tpm == nil:
+		return ""
+	case tpm.Persistent != nil && *tpm.Persistent:
+		return TPMPersistent
+	default:
+		return TPMEphemeral
+	}
+}
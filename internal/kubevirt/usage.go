@@ -0,0 +1,118 @@
+package kubevirt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+)
+
+// ErrMetricsUnavailable is returned when the cluster does not serve the resource
+// metrics API, or has no sample for the VM yet.
+var ErrMetricsUnavailable = errors.New("resource metrics unavailable")
+
+// launcherPodLabel selects the virt-launcher pods that run VMIs
+const launcherPodLabel = "kubevirt.io=virt-launcher"
+
+var podMetricsGVR = schema.GroupVersionResource{
+	Group:    "metrics.k8s.io",
+	Version:  "v1beta1",
+	Resource: "pods",
+}
+
+// ResourceUsage is the CPU and memory usage of a running VM
+type ResourceUsage struct {
+	CPU       resource.Quantity
+	Memory    resource.Quantity
+	Timestamp time.Time
+	Window    time.Duration
+}
+
+// GetVirtualMachineUsage returns the current CPU and memory usage of the
+// virt-launcher pod running the VM with the given DCM instance ID, as reported by
+// the metrics API. A VM without a launcher pod yields a NotFound error.
+func (c *Client) GetVirtualMachineUsage(ctx context.Context, vmID string) (*ResourceUsage, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if _, err := c.coreClient.Discovery().ServerResourcesForGroupVersion(podMetricsGVR.GroupVersion().String()); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s is not served by the cluster", ErrMetricsUnavailable, podMetricsGVR.GroupVersion())
+		}
+		return nil, fmt.Errorf("failed to discover the metrics API: %w", err)
+	}
+
+	pods, err := c.coreClient.CoreV1().Pods(c.namespace).List(timeoutCtx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s,%s=%s", launcherPodLabel, constants.DCMLabelInstanceID, vmID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list launcher pods: %w", err)
+	}
+	podName := ""
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == k8sv1.PodRunning {
+			podName = pod.Name
+			break
+		}
+	}
+	if podName == "" {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Group: "kubevirt.io", Resource: "virtualmachineinstances"}, vmID)
+	}
+
+	metrics, err := c.dynamicClient.Resource(podMetricsGVR).Namespace(c.namespace).Get(timeoutCtx, podName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("%w: no sample for VM %s yet", ErrMetricsUnavailable, vmID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+	return podMetricsUsage(metrics)
+}
+
+// podMetricsUsage sums the container usage of a metrics.k8s.io PodMetrics object
+func podMetricsUsage(metrics *unstructured.Unstructured) (*ResourceUsage, error) {
+	usage := &ResourceUsage{}
+
+	if ts, found, _ := unstructured.NestedString(metrics.Object, "timestamp"); found {
+		if t, err := time.Parse(time.RFC3339, ts); err == nil {
+			usage.Timestamp = t
+		}
+	}
+	if window, found, _ := unstructured.NestedString(metrics.Object, "window"); found {
+		if d, err := time.ParseDuration(window); err == nil {
+			usage.Window = d
+		}
+	}
+
+	containers, _, err := unstructured.NestedSlice(metrics.Object, "containers")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pod metrics: %w", err)
+	}
+	for _, container := range containers {
+		values, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name, total := range map[string]*resource.Quantity{"cpu": &usage.CPU, "memory": &usage.Memory} {
+			raw, found, _ := unstructured.NestedString(values, "usage", name)
+			if !found {
+				continue
+			}
+			q, err := resource.ParseQuantity(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s usage %q: %w", name, raw, err)
+			}
+			total.Add(q)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// FormatJSON writes one JSON object per entry, for log collectors
+	FormatJSON = "json"
+	// FormatConsole writes human-readable entries, for development
+	FormatConsole = "console"
+)
+
+// New builds the process logger. Entries below level (debug, info, warn,
+// error) are dropped; format is FormatJSON or FormatConsole.
+func New(level, format string) (*zap.Logger, error) {
+	cfg, err := newConfig(level, format)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Build()
+}
+
+// newConfig returns the production configuration for JSON logs and the
+// development one for console logs, both at the given level
+func newConfig(level, format string) (zap.Config, error) {
+	lvl, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return zap.Config{}, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+
+	var cfg zap.Config
+	switch format {
+	case FormatJSON:
+		cfg = zap.NewProductionConfig()
+	case FormatConsole:
+		cfg = zap.NewDevelopmentConfig()
+	default:
+		return zap.Config{}, fmt.Errorf("invalid log format %q: must be %q or %q", format, FormatJSON, FormatConsole)
+	}
+	cfg.Level = lvl
+	cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	return cfg, nil
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestLogging(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Logging Suite")
+}
+
+var _ = Describe("New", func() {
+	// buildToFile builds a logger like New does, writing to a file in a temp dir
+	buildToFile := func(level, format string) (func() string, func()) {
+		cfg, err := newConfig(level, format)
+		Expect(err).NotTo(HaveOccurred())
+
+		path := filepath.Join(GinkgoT().TempDir(), "out.log")
+		cfg.OutputPaths = []string{path}
+		logger, err := cfg.Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		read := func() string {
+			_ = logger.Sync()
+			data, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+		return read, func() {
+			logger.Debug("debug entry")
+			logger.Info("info entry")
+			logger.Warn("warn entry")
+		}
+	}
+
+	It("should drop entries below the configured level", func() {
+		read, logAll := buildToFile("warn", FormatJSON)
+		logAll()
+
+		out := read()
+		Expect(out).To(ContainSubstring("warn entry"))
+		Expect(out).NotTo(ContainSubstring("info entry"))
+		Expect(out).NotTo(ContainSubstring("debug entry"))
+	})
+
+	It("should keep debug entries at debug level", func() {
+		read, logAll := buildToFile("debug", FormatJSON)
+		logAll()
+
+		Expect(strings.Count(read(), "\n")).To(Equal(3))
+	})
+
+	It("should write one JSON object per entry in JSON format", func() {
+		read, logAll := buildToFile("info", FormatJSON)
+		logAll()
+
+		lines := strings.Split(strings.TrimSpace(read()), "\n")
+		Expect(lines).To(HaveLen(2))
+		var entry map[string]any
+		Expect(json.Unmarshal([]byte(lines[0]), &entry)).To(Succeed())
+		Expect(entry).To(HaveKeyWithValue("level", "info"))
+		Expect(entry).To(HaveKeyWithValue("msg", "info entry"))
+	})
+
+	It("should write plain text in console format", func() {
+		read, logAll := buildToFile("info", FormatConsole)
+		logAll()
+
+		out := read()
+		Expect(out).To(ContainSubstring("INFO"))
+		Expect(out).NotTo(HavePrefix("{"))
+	})
+
+	It("should reject an unknown level", func() {
+		_, err := New("verbose", FormatJSON)
+		Expect(err).To(MatchError(ContainSubstring("invalid log level")))
+	})
+
+	It("should reject an unknown format", func() {
+		_, err := New("info", "xml")
+		Expect(err).To(MatchError(ContainSubstring("invalid log format")))
+	})
+})
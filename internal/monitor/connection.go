@@ -0,0 +1,87 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	kubevirtv1 "kubevirt.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+var virtualMachineGVR = schema.GroupVersionResource{
+	Group:    "kubevirt.io",
+	Version:  "v1",
+	Resource: "virtualmachines",
+}
+
+// connectionInfo collects how a running VM is reached from its instance and
+// its port Service, if it has one
+func connectionInfo(vmInfo VMInfo, service *k8sv1.Service) kubevirt.ConnectionInfo {
+	info := kubevirt.ConnectionInfo{
+		SSHUser:       vmInfo.SSHUser,
+		SSHSecretName: vmInfo.SSHSecretName,
+	}
+	if len(vmInfo.IPs) > 0 {
+		info.IP = vmInfo.IPs[0]
+	}
+	if service != nil {
+		for _, port := range service.Spec.Ports {
+			if kubevirt.IsSSHServicePort(port) && port.NodePort != 0 {
+				info.SSHNodePort = port.NodePort
+				break
+			}
+		}
+	}
+	return info
+}
+
+// recordedConnectionInfo reads the connection info annotated on the
+// VirtualMachine of an instance, so that a VM annotated before a restart of the
+// provider is not patched again. A VM that cannot be read has none.
+func (s *Service) recordedConnectionInfo(ctx context.Context, vmInfo VMInfo) kubevirt.ConnectionInfo {
+	u, err := s.dynamicClient.Resource(virtualMachineGVR).Namespace(vmInfo.Namespace).Get(ctx, vmInfo.VMName, metav1.GetOptions{})
+	if err != nil {
+		return kubevirt.ConnectionInfo{}
+	}
+	vm := &kubevirtv1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Annotations: u.GetAnnotations()}}
+	if info := kubevirt.VirtualMachineConnectionInfo(vm); info != nil {
+		return *info
+	}
+	return kubevirt.ConnectionInfo{}
+}
+
+// recordConnectionInfo annotates the VirtualMachine of a running instance with
+// how it is reached, unless the VM already records it. Failures are logged, so
+// that the event is still published, and retried on the next event.
+func (s *Service) recordConnectionInfo(ctx context.Context, vmInfo VMInfo, service *k8sv1.Service) {
+	info := connectionInfo(vmInfo, service)
+	if s.connections.get(vmInfo.VMID) == info {
+		return
+	}
+	if s.recordedConnectionInfo(ctx, vmInfo) == info {
+		s.connections.set(vmInfo.VMID, info)
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": info.Annotations(),
+		},
+	})
+	if err != nil {
+		zap.S().Errorw("Failed to encode connection info of VM", "vmID", vmInfo.VMID, "error", err)
+		return
+	}
+	_, err = s.dynamicClient.Resource(virtualMachineGVR).Namespace(vmInfo.Namespace).
+		Patch(ctx, vmInfo.VMName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		zap.S().Warnw("Failed to record connection info of VM", "vmID", vmInfo.VMID, "error", err)
+		return
+	}
+	s.connections.set(vmInfo.VMID, info)
+}
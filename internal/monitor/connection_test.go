@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+var _ = Describe("Connection info", func() {
+	var (
+		client    *dynamicfake.FakeDynamicClient
+		publisher *recordingPublisher
+		service   *Service
+		vmInfo    VMInfo
+	)
+
+	BeforeEach(func() {
+		client = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				serviceGVR:        "ServiceList",
+				virtualMachineGVR: "VirtualMachineList",
+			})
+		vm := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kubevirt.io/v1",
+			"kind":       "VirtualMachine",
+			"metadata": map[string]interface{}{
+				"name":        "dcm-vm-123",
+				"namespace":   "default",
+				"annotations": map[string]interface{}{constants.DCMAnnotationRequestID: "req-1"},
+			},
+		}}
+		_, err := client.Resource(virtualMachineGVR).Namespace("default").Create(context.Background(), vm, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		publisher = &recordingPublisher{}
+		service = &Service{
+			ctx:                   context.Background(),
+			dynamicClient:         client,
+			publisher:             publisher,
+			persistConnectionInfo: true,
+		}
+		vmInfo = VMInfo{
+			VMID:          "vm-123",
+			VMName:        "dcm-vm-123",
+			Namespace:     "default",
+			Phase:         VMPhaseRunning,
+			IPs:           []string{"10.244.0.12", "fd00::12"},
+			SSHSecretName: "dcm-vm-123-ssh",
+			SSHUser:       "fedora",
+		}
+	})
+
+	annotations := func() map[string]string {
+		u, err := client.Resource(virtualMachineGVR).Namespace("default").Get(context.Background(), "dcm-vm-123", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return u.GetAnnotations()
+	}
+
+	createPortService := func(sshTargetPort int64) {
+		portService := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": kubevirt.PortServiceName("vm-123"), "namespace": "default"},
+			"spec": map[string]interface{}{
+				"type": "NodePort",
+				"ports": []interface{}{
+					map[string]interface{}{"name": "tcp-80", "port": int64(80), "targetPort": int64(80), "nodePort": int64(30080), "protocol": "TCP"},
+					map[string]interface{}{"name": "tcp-22", "port": int64(22), "targetPort": sshTargetPort, "nodePort": int64(30022), "protocol": "TCP"},
+				},
+			},
+		}}
+		_, err := client.Resource(serviceGVR).Namespace("default").Create(context.Background(), portService, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("should record the IP and SSH access of a VM once it is running", func() {
+		createPortService(22)
+
+		service.publishVMEvent(vmInfo)
+
+		Expect(annotations()).To(Equal(map[string]string{
+			constants.DCMAnnotationRequestID:   "req-1",
+			constants.DCMAnnotationIPAddress:   "10.244.0.12",
+			constants.DCMAnnotationSSHUser:     "fedora",
+			constants.DCMAnnotationSSHSecret:   "dcm-vm-123-ssh",
+			constants.DCMAnnotationSSHNodePort: "30022",
+		}))
+		Expect(publisher.events).To(HaveLen(1))
+	})
+
+	It("should record the SSH node port when SSH is forwarded to another guest port", func() {
+		createPortService(2222)
+
+		service.publishVMEvent(vmInfo)
+
+		Expect(annotations()).To(HaveKeyWithValue(constants.DCMAnnotationSSHNodePort, "30022"))
+	})
+
+	It("should remove details that no longer apply when the VM runs again", func() {
+		createPortService(22)
+		service.publishVMEvent(vmInfo)
+		service.forgetPublished(vmInfo.VMID)
+
+		vmInfo.IPs = nil
+		Expect(client.Resource(serviceGVR).Namespace("default").Delete(context.Background(), kubevirt.PortServiceName("vm-123"), metav1.DeleteOptions{})).To(Succeed())
+		service.publishVMEvent(vmInfo)
+
+		Expect(annotations()).NotTo(HaveKey(constants.DCMAnnotationIPAddress))
+		Expect(annotations()).NotTo(HaveKey(constants.DCMAnnotationSSHNodePort))
+		Expect(annotations()).To(HaveKeyWithValue(constants.DCMAnnotationSSHSecret, "dcm-vm-123-ssh"))
+	})
+
+	It("should record the IP when it is assigned after the VM started running", func() {
+		createPortService(22)
+		ips := vmInfo.IPs
+		vmInfo.IPs = nil
+		service.publishVMEvent(vmInfo)
+		Expect(annotations()).NotTo(HaveKey(constants.DCMAnnotationIPAddress))
+
+		vmInfo.IPs = ips
+		service.publishVMEvent(vmInfo)
+
+		Expect(annotations()).To(HaveKeyWithValue(constants.DCMAnnotationIPAddress, "10.244.0.12"))
+		Expect(publisher.events).To(HaveLen(1))
+	})
+
+	It("should not annotate the VM again while its connection info is unchanged", func() {
+		service.publishVMEvent(vmInfo)
+		client.ClearActions()
+
+		service.publishVMEvent(vmInfo)
+
+		for _, action := range client.Actions() {
+			Expect(action.GetVerb()).NotTo(Equal("patch"))
+		}
+	})
+
+	It("should not annotate a VM that already records its connection info", func() {
+		u, err := client.Resource(virtualMachineGVR).Namespace("default").Get(context.Background(), "dcm-vm-123", metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		u.SetAnnotations(map[string]string{
+			constants.DCMAnnotationIPAddress: "10.244.0.12",
+			constants.DCMAnnotationSSHUser:   "fedora",
+			constants.DCMAnnotationSSHSecret: "dcm-vm-123-ssh",
+		})
+		_, err = client.Resource(virtualMachineGVR).Namespace("default").Update(context.Background(), u, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		client.ClearActions()
+
+		service.publishVMEvent(vmInfo)
+
+		for _, action := range client.Actions() {
+			Expect(action.GetVerb()).NotTo(Equal("patch"))
+		}
+		Expect(publisher.events).To(HaveLen(1))
+	})
+
+	It("should not record anything before the VM is running", func() {
+		vmInfo.Phase = VMPhaseScheduled
+		service.publishVMEvent(vmInfo)
+
+		Expect(annotations()).To(Equal(map[string]string{constants.DCMAnnotationRequestID: "req-1"}))
+	})
+
+	It("should still publish the event when the VM cannot be annotated", func() {
+		vmInfo.VMName = "missing"
+		service.publishVMEvent(vmInfo)
+
+		Expect(publisher.events).To(HaveLen(1))
+	})
+})
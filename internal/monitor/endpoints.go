@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/events"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+// sshPort is the guest port an SSH command is reported for
+const sshPort = 22
+
+var serviceGVR = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+
+// portService returns the port Service of a VM, or nil when it has none. A
+// Service that cannot be read is logged and treated as missing, so that the
+// event is still published.
+func (s *Service) portService(ctx context.Context, vmInfo VMInfo) *k8sv1.Service {
+	u, err := s.dynamicClient.Resource(serviceGVR).Namespace(vmInfo.Namespace).Get(ctx, kubevirt.PortServiceName(vmInfo.VMID), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		zap.S().Warnw("Failed to get port Service of VM", "vmID", vmInfo.VMID, "error", err)
+		return nil
+	}
+	service := &k8sv1.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, service); err != nil {
+		zap.S().Warnw("Failed to convert port Service of VM", "vmID", vmInfo.VMID, "error", err)
+		return nil
+	}
+	return service
+}
+
+// vmEndpoints returns the addresses a running VM can be reached at: its IPs
+// and the guest ports published by its port Service, if it has one
+func vmEndpoints(vmInfo VMInfo, service *k8sv1.Service) []events.Endpoint {
+	endpoints := make([]events.Endpoint, 0, len(vmInfo.IPs))
+	for _, ip := range vmInfo.IPs {
+		endpoints = append(endpoints, events.Endpoint{Type: "IP", Host: ip})
+	}
+	if service == nil {
+		return endpoints
+	}
+	return append(endpoints, serviceEndpoints(service)...)
+}
+
+// endpointsKey identifies a list of endpoints, so that a change is noticed
+func endpointsKey(endpoints []events.Endpoint) string {
+	return fmt.Sprint(endpoints)
+}
+
+// serviceEndpoints lists the guest ports published by a port Service. The load
+// balancer address is included once it is assigned, and the cluster IP of a
+// ClusterIP Service.
+func serviceEndpoints(service *k8sv1.Service) []events.Endpoint {
+	host := serviceHost(service)
+	endpoints := make([]events.Endpoint, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		endpoint := events.Endpoint{
+			Type:     string(service.Spec.Type),
+			Host:     host,
+			Port:     port.TargetPort.IntValue(),
+			NodePort: int(port.NodePort),
+			Protocol: string(port.Protocol),
+		}
+		if endpoint.Port == sshPort && host != "" {
+			endpoint.SSHCommand = fmt.Sprintf("ssh -p %d %s", port.Port, host)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// serviceHost returns the address a port Service publishes its ports at: the
+// load balancer address once it is assigned, or the cluster IP of a ClusterIP
+// Service. It is empty for NodePort Services, which publish on every node.
+func serviceHost(service *k8sv1.Service) string {
+	switch service.Spec.Type {
+	case k8sv1.ServiceTypeLoadBalancer:
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return ingress.IP
+			}
+			if ingress.Hostname != "" {
+				return ingress.Hostname
+			}
+		}
+	case k8sv1.ServiceTypeClusterIP:
+		if service.Spec.ClusterIP != k8sv1.ClusterIPNone {
+			return service.Spec.ClusterIP
+		}
+	}
+	return ""
+}
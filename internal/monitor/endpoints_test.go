@@ -0,0 +1,118 @@
+package monitor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/events"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+var _ = Describe("Endpoint reporting", func() {
+	var (
+		publisher *recordingPublisher
+		service   *Service
+	)
+
+	var client *dynamicfake.FakeDynamicClient
+
+	BeforeEach(func() {
+		client = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{serviceGVR: "ServiceList"})
+		portService := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": kubevirt.PortServiceName("vm-123"), "namespace": "default"},
+			"spec": map[string]interface{}{
+				"type": "LoadBalancer",
+				"ports": []interface{}{
+					map[string]interface{}{"name": "ssh", "port": int64(2222), "targetPort": int64(22), "nodePort": int64(30022), "protocol": "TCP"},
+				},
+			},
+			"status": map[string]interface{}{"loadBalancer": map[string]interface{}{
+				"ingress": []interface{}{map[string]interface{}{"ip": "203.0.113.10"}},
+			}},
+		}}
+		_, err := client.Resource(serviceGVR).Namespace("default").Create(context.Background(), portService, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		publisher = &recordingPublisher{}
+		service = &Service{
+			ctx:             context.Background(),
+			dynamicClient:   client,
+			publisher:       publisher,
+			reportEndpoints: true,
+		}
+	})
+
+	It("should report the endpoints of a VM on its Running transition", func() {
+		service.publishVMEvent(VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseScheduled, IPs: []string{"10.244.0.12"}})
+		service.publishVMEvent(VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseRunning, IPs: []string{"10.244.0.12"}})
+
+		Expect(publisher.events).To(HaveLen(2))
+		Expect(publisher.events[0].Endpoints).To(BeEmpty())
+		Expect(publisher.events[1].Endpoints).To(Equal([]events.Endpoint{
+			{Type: "IP", Host: "10.244.0.12"},
+			{
+				Type:       "LoadBalancer",
+				Host:       "203.0.113.10",
+				Port:       22,
+				NodePort:   30022,
+				Protocol:   "TCP",
+				SSHCommand: "ssh -p 2222 203.0.113.10",
+			},
+		}))
+	})
+
+	It("should report the endpoints again once the VM gets an IP after running", func() {
+		service.publishVMEvent(VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseRunning})
+		service.publishVMEvent(VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseRunning})
+		service.publishVMEvent(VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseRunning, IPs: []string{"10.244.0.12"}})
+
+		Expect(publisher.events).To(HaveLen(2))
+		Expect(publisher.events[0].Endpoints).NotTo(ContainElement(events.Endpoint{Type: "IP", Host: "10.244.0.12"}))
+		Expect(publisher.events[1].Status).To(Equal("Running"))
+		Expect(publisher.events[1].Endpoints).To(ContainElement(events.Endpoint{Type: "IP", Host: "10.244.0.12"}))
+	})
+
+	It("should report the endpoints again once the load balancer address is assigned", func() {
+		vmInfo := VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseRunning, IPs: []string{"10.244.0.12"}}
+		services := client.Resource(serviceGVR).Namespace("default")
+		portService, err := services.Get(context.Background(), kubevirt.PortServiceName("vm-123"), metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		unstructured.RemoveNestedField(portService.Object, "status")
+		_, err = services.Update(context.Background(), portService, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		service.publishVMEvent(vmInfo)
+
+		Expect(unstructured.SetNestedSlice(portService.Object, []interface{}{map[string]interface{}{"ip": "203.0.113.10"}}, "status", "loadBalancer", "ingress")).To(Succeed())
+		_, err = services.Update(context.Background(), portService, metav1.UpdateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		service.publishVMEvent(vmInfo)
+
+		Expect(publisher.events).To(HaveLen(2))
+		Expect(publisher.events[1].Endpoints).To(ContainElement(HaveField("Host", "203.0.113.10")))
+	})
+
+	It("should report only the IPs of a VM without published ports", func() {
+		service.publishVMEvent(VMInfo{VMID: "vm-456", Namespace: "default", Phase: VMPhaseRunning, IPs: []string{"10.244.0.13"}})
+
+		Expect(publisher.events).To(HaveLen(1))
+		Expect(publisher.events[0].Endpoints).To(Equal([]events.Endpoint{{Type: "IP", Host: "10.244.0.13"}}))
+	})
+
+	It("should not report endpoints unless enabled", func() {
+		service.reportEndpoints = false
+		service.publishVMEvent(VMInfo{VMID: "vm-123", Namespace: "default", Phase: VMPhaseRunning, IPs: []string{"10.244.0.12"}})
+
+		Expect(publisher.events).To(HaveLen(1))
+		Expect(publisher.events[0].Endpoints).To(BeNil())
+	})
+})
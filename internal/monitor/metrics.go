@@ -0,0 +1,95 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/events"
+)
+
+// Publish failure reasons reported in Stats and the failed events metric
+const (
+	FailureReasonNotConnected = "not_connected"
+	FailureReasonTimeout      = "timeout"
+	FailureReasonPublish      = "publish_error"
+	FailureReasonMarshal      = "marshal_error"
+)
+
+var (
+	eventsPublishedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "kubevirt_provider",
+		Subsystem: "monitor",
+		Name:      "events_published_total",
+		Help:      "Number of VM events successfully published.",
+	})
+	eventsFailedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "kubevirt_provider",
+		Subsystem: "monitor",
+		Name:      "events_failed_total",
+		Help:      "Number of VM events that could not be published, by reason.",
+	}, []string{"reason"})
+	trackedVMs = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "kubevirt_provider",
+		Subsystem: "monitor",
+		Name:      "tracked_vms",
+		Help:      "Number of VMs whose last published phase is tracked.",
+	})
+)
+
+// Stats is a snapshot of the monitor's event publishing counters
+type Stats struct {
+	EventsPublished uint64
+	EventsFailed    map[string]uint64
+}
+
+// publishStats tracks publish outcomes for a single Service
+type publishStats struct {
+	mu        sync.Mutex
+	published uint64
+	failed    map[string]uint64
+}
+
+func (p *publishStats) recordSuccess() {
+	p.mu.Lock()
+	p.published++
+	p.mu.Unlock()
+	eventsPublishedTotal.Inc()
+}
+
+func (p *publishStats) recordFailure(reason string) {
+	p.mu.Lock()
+	if p.failed == nil {
+		p.failed = make(map[string]uint64)
+	}
+	p.failed[reason]++
+	p.mu.Unlock()
+	eventsFailedTotal.WithLabelValues(reason).Inc()
+}
+
+func (p *publishStats) snapshot() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	failed := make(map[string]uint64, len(p.failed))
+	for reason, count := range p.failed {
+		failed[reason] = count
+	}
+	return Stats{EventsPublished: p.published, EventsFailed: failed}
+}
+
+// failureReason classifies a publish error for metrics
+func failureReason(err error) string {
+	switch {
+	case errors.Is(err, events.ErrNotConnected):
+		return FailureReasonNotConnected
+	case errors.Is(err, events.ErrFlushTimeout), errors.Is(err, context.DeadlineExceeded):
+		return FailureReasonTimeout
+	case errors.Is(err, events.ErrMarshal):
+		return FailureReasonMarshal
+	default:
+		return FailureReasonPublish
+	}
+}
@@ -2,8 +2,9 @@ package monitor
 
 import (
 	"fmt"
-	"log"
 
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
@@ -35,6 +36,30 @@ type VMInfo struct {
 	VMName    string
 	Namespace string
 	Phase     VMPhase
+	// IPs are the addresses reported for the VM's interfaces
+	IPs []string
+	// SSHSecretName names the Secret the VM's SSH public keys are propagated from
+	SSHSecretName string
+	// SSHUser is the guest user the SSH public keys are installed for, when
+	// they are propagated through the guest agent
+	SSHUser string
+	// AgentConnected reports whether the guest agent of the VM is connected
+	AgentConnected bool
+}
+
+// PhaseChange is a VM moving from the last phase published for it to its
+// current phase
+type PhaseChange struct {
+	VMID string
+	// From is empty when no phase was published for the VM yet
+	From VMPhase
+	To   VMPhase
+}
+
+// IsSignificant reports whether the change is a status transition worth
+// publishing: the first phase seen for a VM, or a move to a different phase.
+func (c PhaseChange) IsSignificant() bool {
+	return c.From != c.To
 }
 
 // ExtractVMInfo extracts phase and identifying information from a VMI object
@@ -43,12 +68,57 @@ func ExtractVMInfo(vmi *kubevirtv1.VirtualMachineInstance) (VMInfo, error) {
 		return VMInfo{}, fmt.Errorf("VMI object is nil")
 	}
 
-	return VMInfo{
+	info := VMInfo{
 		VMID:      vmi.Labels[constants.DCMLabelInstanceID],
 		VMName:    vmi.Name,
 		Namespace: vmi.Namespace,
 		Phase:     mapVMIPhase(vmi.Status.Phase),
-	}, nil
+		IPs:       interfaceIPs(vmi),
+	}
+	info.SSHSecretName, info.SSHUser = sshAccess(vmi)
+	info.AgentConnected = agentConnected(vmi)
+	return info, nil
+}
+
+// agentConnected reports whether the AgentConnected condition of a VMI is true
+func agentConnected(vmi *kubevirtv1.VirtualMachineInstance) bool {
+	for _, c := range vmi.Status.Conditions {
+		if c.Type == kubevirtv1.VirtualMachineInstanceAgentConnected {
+			return c.Status == k8sv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// sshAccess returns the Secret and guest user of the first SSH access
+// credential of a VMI, if any
+func sshAccess(vmi *kubevirtv1.VirtualMachineInstance) (secretName, user string) {
+	for _, cred := range vmi.Spec.AccessCredentials {
+		ssh := cred.SSHPublicKey
+		if ssh == nil || ssh.Source.Secret == nil {
+			continue
+		}
+		if agent := ssh.PropagationMethod.QemuGuestAgent; agent != nil && len(agent.Users) > 0 {
+			user = agent.Users[0]
+		}
+		return ssh.Source.Secret.SecretName, user
+	}
+	return "", ""
+}
+
+// interfaceIPs returns the distinct IPs reported for the interfaces of a VMI
+func interfaceIPs(vmi *kubevirtv1.VirtualMachineInstance) []string {
+	var ips []string
+	seen := map[string]bool{}
+	for _, iface := range vmi.Status.Interfaces {
+		for _, ip := range append([]string{iface.IP}, iface.IPs...) {
+			if ip != "" && !seen[ip] {
+				seen[ip] = true
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
 }
 
 // mapVMIPhase maps KubeVirt VMI phase to our VMPhase constants
@@ -69,7 +139,7 @@ func mapVMIPhase(phase kubevirtv1.VirtualMachineInstancePhase) VMPhase {
 	case kubevirtv1.Unknown:
 		return VMPhaseUnknown
 	default:
-		log.Printf("Warning: Unknown VMI phase '%s', mapping to Unknown", phase)
+		zap.S().Warnw("Unknown VMI phase, mapping to Unknown", "phase", phase)
 		return VMPhaseUnknown
 	}
 }
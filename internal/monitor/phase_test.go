@@ -5,6 +5,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	k8sv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
@@ -49,6 +50,62 @@ var _ = Describe("Phase", func() {
 			Expect(info.Phase).To(Equal(VMPhaseRunning))
 		})
 
+		It("should extract the distinct interface IPs", func() {
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				Status: kubevirtv1.VirtualMachineInstanceStatus{
+					Phase: kubevirtv1.Running,
+					Interfaces: []kubevirtv1.VirtualMachineInstanceNetworkInterface{
+						{IP: "10.244.0.12", IPs: []string{"10.244.0.12", "fd00::12"}},
+						{Name: "secondary"},
+					},
+				},
+			}
+
+			info, err := ExtractVMInfo(vmi)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.IPs).To(Equal([]string{"10.244.0.12", "fd00::12"}))
+		})
+
+		It("should extract the SSH secret and guest agent user", func() {
+			vmi := &kubevirtv1.VirtualMachineInstance{
+				Spec: kubevirtv1.VirtualMachineInstanceSpec{
+					AccessCredentials: []kubevirtv1.AccessCredential{{
+						SSHPublicKey: &kubevirtv1.SSHPublicKeyAccessCredential{
+							Source: kubevirtv1.SSHPublicKeyAccessCredentialSource{
+								Secret: &kubevirtv1.AccessCredentialSecretSource{SecretName: "dcm-vm-123-ssh"},
+							},
+							PropagationMethod: kubevirtv1.SSHPublicKeyAccessCredentialPropagationMethod{
+								QemuGuestAgent: &kubevirtv1.QemuGuestAgentSSHPublicKeyAccessCredentialPropagation{
+									Users: []string{"fedora", "admin"},
+								},
+							},
+						},
+					}},
+				},
+			}
+
+			info, err := ExtractVMInfo(vmi)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.SSHSecretName).To(Equal("dcm-vm-123-ssh"))
+			Expect(info.SSHUser).To(Equal("fedora"))
+		})
+
+		It("should report whether the guest agent is connected", func() {
+			vmi := &kubevirtv1.VirtualMachineInstance{}
+			info, err := ExtractVMInfo(vmi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.AgentConnected).To(BeFalse())
+
+			vmi.Status.Conditions = []kubevirtv1.VirtualMachineInstanceCondition{
+				{Type: kubevirtv1.VirtualMachineInstanceAgentConnected, Status: k8sv1.ConditionTrue},
+			}
+			info, err = ExtractVMInfo(vmi)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.AgentConnected).To(BeTrue())
+		})
+
 		It("should return empty VMID when DCM label is missing", func() {
 			vmi := &kubevirtv1.VirtualMachineInstance{
 				ObjectMeta: metav1.ObjectMeta{
@@ -70,6 +127,17 @@ var _ = Describe("Phase", func() {
 		})
 	})
 
+	Describe("PhaseChange", func() {
+		DescribeTable("IsSignificant",
+			func(from, to VMPhase, expected bool) {
+				Expect(PhaseChange{VMID: "vm-123", From: from, To: to}.IsSignificant()).To(Equal(expected))
+			},
+			Entry("first phase of a VM", VMPhase(""), VMPhasePending, true),
+			Entry("move to another phase", VMPhaseScheduled, VMPhaseRunning, true),
+			Entry("repeated phase", VMPhaseRunning, VMPhaseRunning, false),
+		)
+	})
+
 	Describe("mapVMIPhase", func() {
 		DescribeTable("should map KubeVirt phases correctly",
 			func(input kubevirtv1.VirtualMachineInstancePhase, expected VMPhase) {
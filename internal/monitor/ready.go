@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	k8sv1 "k8s.io/api/core/v1"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/events"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+// readyConnection returns how to reach a VM once it is ready: running, with its
+// guest agent connected, an IP assigned and its SSH port published by its port
+// Service. It returns nil while any of these is missing.
+func readyConnection(vmInfo VMInfo, service *k8sv1.Service) *events.Connection {
+	if vmInfo.Phase != VMPhaseRunning || !vmInfo.AgentConnected || len(vmInfo.IPs) == 0 || service == nil {
+		return nil
+	}
+	host := serviceHost(service)
+	for _, port := range service.Spec.Ports {
+		if !kubevirt.IsSSHServicePort(port) {
+			continue
+		}
+		connection := &events.Connection{IP: vmInfo.IPs[0], SSHNodePort: int(port.NodePort)}
+		switch {
+		case host != "":
+			connection.SSHCommand = sshCommand(vmInfo.SSHUser, host, int(port.Port))
+		case service.Spec.Type == k8sv1.ServiceTypeNodePort && port.NodePort != 0:
+			// The VM IP is reached directly, on the port the guest listens on
+			connection.SSHCommand = sshCommand(vmInfo.SSHUser, vmInfo.IPs[0], port.TargetPort.IntValue())
+		default:
+			// The load balancer address is not assigned yet
+			return nil
+		}
+		return connection
+	}
+	return nil
+}
+
+// sshCommand returns the command connecting to an SSH server as the user, if known
+func sshCommand(user, host string, port int) string {
+	target := host
+	if user != "" {
+		target = user + "@" + host
+	}
+	if port == sshPort {
+		return "ssh " + target
+	}
+	return fmt.Sprintf("ssh -p %d %s", port, target)
+}
+
+// publishReadyEvent publishes the ready event of a VM once it is ready. The
+// event is published once per VM instance; a failed publish is retried on the
+// next event of the VM.
+func (s *Service) publishReadyEvent(ctx context.Context, vmInfo VMInfo, service *k8sv1.Service) {
+	connection := readyConnection(vmInfo, service)
+	if connection == nil {
+		return
+	}
+
+	vmEvent := events.VMEvent{
+		Type:       events.EventTypeReady,
+		Id:         vmInfo.VMID,
+		Status:     vmInfo.Phase.String(),
+		Timestamp:  time.Now(),
+		Connection: connection,
+	}
+	if err := s.publisher.PublishVMEvent(ctx, vmEvent); err != nil {
+		reason := failureReason(err)
+		s.stats.recordFailure(reason)
+		zap.S().Errorw("Failed to publish VM ready event",
+			"vmID", vmInfo.VMID,
+			"reason", reason,
+			"error", err,
+		)
+		return
+	}
+	s.stats.recordSuccess()
+	s.readyPublished.set(vmInfo.VMID)
+}
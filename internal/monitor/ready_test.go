@@ -0,0 +1,157 @@
+package monitor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/dcm-project/kubevirt-service-provider/internal/events"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
+)
+
+var _ = Describe("Ready events", func() {
+	var (
+		client    *dynamicfake.FakeDynamicClient
+		publisher *recordingPublisher
+		service   *Service
+		vmInfo    VMInfo
+
+		sshTargetPort int64
+	)
+
+	createPortService := func(serviceType string, status map[string]interface{}) {
+		portService := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]interface{}{"name": kubevirt.PortServiceName("vm-123"), "namespace": "default"},
+			"spec": map[string]interface{}{
+				"type": serviceType,
+				"ports": []interface{}{
+					map[string]interface{}{"name": "tcp-22", "port": int64(22), "targetPort": sshTargetPort, "nodePort": int64(30022), "protocol": "TCP"},
+				},
+			},
+			"status": status,
+		}}
+		_, err := client.Resource(serviceGVR).Namespace("default").Create(context.Background(), portService, metav1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	readyEvents := func() []events.VMEvent {
+		var ready []events.VMEvent
+		for _, e := range publisher.events {
+			if e.Type == events.EventTypeReady {
+				ready = append(ready, e)
+			}
+		}
+		return ready
+	}
+
+	BeforeEach(func() {
+		client = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{serviceGVR: "ServiceList"})
+		publisher = &recordingPublisher{}
+		service = &Service{
+			ctx:           context.Background(),
+			dynamicClient: client,
+			publisher:     publisher,
+			readyEvents:   true,
+		}
+		vmInfo = VMInfo{
+			VMID:      "vm-123",
+			VMName:    "dcm-vm-123",
+			Namespace: "default",
+			Phase:     VMPhaseRunning,
+			IPs:       []string{"10.244.0.12"},
+			SSHUser:   "fedora",
+		}
+		sshTargetPort = 22
+	})
+
+	It("should publish the ready event once with the connection details", func() {
+		createPortService("NodePort", nil)
+
+		service.publishVMEvent(vmInfo)
+		Expect(readyEvents()).To(BeEmpty())
+
+		vmInfo.AgentConnected = true
+		for range 3 {
+			service.publishVMEvent(vmInfo)
+		}
+
+		Expect(publisher.statuses()).To(Equal([]string{"vm-123=Running", "vm-123=Running"}))
+		ready := readyEvents()
+		Expect(ready).To(HaveLen(1))
+		Expect(ready[0].Connection).To(Equal(&events.Connection{
+			IP:          "10.244.0.12",
+			SSHCommand:  "ssh fedora@10.244.0.12",
+			SSHNodePort: 30022,
+		}))
+	})
+
+	It("should connect through the load balancer once its address is assigned", func() {
+		createPortService("LoadBalancer", map[string]interface{}{"loadBalancer": map[string]interface{}{
+			"ingress": []interface{}{map[string]interface{}{"ip": "203.0.113.10"}},
+		}})
+		vmInfo.AgentConnected = true
+
+		service.publishVMEvent(vmInfo)
+
+		ready := readyEvents()
+		Expect(ready).To(HaveLen(1))
+		Expect(ready[0].Connection.SSHCommand).To(Equal("ssh fedora@203.0.113.10"))
+	})
+
+	It("should connect to the port the guest listens on when SSH is forwarded elsewhere", func() {
+		sshTargetPort = 2222
+		createPortService("NodePort", nil)
+		vmInfo.AgentConnected = true
+
+		service.publishVMEvent(vmInfo)
+
+		ready := readyEvents()
+		Expect(ready).To(HaveLen(1))
+		Expect(ready[0].Connection).To(Equal(&events.Connection{
+			IP:          "10.244.0.12",
+			SSHCommand:  "ssh -p 2222 fedora@10.244.0.12",
+			SSHNodePort: 30022,
+		}))
+	})
+
+	It("should wait for every readiness condition", func() {
+		createPortService("LoadBalancer", nil)
+		vmInfo.AgentConnected = true
+		service.publishVMEvent(vmInfo)
+
+		noIP := vmInfo
+		noIP.VMID, noIP.IPs = "vm-456", nil
+		service.publishVMEvent(noIP)
+
+		Expect(readyEvents()).To(BeEmpty())
+		Expect(publisher.statuses()).To(Equal([]string{"vm-123=Running", "vm-456=Running"}))
+	})
+
+	It("should publish the ready event of a restarted VM again", func() {
+		createPortService("NodePort", nil)
+		vmInfo.AgentConnected = true
+		service.publishVMEvent(vmInfo)
+		service.readyPublished.delete("vm-123")
+		service.publishVMEvent(vmInfo)
+
+		Expect(readyEvents()).To(HaveLen(2))
+	})
+
+	It("should not publish ready events unless enabled", func() {
+		createPortService("NodePort", nil)
+		service.readyEvents = false
+		vmInfo.AgentConnected = true
+		service.publishVMEvent(vmInfo)
+
+		Expect(readyEvents()).To(BeEmpty())
+	})
+})
@@ -3,9 +3,13 @@ package monitor
 import (
 	"context"
 	"fmt"
-	"log"
+	"hash/fnv"
+	"sync"
 	"time"
 
+	"go.uber.org/zap"
+
+	k8sv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -18,19 +22,41 @@ import (
 
 	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
 	"github.com/dcm-project/kubevirt-service-provider/internal/events"
+	"github.com/dcm-project/kubevirt-service-provider/internal/kubevirt"
 )
 
 // Service monitors VM status changes and publishes events
 type Service struct {
 	dynamicClient   dynamic.Interface
 	namespace       string
-	publisher       *events.Publisher
+	publisher       events.Publisher
 	informerFactory dynamicinformer.DynamicSharedInformerFactory
 	vmiInformer     cache.SharedIndexInformer
 	resyncPeriod    time.Duration
+	workers         int
+	queues          []chan VMInfo
 	ctx             context.Context
+	stats           publishStats
+	reportEndpoints bool
+	// persistConnectionInfo records how a running VM is reached on the VM
+	persistConnectionInfo bool
+	// readyEvents publishes a ready event once a running VM is reachable
+	readyEvents bool
+
+	// published holds the last phase successfully published per VM ID
+	published trackedPhases
+	// readyPublished holds the VM IDs whose ready event was published
+	readyPublished trackedSet
+	// connections holds the connection info last recorded on each VM
+	connections tracked[kubevirt.ConnectionInfo]
+	// endpoints holds the endpoints last published for each VM
+	endpoints tracked[string]
 }
 
+// workerQueueSize is the number of events buffered per publishing worker
+// before the informer waits for it
+const workerQueueSize = 64
+
 var (
 	virtualMachineInstanceGVR = schema.GroupVersionResource{
 		Group:    "kubevirt.io",
@@ -39,28 +65,65 @@ var (
 	}
 )
 
+// DefaultLabelSelector selects the VMIs of DCM-managed VMs
+const DefaultLabelSelector = constants.DCMLabelManagedBy + "=" + constants.DCMManagedByValue
+
 // MonitorConfig contains configuration for the monitoring service
 type MonitorConfig struct {
 	Namespace    string
 	ResyncPeriod time.Duration
+	// Workers is the number of events published concurrently; defaults to 1
+	Workers int
+	// LabelSelector restricts the VMIs the informer lists and watches;
+	// defaults to DefaultLabelSelector
+	LabelSelector string
+	// MaxTrackedVMs bounds the VMs whose last published phase is remembered;
+	// defaults to DefaultMaxTrackedVMs
+	MaxTrackedVMs int
+	// ReportEndpoints adds the addresses of a VM to the event of its Running
+	// phase, which is published again when they change
+	ReportEndpoints bool
+	// PersistConnectionInfo annotates a VM with its IP and SSH access details
+	// once it is running
+	PersistConnectionInfo bool
+	// ReadyEvents publishes a ready event with the connection details of a VM
+	// once it is running, its guest agent is connected, it has an IP and its
+	// SSH port is published
+	ReadyEvents bool
 }
 
 // NewMonitorService creates a new VM monitoring service
-func NewMonitorService(dynamicClient dynamic.Interface, publisher *events.Publisher, config MonitorConfig) *Service {
+func NewMonitorService(dynamicClient dynamic.Interface, publisher events.Publisher, config MonitorConfig) *Service {
 	service := &Service{
-		dynamicClient: dynamicClient,
-		namespace:     config.Namespace,
-		publisher:     publisher,
-		resyncPeriod:  config.ResyncPeriod,
+		dynamicClient:         dynamicClient,
+		namespace:             config.Namespace,
+		publisher:             publisher,
+		resyncPeriod:          config.ResyncPeriod,
+		workers:               max(config.Workers, 1),
+		reportEndpoints:       config.ReportEndpoints,
+		persistConnectionInfo: config.PersistConnectionInfo,
+		readyEvents:           config.ReadyEvents,
+	}
+	service.published.size = trackedVMs
+	service.published.max = config.MaxTrackedVMs
+	if service.published.max <= 0 {
+		service.published.max = DefaultMaxTrackedVMs
 	}
+	service.readyPublished.max = service.published.max
+	service.connections.max = service.published.max
+	service.endpoints.max = service.published.max
 
-	// Create informer factory
+	// Filter on the API server so unmanaged workloads are never cached
+	labelSelector := config.LabelSelector
+	if labelSelector == "" {
+		labelSelector = DefaultLabelSelector
+	}
 	service.informerFactory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(
 		dynamicClient,
 		config.ResyncPeriod,
 		config.Namespace,
 		func(options *metav1.ListOptions) {
-			options.LabelSelector = fmt.Sprintf("%s=%s", constants.DCMLabelManagedBy, constants.DCMManagedByValue)
+			options.LabelSelector = labelSelector
 		},
 	)
 
@@ -79,31 +142,57 @@ func (s *Service) setupInformers() {
 			s.handleVMEvent(obj, "created")
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
+			// Updates that leave the phase as it was are passed on too:
+			// publishVMEvent skips phases already published, so they retry
+			// failed publishes and complete the readiness of running VMs
 			s.handleVMEvent(newObj, "updated")
 		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				vmID := u.GetLabels()[constants.DCMLabelInstanceID]
+				s.forgetPublished(vmID)
+				s.readyPublished.delete(vmID)
+				s.connections.delete(vmID)
+				s.endpoints.delete(vmID)
+			}
+		},
 	})
 }
 
 // Run starts the monitoring service
 func (s *Service) Run(ctx context.Context) error {
 	s.ctx = ctx
-	log.Printf("Starting KubeVirt VM monitoring service in namespace %s", s.namespace)
+	zap.S().Infow("Starting KubeVirt VM monitoring service", "namespace", s.namespace, "workers", s.workers)
+
+	// Publish from a bounded pool of workers so a slow event bus does not
+	// stall the informer; wait for them so no publish outlives Run
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	s.startWorkers(ctx, &wg)
 
-	// Start informers
+	// Start informers, and wait for their goroutines to stop before returning
 	s.informerFactory.Start(ctx.Done())
+	defer s.informerFactory.Shutdown()
 
 	// Wait for cache sync
-	log.Printf("Waiting for informer caches to sync...")
+	zap.S().Info("Waiting for informer caches to sync")
 	if !cache.WaitForCacheSync(ctx.Done(), s.vmiInformer.HasSynced) {
+		// A cancelled context aborts the sync; that is a shutdown, not a failure
+		if ctx.Err() != nil {
+			zap.S().Infow("KubeVirt VM monitoring service stopped before informer caches synced", "reason", ctx.Err())
+			return nil
+		}
 		return fmt.Errorf("failed to sync informer caches")
 	}
 
-	log.Printf("Informer caches synced successfully")
-	log.Printf("KubeVirt VM monitoring service is running")
+	zap.S().Info("Informer caches synced, KubeVirt VM monitoring service is running")
 
 	// Wait for context cancellation
 	<-ctx.Done()
-	log.Printf("Stopping KubeVirt VM monitoring service")
+	zap.S().Info("Stopping KubeVirt VM monitoring service")
 	return nil
 }
 
@@ -111,48 +200,159 @@ func (s *Service) Run(ctx context.Context) error {
 func (s *Service) handleVMEvent(obj interface{}, eventType string) {
 	u, ok := obj.(*unstructured.Unstructured)
 	if !ok {
-		log.Printf("Warning: handleVMEvent received non-unstructured object")
+		zap.S().Warnw("Ignoring non-unstructured object", "type", fmt.Sprintf("%T", obj))
 		return
 	}
 
 	// Convert unstructured to typed VMI at the informer boundary
 	vmi := &kubevirtv1.VirtualMachineInstance{}
 	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, vmi); err != nil {
-		log.Printf("Error converting unstructured to VirtualMachineInstance: %v", err)
+		zap.S().Errorw("Failed to convert unstructured to VirtualMachineInstance", "error", err)
 		return
 	}
 
 	// If the VMI don't contain ID skip the VM event
 	if vmi.Labels[constants.DCMLabelInstanceID] == "" {
-		log.Printf("Warning: VMI %s does not contain DCM instance ID", vmi.Name)
+		zap.S().Warnw("VMI does not contain DCM instance ID", "vmi", vmi.Name)
 		return
 	}
 
 	// Extract VM information
 	vmInfo, err := ExtractVMInfo(vmi)
 	if err != nil {
-		log.Printf("Error extracting VM info: %v", err)
+		zap.S().Errorw("Failed to extract VM info", "error", err)
 		return
 	}
 
-	log.Printf("VM %s: %s (ID: %s) with phase %s", eventType, vmInfo.VMName, vmInfo.VMID, vmInfo.Phase)
+	zap.S().Infow("VM event", "event", eventType, "vm", vmInfo.VMName, "vmID", vmInfo.VMID, "phase", vmInfo.Phase)
 
 	// Publish current VM state
-	s.publishVMEvent(vmInfo)
+	s.dispatch(vmInfo)
+}
+
+// startWorkers starts the publishing workers, each draining its own queue
+func (s *Service) startWorkers(ctx context.Context, wg *sync.WaitGroup) {
+	s.queues = make([]chan VMInfo, s.workers)
+	for i := range s.queues {
+		queue := make(chan VMInfo, workerQueueSize)
+		s.queues[i] = queue
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case vmInfo := <-queue:
+					s.publishVMEvent(vmInfo)
+				}
+			}
+		}()
+	}
 }
 
-// publishVMEvent publishes the current VM state
+// dispatch hands an event to the worker owning its VM, so events of one VM
+// are published in order while different VMs are published concurrently.
+// Without workers, the event is published directly.
+func (s *Service) dispatch(vmInfo VMInfo) {
+	if len(s.queues) == 0 {
+		s.publishVMEvent(vmInfo)
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(vmInfo.VMID))
+	select {
+	case s.queues[h.Sum32()%uint32(len(s.queues))] <- vmInfo:
+	case <-s.ctx.Done():
+	}
+}
+
+// publishVMEvent publishes the current VM state, unless that state was the
+// last one published for the VM. Informer relists replay every VMI, and a
+// phase that was already reported is not a status transition. The addresses
+// of a running VM are usually assigned after it started running, so its
+// connection info and endpoints are compared on every event and updated when
+// they changed. When enabled, the ready event of a running VM follows once it
+// is reachable.
 func (s *Service) publishVMEvent(vmInfo VMInfo) {
+	change := PhaseChange{VMID: vmInfo.VMID, From: s.lastPublished(vmInfo.VMID), To: vmInfo.Phase}
+	running := vmInfo.Phase == VMPhaseRunning
+	awaitingReady := s.readyEvents && running && !s.readyPublished.has(vmInfo.VMID)
+	tracksAddresses := running && (s.reportEndpoints || s.persistConnectionInfo)
+	if !change.IsSignificant() && !awaitingReady && !tracksAddresses {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	defer cancel()
+
+	var service *k8sv1.Service
+	if tracksAddresses || awaitingReady {
+		service = s.portService(ctx, vmInfo)
+	}
+
+	if running && s.persistConnectionInfo {
+		s.recordConnectionInfo(ctx, vmInfo, service)
+	}
+	endpointsChanged := running && s.reportEndpoints && s.endpoints.get(vmInfo.VMID) != endpointsKey(vmEndpoints(vmInfo, service))
+	if (change.IsSignificant() || endpointsChanged) && !s.publishStatusEvent(ctx, vmInfo, service) {
+		return
+	}
+	if awaitingReady {
+		s.publishReadyEvent(ctx, vmInfo, service)
+	}
+}
+
+// publishStatusEvent publishes the phase of a VM, reporting whether it was
+// published
+func (s *Service) publishStatusEvent(ctx context.Context, vmInfo VMInfo, service *k8sv1.Service) bool {
 	vmEvent := events.VMEvent{
 		Id:        vmInfo.VMID,
 		Status:    vmInfo.Phase.String(),
 		Timestamp: time.Now(),
 	}
-
-	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
-	defer cancel()
+	if vmInfo.Phase == VMPhaseRunning && s.reportEndpoints {
+		vmEvent.Endpoints = vmEndpoints(vmInfo, service)
+	}
 
 	if err := s.publisher.PublishVMEvent(ctx, vmEvent); err != nil {
-		log.Printf("Error publishing VM event for %s: %v", vmInfo.VMID, err)
+		reason := failureReason(err)
+		s.stats.recordFailure(reason)
+		zap.S().Errorw("Failed to publish VM event",
+			"vmID", vmInfo.VMID,
+			"phase", vmInfo.Phase,
+			"reason", reason,
+			"error", err,
+		)
+		return false
+	}
+	s.stats.recordSuccess()
+	s.recordPublished(vmInfo)
+	if vmEvent.Endpoints != nil {
+		s.endpoints.set(vmInfo.VMID, endpointsKey(vmEvent.Endpoints))
 	}
+	return true
+}
+
+// lastPublished returns the last phase published for a VM, if any
+func (s *Service) lastPublished(vmID string) VMPhase {
+	return s.published.get(vmID)
+}
+
+// recordPublished remembers the phase just published for a VM. Failed
+// publishes are not recorded, so the next event for the VM retries them.
+func (s *Service) recordPublished(vmInfo VMInfo) {
+	s.published.set(vmInfo.VMID, vmInfo.Phase)
+}
+
+// forgetPublished drops the tracked phase of a VM whose VMI was deleted, so
+// the phases of a restarted VM are published again
+func (s *Service) forgetPublished(vmID string) {
+	s.published.delete(vmID)
+}
+
+// GetStats returns a snapshot of the event publishing counters
+func (s *Service) GetStats() Stats {
+	return s.stats.snapshot()
 }
@@ -2,6 +2,10 @@ package monitor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	goruntime "runtime"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -9,13 +13,52 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 	kubevirtv1 "kubevirt.io/api/core/v1"
 
 	"github.com/dcm-project/kubevirt-service-provider/internal/constants"
 	"github.com/dcm-project/kubevirt-service-provider/internal/events"
 )
 
+// recordingPublisher records published events or fails with publishErr
+type recordingPublisher struct {
+	mu         sync.Mutex
+	events     []events.VMEvent
+	publishErr error
+}
+
+func (p *recordingPublisher) PublishVMEvent(_ context.Context, vmEvent events.VMEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.publishErr != nil {
+		return p.publishErr
+	}
+	p.events = append(p.events, vmEvent)
+	return nil
+}
+
+// failWith makes later publishes fail with err, or succeed when it is nil
+func (p *recordingPublisher) failWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.publishErr = err
+}
+
+func (p *recordingPublisher) IsConnected() bool { return true }
+func (p *recordingPublisher) Close() error      { return nil }
+
+func (p *recordingPublisher) statuses() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	statuses := make([]string, 0, len(p.events))
+	for _, e := range p.events {
+		statuses = append(statuses, e.Id+"="+e.Status)
+	}
+	return statuses
+}
+
 var _ = Describe("Service", func() {
 	Describe("handleVMEvent", func() {
 		var service *Service
@@ -23,7 +66,7 @@ var _ = Describe("Service", func() {
 		BeforeEach(func() {
 			service = &Service{
 				ctx:       context.Background(),
-				publisher: &events.Publisher{},
+				publisher: &events.NATSPublisher{},
 				namespace: "default",
 			}
 		})
@@ -90,10 +133,79 @@ var _ = Describe("Service", func() {
 	})
 
 	Describe("publishVMEvent", func() {
+		var (
+			publisher *recordingPublisher
+			service   *Service
+		)
+
+		BeforeEach(func() {
+			publisher = &recordingPublisher{}
+			service = &Service{
+				ctx:       context.Background(),
+				publisher: publisher,
+				namespace: "default",
+			}
+		})
+
+		running := VMInfo{VMID: "vm-123", VMName: "test-vm", Namespace: "default", Phase: VMPhaseRunning}
+
+		It("should publish the same phase of a VM only once", func() {
+			service.publishVMEvent(running)
+			service.publishVMEvent(running)
+
+			Expect(publisher.statuses()).To(Equal([]string{"vm-123=Running"}))
+		})
+
+		It("should publish repeated identical VMI events once", func() {
+			vmi := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "kubevirt.io/v1",
+				"kind":       "VirtualMachineInstance",
+				"metadata": map[string]interface{}{
+					"name":      "test-vmi",
+					"namespace": "default",
+					"labels":    map[string]interface{}{constants.DCMLabelInstanceID: "vm-123"},
+				},
+				"status": map[string]interface{}{"phase": "Running"},
+			}}
+			for range 5 {
+				service.handleVMEvent(vmi, "updated")
+			}
+
+			Expect(publisher.statuses()).To(Equal([]string{"vm-123=Running"}))
+		})
+
+		It("should publish every phase transition", func() {
+			service.publishVMEvent(VMInfo{VMID: "vm-123", Phase: VMPhaseScheduling})
+			service.publishVMEvent(running)
+			service.publishVMEvent(VMInfo{VMID: "vm-123", Phase: VMPhaseFailed})
+			service.publishVMEvent(VMInfo{VMID: "vm-456", Phase: VMPhaseRunning})
+
+			Expect(publisher.statuses()).To(Equal([]string{
+				"vm-123=Scheduling", "vm-123=Running", "vm-123=Failed", "vm-456=Running",
+			}))
+		})
+
+		It("should retry a phase whose publish failed", func() {
+			publisher.publishErr = events.ErrPublishFailed
+			service.publishVMEvent(running)
+			publisher.publishErr = nil
+			service.publishVMEvent(running)
+
+			Expect(publisher.statuses()).To(Equal([]string{"vm-123=Running"}))
+		})
+
+		It("should publish again once the VM was forgotten", func() {
+			service.publishVMEvent(running)
+			service.forgetPublished("vm-123")
+			service.publishVMEvent(running)
+
+			Expect(publisher.statuses()).To(HaveLen(2))
+		})
+
 		It("should not panic when publisher has nil natsConn", func() {
 			service := &Service{
 				ctx:       context.Background(),
-				publisher: &events.Publisher{},
+				publisher: &events.NATSPublisher{},
 				namespace: "default",
 			}
 
@@ -108,12 +220,280 @@ var _ = Describe("Service", func() {
 				service.publishVMEvent(vmInfo)
 			}).NotTo(Panic())
 		})
+
+		It("should count the event as failed when the publisher is not connected", func() {
+			service := &Service{
+				ctx:       context.Background(),
+				publisher: &events.NATSPublisher{},
+				namespace: "default",
+			}
+
+			vmInfo := VMInfo{
+				VMID:      "vm-123",
+				VMName:    "test-vm",
+				Namespace: "default",
+				Phase:     VMPhaseRunning,
+			}
+
+			service.publishVMEvent(vmInfo)
+			service.publishVMEvent(vmInfo)
+
+			stats := service.GetStats()
+			Expect(stats.EventsPublished).To(BeZero())
+			Expect(stats.EventsFailed).To(HaveKeyWithValue(FailureReasonNotConnected, uint64(2)))
+		})
+	})
+
+	Describe("failureReason", func() {
+		It("should classify publish errors", func() {
+			Expect(failureReason(events.ErrNotConnected)).To(Equal(FailureReasonNotConnected))
+			Expect(failureReason(fmt.Errorf("%w: %w", events.ErrFlushTimeout, errors.New("boom")))).To(Equal(FailureReasonTimeout))
+			Expect(failureReason(fmt.Errorf("publish: %w", context.DeadlineExceeded))).To(Equal(FailureReasonTimeout))
+			Expect(failureReason(fmt.Errorf("%w: boom", events.ErrMarshal))).To(Equal(FailureReasonMarshal))
+			Expect(failureReason(fmt.Errorf("%w: boom", events.ErrPublishFailed))).To(Equal(FailureReasonPublish))
+			Expect(failureReason(errors.New("boom"))).To(Equal(FailureReasonPublish))
+		})
+	})
+
+	Describe("Run", func() {
+		It("should stop all of its goroutines before returning", func() {
+			fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					virtualMachineInstanceGVR: "VirtualMachineInstanceList",
+				})
+			before := goruntime.NumGoroutine()
+			svc := NewMonitorService(fakeClient, &events.NATSPublisher{}, MonitorConfig{Namespace: "default", Workers: 4})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- svc.Run(ctx)
+			}()
+			Eventually(svc.vmiInformer.HasSynced).Should(BeTrue())
+			cancel()
+
+			var err error
+			Eventually(errCh, 2*time.Second).Should(Receive(&err))
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(goruntime.NumGoroutine, 2*time.Second).Should(BeNumerically("<=", before))
+		})
+
+		It("should return cleanly when the context is cancelled before caches sync", func() {
+			fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					virtualMachineInstanceGVR: "VirtualMachineInstanceList",
+				})
+			// Failing lists keep the informer from ever syncing
+			fakeClient.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, errors.New("list unavailable")
+			})
+			svc := NewMonitorService(fakeClient, &events.NATSPublisher{}, MonitorConfig{Namespace: "default"})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- svc.Run(ctx)
+			}()
+
+			Consistently(errCh, 100*time.Millisecond).ShouldNot(Receive())
+			cancel()
+
+			var err error
+			Eventually(errCh, 2*time.Second).Should(Receive(&err))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("shared VMI informer", func() {
+		newVMI := func(name, vmID string, phase kubevirtv1.VirtualMachineInstancePhase) *unstructured.Unstructured {
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "kubevirt.io/v1",
+				"kind":       "VirtualMachineInstance",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+					"labels": map[string]interface{}{
+						constants.DCMLabelManagedBy:  constants.DCMManagedByValue,
+						constants.DCMLabelInstanceID: vmID,
+					},
+				},
+				"status": map[string]interface{}{"phase": string(phase)},
+			}}
+		}
+
+		// Publishes fail without a NATS connection, so failures count attempts
+		publishAttempts := func(svc *Service) func() uint64 {
+			return func() uint64 {
+				return svc.GetStats().EventsFailed[FailureReasonNotConnected]
+			}
+		}
+
+		It("should publish each status change of every VMI exactly once", func() {
+			fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					virtualMachineInstanceGVR: "VirtualMachineInstanceList",
+				})
+			publisher := &recordingPublisher{}
+			svc := NewMonitorService(fakeClient, publisher, MonitorConfig{Namespace: "default"})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(svc.Run(ctx)).To(Succeed())
+			}()
+			Eventually(svc.vmiInformer.HasSynced).Should(BeTrue())
+
+			vmis := fakeClient.Resource(virtualMachineInstanceGVR).Namespace("default")
+			names := []string{"dcm-a", "dcm-b", "dcm-c"}
+			for i, name := range names {
+				_, err := vmis.Create(ctx, newVMI(name, fmt.Sprintf("vm-%d", i), kubevirtv1.Pending), metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Eventually(publisher.statuses).Should(HaveLen(3))
+
+			for i, name := range names {
+				_, err := vmis.Update(ctx, newVMI(name, fmt.Sprintf("vm-%d", i), kubevirtv1.Running), metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Eventually(publisher.statuses).Should(HaveLen(6))
+
+			// Updates that leave the phase unchanged are not status changes
+			for i, name := range names {
+				vmi := newVMI(name, fmt.Sprintf("vm-%d", i), kubevirtv1.Running)
+				vmi.SetAnnotations(map[string]string{"kubevirt.io/latest-observed-api-version": "v1"})
+				_, err := vmis.Update(ctx, vmi, metav1.UpdateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Consistently(publisher.statuses, 200*time.Millisecond).Should(HaveLen(6))
+		})
+
+		It("should retry a failed publish on the next update of the same phase", func() {
+			fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					virtualMachineInstanceGVR: "VirtualMachineInstanceList",
+				})
+			publisher := &recordingPublisher{}
+			publisher.failWith(events.ErrPublishFailed)
+			svc := NewMonitorService(fakeClient, publisher, MonitorConfig{Namespace: "default"})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(svc.Run(ctx)).To(Succeed())
+			}()
+			Eventually(svc.vmiInformer.HasSynced).Should(BeTrue())
+
+			vmis := fakeClient.Resource(virtualMachineInstanceGVR).Namespace("default")
+			_, err := vmis.Create(ctx, newVMI("dcm-a", "vm-0", kubevirtv1.Running), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() uint64 { return svc.GetStats().EventsFailed[FailureReasonPublish] }).Should(BeEquivalentTo(1))
+
+			publisher.failWith(nil)
+			vmi := newVMI("dcm-a", "vm-0", kubevirtv1.Running)
+			vmi.SetAnnotations(map[string]string{"kubevirt.io/latest-observed-api-version": "v1"})
+			_, err = vmis.Update(ctx, vmi, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(publisher.statuses).Should(Equal([]string{"vm-0=Running"}))
+		})
+
+		It("should handle the events of many VMs through a single watch", func() {
+			fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					virtualMachineInstanceGVR: "VirtualMachineInstanceList",
+				})
+			svc := NewMonitorService(fakeClient, &events.NATSPublisher{}, MonitorConfig{Namespace: "default", Workers: 4})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(svc.Run(ctx)).To(Succeed())
+			}()
+			Eventually(svc.vmiInformer.HasSynced).Should(BeTrue())
+
+			vmis := fakeClient.Resource(virtualMachineInstanceGVR).Namespace("default")
+			const count = 50
+			for i := range count {
+				_, err := vmis.Create(ctx, newVMI(fmt.Sprintf("dcm-%d", i), fmt.Sprintf("vm-%d", i), kubevirtv1.Running), metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Eventually(publishAttempts(svc)).Should(BeEquivalentTo(count))
+
+			watches := 0
+			for _, action := range fakeClient.Actions() {
+				if action.GetVerb() == "watch" {
+					watches++
+				}
+			}
+			Expect(watches).To(Equal(1))
+		})
+	})
+
+	Describe("label selector", func() {
+		newVMI := func(name, vmID string, labels map[string]interface{}) *unstructured.Unstructured {
+			labels[constants.DCMLabelInstanceID] = vmID
+			return &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "kubevirt.io/v1",
+				"kind":       "VirtualMachineInstance",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+					"labels":    labels,
+				},
+				"status": map[string]interface{}{"phase": "Running"},
+			}}
+		}
+
+		run := func(config MonitorConfig, objects ...runtime.Object) *recordingPublisher {
+			fakeClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{
+					virtualMachineInstanceGVR: "VirtualMachineInstanceList",
+				}, objects...)
+			publisher := &recordingPublisher{}
+			config.Namespace = "default"
+			svc := NewMonitorService(fakeClient, publisher, config)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			DeferCleanup(cancel)
+			go func() {
+				defer GinkgoRecover()
+				Expect(svc.Run(ctx)).To(Succeed())
+			}()
+			Eventually(svc.vmiInformer.HasSynced).Should(BeTrue())
+			return publisher
+		}
+
+		managed := map[string]interface{}{constants.DCMLabelManagedBy: constants.DCMManagedByValue}
+
+		It("should only handle DCM-managed VMIs by default", func() {
+			publisher := run(MonitorConfig{},
+				newVMI("dcm-a", "vm-a", managed),
+				newVMI("other", "vm-b", map[string]interface{}{}),
+			)
+
+			Eventually(publisher.statuses).Should(Equal([]string{"vm-a=Running"}))
+			Consistently(publisher.statuses, 200*time.Millisecond).Should(Equal([]string{"vm-a=Running"}))
+		})
+
+		It("should apply a configured selector", func() {
+			gold := map[string]interface{}{constants.DCMLabelManagedBy: constants.DCMManagedByValue, "tier": "gold"}
+			publisher := run(MonitorConfig{LabelSelector: DefaultLabelSelector + ",tier=gold"},
+				newVMI("dcm-a", "vm-a", managed),
+				newVMI("dcm-b", "vm-b", gold),
+			)
+
+			Eventually(publisher.statuses).Should(Equal([]string{"vm-b=Running"}))
+			Consistently(publisher.statuses, 200*time.Millisecond).Should(Equal([]string{"vm-b=Running"}))
+		})
 	})
 
 	Describe("NewMonitorService", func() {
 		It("should create service with correct fields", func() {
 			fakeClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
-			publisher := &events.Publisher{}
+			publisher := &events.NATSPublisher{}
 			config := MonitorConfig{
 				Namespace:    "test-ns",
 				ResyncPeriod: 30 * time.Minute,
@@ -125,6 +505,8 @@ var _ = Describe("Service", func() {
 			Expect(svc.namespace).To(Equal("test-ns"))
 			Expect(svc.publisher).To(Equal(publisher))
 			Expect(svc.resyncPeriod).To(Equal(30 * time.Minute))
+			Expect(svc.workers).To(Equal(1))
+			Expect(svc.published.max).To(Equal(DefaultMaxTrackedVMs))
 			Expect(svc.dynamicClient).To(Equal(fakeClient))
 			Expect(svc.informerFactory).NotTo(BeNil())
 			Expect(svc.vmiInformer).NotTo(BeNil())
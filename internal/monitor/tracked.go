@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// DefaultMaxTrackedVMs bounds the VMs whose last published phase is tracked
+const DefaultMaxTrackedVMs = 10000
+
+// tracked holds a value per VM ID. When it holds more than max VMs, the least
+// recently used one is evicted; its next event is then handled as if nothing
+// was recorded for it. A zero max leaves it unbounded. When size is set, it
+// reports the number of tracked VMs. The zero value is ready to use.
+type tracked[V comparable] struct {
+	mu      sync.Mutex
+	max     int
+	size    prometheus.Gauge
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// trackedPhases holds the last phase published per VM ID
+type trackedPhases = tracked[VMPhase]
+
+// trackedSet holds a set of VM IDs, bounded and evicted like tracked
+type trackedSet struct {
+	tracked[bool]
+}
+
+// set adds a VM to the set
+func (t *trackedSet) set(vmID string) {
+	t.tracked.set(vmID, true)
+}
+
+// has reports whether a VM is in the set, marking it as recently used
+func (t *trackedSet) has(vmID string) bool {
+	return t.get(vmID)
+}
+
+type trackedEntry[V comparable] struct {
+	vmID  string
+	value V
+}
+
+// get returns the value recorded for a VM, or the zero value when none is,
+// marking it as recently used
+func (t *tracked[V]) get(vmID string) V {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[vmID]
+	if !ok {
+		var zero V
+		return zero
+	}
+	t.order.MoveToFront(e)
+	return e.Value.(*trackedEntry[V]).value
+}
+
+// set records the value for a VM, evicting the least recently used VMs beyond
+// the bound
+func (t *tracked[V]) set(vmID string, value V) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.entries == nil {
+		t.entries = map[string]*list.Element{}
+		t.order = list.New()
+	}
+	if e, ok := t.entries[vmID]; ok {
+		e.Value.(*trackedEntry[V]).value = value
+		t.order.MoveToFront(e)
+		return
+	}
+	t.entries[vmID] = t.order.PushFront(&trackedEntry[V]{vmID: vmID, value: value})
+	for t.max > 0 && t.order.Len() > t.max {
+		evicted := t.order.Remove(t.order.Back()).(*trackedEntry[V])
+		delete(t.entries, evicted.vmID)
+		zap.S().Debugw("Evicted tracked VM", "vmID", evicted.vmID, "value", evicted.value)
+	}
+	t.reportSize()
+}
+
+// delete drops the value tracked for a VM
+func (t *tracked[V]) delete(vmID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[vmID]
+	if !ok {
+		return
+	}
+	t.order.Remove(e)
+	delete(t.entries, vmID)
+	t.reportSize()
+}
+
+// reportSize sets the size gauge, if any, to the number of tracked VMs. The
+// caller holds the lock.
+func (t *tracked[V]) reportSize() {
+	if t.size != nil {
+		t.size.Set(float64(t.order.Len()))
+	}
+}
+
+// len returns the number of tracked VMs
+func (t *tracked[V]) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
@@ -0,0 +1,103 @@
+package monitor
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+var _ = Describe("trackedPhases", func() {
+	It("should evict the least recently used VM beyond its bound", func() {
+		tracked := &trackedPhases{max: 2}
+		tracked.set("vm-1", VMPhaseRunning)
+		tracked.set("vm-2", VMPhaseRunning)
+		Expect(tracked.get("vm-1")).To(Equal(VMPhaseRunning))
+
+		tracked.set("vm-3", VMPhaseScheduling)
+
+		Expect(tracked.len()).To(Equal(2))
+		Expect(tracked.get("vm-1")).To(Equal(VMPhaseRunning))
+		Expect(tracked.get("vm-2")).To(BeEmpty())
+		Expect(tracked.get("vm-3")).To(Equal(VMPhaseScheduling))
+	})
+
+	It("should report its size", func() {
+		tracked := &trackedPhases{max: 2, size: trackedVMs}
+		tracked.set("vm-1", VMPhaseRunning)
+		Expect(testutil.ToFloat64(trackedVMs)).To(Equal(1.0))
+
+		tracked.set("vm-2", VMPhaseRunning)
+		tracked.set("vm-3", VMPhaseRunning)
+		Expect(testutil.ToFloat64(trackedVMs)).To(Equal(2.0))
+
+		tracked.delete("vm-3")
+		Expect(testutil.ToFloat64(trackedVMs)).To(Equal(1.0))
+	})
+
+	It("should report only the published phases, not the ready events", func() {
+		publisher := &recordingPublisher{}
+		service := &Service{ctx: context.Background(), publisher: publisher, readyEvents: true}
+		service.published.size = trackedVMs
+
+		service.publishVMEvent(VMInfo{VMID: "vm-1", Phase: VMPhaseScheduling})
+		service.publishVMEvent(VMInfo{VMID: "vm-2", Phase: VMPhaseScheduling})
+		Expect(testutil.ToFloat64(trackedVMs)).To(Equal(2.0))
+
+		running := VMInfo{VMID: "vm-3", Phase: VMPhaseRunning, AgentConnected: true, IPs: []string{"10.244.0.12"}}
+		service.publishReadyEvent(context.Background(), running, &k8sv1.Service{
+			Spec: k8sv1.ServiceSpec{
+				Type:  k8sv1.ServiceTypeNodePort,
+				Ports: []k8sv1.ServicePort{{Port: 22, TargetPort: intstr.FromInt32(22), NodePort: 30022}},
+			},
+		})
+		Expect(service.readyPublished.has("vm-3")).To(BeTrue())
+		Expect(testutil.ToFloat64(trackedVMs)).To(Equal(2.0))
+
+		service.readyPublished.delete("vm-3")
+		Expect(testutil.ToFloat64(trackedVMs)).To(Equal(2.0))
+	})
+
+	It("should stay unbounded without a bound", func() {
+		var tracked trackedPhases
+		for _, id := range []string{"vm-1", "vm-2", "vm-3"} {
+			tracked.set(id, VMPhaseRunning)
+		}
+		Expect(tracked.len()).To(Equal(3))
+	})
+
+	It("should publish the phase of an evicted VM again", func() {
+		publisher := &recordingPublisher{}
+		service := &Service{ctx: context.Background(), publisher: publisher}
+		service.published.max = 1
+
+		service.publishVMEvent(VMInfo{VMID: "vm-1", Phase: VMPhaseRunning})
+		service.publishVMEvent(VMInfo{VMID: "vm-2", Phase: VMPhaseRunning})
+		service.publishVMEvent(VMInfo{VMID: "vm-2", Phase: VMPhaseRunning})
+		service.publishVMEvent(VMInfo{VMID: "vm-1", Phase: VMPhaseRunning})
+
+		Expect(publisher.statuses()).To(Equal([]string{"vm-1=Running", "vm-2=Running", "vm-1=Running"}))
+	})
+})
+
+var _ = Describe("trackedSet", func() {
+	It("should evict the least recently used VM beyond its bound", func() {
+		set := &trackedSet{}
+		set.max = 2
+		set.set("vm-1")
+		set.set("vm-2")
+		Expect(set.has("vm-1")).To(BeTrue())
+
+		set.set("vm-3")
+
+		Expect(set.has("vm-1")).To(BeTrue())
+		Expect(set.has("vm-2")).To(BeFalse())
+		Expect(set.has("vm-3")).To(BeTrue())
+
+		set.delete("vm-3")
+		Expect(set.has("vm-3")).To(BeFalse())
+	})
+})
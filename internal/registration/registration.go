@@ -4,14 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"sync"
 	"time"
 
 	spmv1alpha1 "github.com/dcm-project/service-provider-manager/api/v1alpha1/provider"
 	spmclient "github.com/dcm-project/service-provider-manager/pkg/client/provider"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/dcm-project/kubevirt-service-provider/internal/config"
 )
@@ -35,25 +37,50 @@ func SetMaxBackoff(d time.Duration) Option {
 	}
 }
 
+// SetMaxAttempts gives up registering after n failed attempts. Zero retries
+// until registration succeeds or the context is cancelled.
+func SetMaxAttempts(n int) Option {
+	return func(r *Registrar) {
+		r.maxAttempts = n
+	}
+}
+
+// SetRequestTimeout bounds each registration request. Zero leaves requests
+// bounded only by the context passed to Start.
+func SetRequestTimeout(d time.Duration) Option {
+	return func(r *Registrar) {
+		r.requestTimeout = d
+	}
+}
+
 // Registrar handles registration with the DCM Service Provider Manager
 type Registrar struct {
 	client         *spmclient.ClientWithResponses
 	providerCfg    *config.ProviderConfig
 	initialBackoff time.Duration
 	maxBackoff     time.Duration
+	maxAttempts    int
+	requestTimeout time.Duration
 	startOnce      sync.Once
 	done           chan struct{}
+
+	heartbeatOnce sync.Once
+	mu            sync.Mutex
+	stopRun       context.CancelFunc
+	stopHeartbeat func()
 }
 
-// NewRegistrar creates a new Registrar with the given configuration
+// NewRegistrar creates a new Registrar with the given configuration. Requests
+// time out after the provider's HTTP timeout unless SetRequestTimeout is given.
 func NewRegistrar(providerCfg *config.ProviderConfig, svcMgrCfg *config.ServiceProviderManagerConfig, opts ...Option) (*Registrar, error) {
-	httpClient := &http.Client{
-		Timeout: providerCfg.HTTPTimeout,
+	if err := validateEndpoint(svcMgrCfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("failed to create DCM client: %w", err)
 	}
 
+	// A single client reuses its connections across registrations and heartbeats
 	client, err := spmclient.NewClientWithResponses(
 		svcMgrCfg.Endpoint,
-		spmclient.WithHTTPClient(httpClient),
+		spmclient.WithHTTPClient(&http.Client{Transport: newTransport(svcMgrCfg)}),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DCM client: %w", err)
@@ -64,6 +91,7 @@ func NewRegistrar(providerCfg *config.ProviderConfig, svcMgrCfg *config.ServiceP
 		providerCfg:    providerCfg,
 		initialBackoff: 1 * time.Second,
 		maxBackoff:     60 * time.Second,
+		requestTimeout: providerCfg.HTTPTimeout,
 		done:           make(chan struct{}),
 	}
 	for _, opt := range opts {
@@ -73,12 +101,62 @@ func NewRegistrar(providerCfg *config.ProviderConfig, svcMgrCfg *config.ServiceP
 	return r, nil
 }
 
+// newTransport returns the HTTP transport of the Service Provider Manager
+// client, tuned for connection reuse. Unset values keep the defaults of
+// http.DefaultTransport.
+func newTransport(svcMgrCfg *config.ServiceProviderManagerConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{
+		Timeout:   svcMgrCfg.ConnectTimeout,
+		KeepAlive: svcMgrCfg.KeepAlive,
+	}
+	transport.DialContext = dialer.DialContext
+	if svcMgrCfg.ConnectTimeout > 0 {
+		transport.TLSHandshakeTimeout = svcMgrCfg.ConnectTimeout
+	}
+	if svcMgrCfg.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = svcMgrCfg.IdleConnTimeout
+	}
+	if svcMgrCfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = svcMgrCfg.MaxIdleConns
+		transport.MaxIdleConnsPerHost = svcMgrCfg.MaxIdleConns
+	}
+	return transport
+}
+
+// validateEndpoint checks that the Service Provider Manager endpoint is an
+// absolute http(s) URL that request paths can be appended to
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid service manager endpoint %q: %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid service manager endpoint %q: scheme must be http or https", endpoint)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid service manager endpoint %q: missing host", endpoint)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid service manager endpoint %q: must not have a query or fragment", endpoint)
+	}
+	return nil
+}
+
 // Start begins the registration process in the background.
-// Multiple calls are safe; only the first launches a goroutine.
+// Multiple calls are safe; only the first launches a goroutine, and none is
+// launched once the provider is deregistered. The registration stops with ctx
+// or on Deregister.
 func (r *Registrar) Start(ctx context.Context) {
 	r.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(ctx)
+		r.mu.Lock()
+		r.stopRun = cancel
+		r.mu.Unlock()
+
 		go func() {
 			defer close(r.done)
+			defer cancel()
 			r.run(ctx)
 		}()
 	})
@@ -90,17 +168,64 @@ func (r *Registrar) Done() <-chan struct{} {
 	return r.done
 }
 
+// StartHeartbeat re-registers the provider every interval in the background, so
+// that it is listed again if the Service Provider Manager restarted or expired
+// its entry. Failures are logged and retried on the next tick. Multiple calls
+// are safe; only the first starts the heartbeat, and a non-positive interval
+// disables it. The heartbeat stops with ctx or on Deregister.
+func (r *Registrar) StartHeartbeat(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	r.heartbeatOnce.Do(func() {
+		ctx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		r.mu.Lock()
+		r.stopHeartbeat = func() {
+			cancel()
+			<-done
+		}
+		r.mu.Unlock()
+
+		go func() {
+			defer close(done)
+			r.heartbeat(ctx, interval)
+		}()
+	})
+}
+
+func (r *Registrar) heartbeat(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.register(ctx); err != nil && ctx.Err() == nil {
+			zap.S().Warnw("Heartbeat registration failed", "error", err)
+		}
+	}
+}
+
 func (r *Registrar) run(ctx context.Context) {
 	backoff := r.initialBackoff
 
-	for {
-		if err := r.register(ctx); err == nil {
+	for attempt := 1; ; attempt++ {
+		err := r.register(ctx)
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, errNonRetryable):
+			zap.S().Errorw("Registration failed with non-retryable error, giving up", "error", err)
 			return
-		} else if errors.Is(err, errNonRetryable) {
-			log.Printf("Registration failed with non-retryable error, giving up: %v", err)
+		case r.maxAttempts > 0 && attempt >= r.maxAttempts:
+			zap.S().Errorw("Registration failed, giving up", "attempts", attempt, "error", err)
 			return
-		} else {
-			log.Printf("Registration failed, will retry: %v", err)
+		default:
+			zap.S().Warnw("Registration failed, will retry", "attempt", attempt, "backoff", backoff, "error", err)
 		}
 
 		timer := time.NewTimer(backoff)
@@ -120,6 +245,22 @@ func (r *Registrar) run(ctx context.Context) {
 	}
 }
 
+// providerMetadata returns the location advertised on registration, or nil
+// when neither a region nor a zone is configured
+func (r *Registrar) providerMetadata() *spmv1alpha1.ProviderMetadata {
+	if r.providerCfg.Region == "" && r.providerCfg.Zone == "" {
+		return nil
+	}
+	metadata := &spmv1alpha1.ProviderMetadata{}
+	if r.providerCfg.Region != "" {
+		metadata.RegionCode = &r.providerCfg.Region
+	}
+	if r.providerCfg.Zone != "" {
+		metadata.Zone = &r.providerCfg.Zone
+	}
+	return metadata
+}
+
 func (r *Registrar) register(ctx context.Context) error {
 	providerUUID, err := uuid.Parse(r.providerCfg.ID)
 	if err != nil {
@@ -134,6 +275,17 @@ func (r *Registrar) register(ctx context.Context) error {
 		Endpoint:      r.providerCfg.Endpoint,
 		ServiceType:   r.providerCfg.ServiceType,
 		SchemaVersion: r.providerCfg.SchemaVersion,
+		Metadata:      r.providerMetadata(),
+	}
+	if len(r.providerCfg.Operations) > 0 {
+		operations := r.providerCfg.Operations
+		provider.Operations = &operations
+	}
+
+	if r.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.requestTimeout)
+		defer cancel()
 	}
 
 	resp, err := r.client.CreateProviderWithResponse(ctx, params, provider)
@@ -143,9 +295,9 @@ func (r *Registrar) register(ctx context.Context) error {
 
 	switch resp.StatusCode() {
 	case http.StatusCreated:
-		log.Printf("Registered new provider: %s (ID: %s)", r.providerCfg.Name, *resp.JSON201.Id)
+		zap.S().Infow("Registered new provider", "name", r.providerCfg.Name, "id", *resp.JSON201.Id)
 	case http.StatusOK:
-		log.Printf("Updated existing provider: %s (ID: %s)", r.providerCfg.Name, *resp.JSON200.Id)
+		zap.S().Infow("Updated existing provider", "name", r.providerCfg.Name, "id", *resp.JSON200.Id)
 	case http.StatusConflict:
 		return fmt.Errorf("conflict registering provider: %s: %w", resp.ApplicationproblemJSON409.Title, errNonRetryable)
 	case http.StatusBadRequest:
@@ -160,3 +312,53 @@ func (r *Registrar) register(ctx context.Context) error {
 
 	return nil
 }
+
+// Deregister removes the provider from the Service Provider Manager so that
+// DCM stops routing requests to it, stopping the registration and heartbeat
+// first. A provider that is already gone counts as deregistered.
+func (r *Registrar) Deregister(ctx context.Context) error {
+	// Neither a registration still retrying nor a heartbeat may list the
+	// provider again once it is removed, and neither may start afterwards
+	r.startOnce.Do(func() { close(r.done) })
+	r.heartbeatOnce.Do(func() {})
+	r.mu.Lock()
+	stopRun, stopHeartbeat := r.stopRun, r.stopHeartbeat
+	r.mu.Unlock()
+	if stopRun != nil {
+		stopRun()
+	}
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for registration to stop: %w", ctx.Err())
+	}
+	if stopHeartbeat != nil {
+		stopHeartbeat()
+	}
+
+	providerUUID, err := uuid.Parse(r.providerCfg.ID)
+	if err != nil {
+		return fmt.Errorf("invalid provider ID %q: %w", r.providerCfg.ID, err)
+	}
+
+	if r.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.requestTimeout)
+		defer cancel()
+	}
+
+	resp, err := r.client.DeleteProviderWithResponse(ctx, providerUUID.String())
+	if err != nil {
+		return fmt.Errorf("failed to deregister provider: %w", err)
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusNoContent, http.StatusOK:
+		zap.S().Infow("Deregistered provider", "name", r.providerCfg.Name, "id", providerUUID.String())
+	case http.StatusNotFound:
+		zap.S().Infow("Provider already deregistered", "name", r.providerCfg.Name, "id", providerUUID.String())
+	default:
+		return fmt.Errorf("deregistration returned unexpected status %d", resp.StatusCode())
+	}
+	return nil
+}
@@ -3,6 +3,8 @@ package registration
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -13,6 +15,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	spmv1alpha1 "github.com/dcm-project/service-provider-manager/api/v1alpha1/provider"
+	spmclient "github.com/dcm-project/service-provider-manager/pkg/client/provider"
 
 	"github.com/dcm-project/kubevirt-service-provider/internal/config"
 )
@@ -75,6 +78,88 @@ var _ = Describe("Registrar", func() {
 			Expect(registrar.initialBackoff).To(Equal(100 * time.Millisecond))
 			Expect(registrar.maxBackoff).To(Equal(5 * time.Second))
 		})
+
+		It("should time out requests after the provider HTTP timeout by default", func() {
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: "https://dcm.example.com/api/v1alpha1"}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registrar.requestTimeout).To(Equal(30 * time.Second))
+
+			registrar, err = NewRegistrar(providerCfg, svcMgrCfg, SetRequestTimeout(time.Second))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registrar.requestTimeout).To(Equal(time.Second))
+		})
+
+		It("should reuse connections with the configured transport settings", func() {
+			svcMgrCfg = &config.ServiceProviderManagerConfig{
+				Endpoint:        "https://dcm.example.com/api/v1alpha1",
+				ConnectTimeout:  3 * time.Second,
+				KeepAlive:       15 * time.Second,
+				IdleConnTimeout: time.Minute,
+				MaxIdleConns:    4,
+			}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			// The client requests are sent through
+			spm, ok := registrar.client.ClientInterface.(*spmclient.Client)
+			Expect(ok).To(BeTrue())
+			httpClient, ok := spm.Client.(*http.Client)
+			Expect(ok).To(BeTrue())
+
+			Expect(httpClient.Timeout).To(BeZero())
+			transport, ok := httpClient.Transport.(*http.Transport)
+			Expect(ok).To(BeTrue())
+			Expect(transport.DialContext).NotTo(BeNil())
+			Expect(transport.TLSHandshakeTimeout).To(Equal(3 * time.Second))
+			Expect(transport.IdleConnTimeout).To(Equal(time.Minute))
+			Expect(transport.MaxIdleConns).To(Equal(4))
+			Expect(transport.MaxIdleConnsPerHost).To(Equal(4))
+			Expect(transport.DisableKeepAlives).To(BeFalse())
+		})
+
+		It("should reuse the connection across registrations", func() {
+			var conns int32
+			testServer = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				providerUUID := validUUID
+				json.NewEncoder(w).Encode(spmv1alpha1.Provider{Id: &providerUUID, Name: "test-provider"})
+			}))
+			testServer.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					atomic.AddInt32(&conns, 1)
+				}
+			}
+			testServer.Start()
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL, KeepAlive: 30 * time.Second}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			for range 3 {
+				Expect(registrar.register(context.Background())).To(Succeed())
+			}
+			Expect(atomic.LoadInt32(&conns)).To(Equal(int32(1)))
+		})
+
+		DescribeTable("should reject a malformed service manager endpoint",
+			func(endpoint string) {
+				svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: endpoint}
+
+				registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+				Expect(err).To(MatchError(ContainSubstring("invalid service manager endpoint")))
+				Expect(registrar).To(BeNil())
+			},
+			Entry("empty", ""),
+			Entry("missing scheme", "localhost:8080/api/v1alpha1"),
+			Entry("unsupported scheme", "ftp://localhost:8080"),
+			Entry("missing host", "http:///api/v1alpha1"),
+			Entry("query", "http://localhost:8080/api/v1alpha1?token=x"),
+			Entry("unparsable", "http://local host:8080"),
+		)
 	})
 
 	Describe("Start", func() {
@@ -241,6 +326,80 @@ var _ = Describe("Registrar", func() {
 				Eventually(registrar.Done(), 5*time.Second).Should(BeClosed())
 				Expect(atomic.LoadInt32(&attempts)).To(BeNumerically(">=", int32(3)))
 			})
+
+			It("should retry an unavailable service manager within the attempt limit", func() {
+				var attempts int32
+				testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if atomic.AddInt32(&attempts, 1) <= 2 {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusOK)
+					providerUUID := validUUID
+					json.NewEncoder(w).Encode(spmv1alpha1.Provider{Id: &providerUUID, Name: "test-provider"})
+				}))
+				svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+				registrar, err := NewRegistrar(providerCfg, svcMgrCfg,
+					SetInitialBackoff(10*time.Millisecond),
+					SetMaxAttempts(3),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				registrar.Start(context.Background())
+				Eventually(registrar.Done(), 5*time.Second).Should(BeClosed())
+				Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(3)))
+			})
+
+			It("should give up after the maximum number of attempts", func() {
+				var attempts int32
+				testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					atomic.AddInt32(&attempts, 1)
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}))
+				svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+				registrar, err := NewRegistrar(providerCfg, svcMgrCfg,
+					SetInitialBackoff(10*time.Millisecond),
+					SetMaxAttempts(2),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				registrar.Start(context.Background())
+				Eventually(registrar.Done(), 5*time.Second).Should(BeClosed())
+				Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(2)))
+			})
+
+			It("should time out a hanging request and retry", func() {
+				var attempts int32
+				testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if atomic.AddInt32(&attempts, 1) == 1 {
+						// The server notices the client going away only once the body is read
+						_, _ = io.Copy(io.Discard, r.Body)
+						<-r.Context().Done()
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusCreated)
+					providerUUID := validUUID
+					json.NewEncoder(w).Encode(spmv1alpha1.Provider{Id: &providerUUID, Name: "test-provider"})
+				}))
+
+				svcMgrCfg = &config.ServiceProviderManagerConfig{
+					Endpoint: testServer.URL,
+				}
+
+				registrar, err := NewRegistrar(providerCfg, svcMgrCfg,
+					SetInitialBackoff(10*time.Millisecond),
+					SetRequestTimeout(50*time.Millisecond),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				registrar.Start(context.Background())
+				Eventually(registrar.Done(), 5*time.Second).Should(BeClosed())
+				Expect(atomic.LoadInt32(&attempts)).To(Equal(int32(2)))
+			})
 		})
 
 		Context("when context is cancelled", func() {
@@ -269,6 +428,61 @@ var _ = Describe("Registrar", func() {
 			})
 		})
 
+		Context("when operations and location are configured", func() {
+			It("should advertise them in the registration request", func() {
+				bodies := make(chan map[string]interface{}, 1)
+				testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var body map[string]interface{}
+					Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+					bodies <- body
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusCreated)
+					providerUUID := validUUID
+					json.NewEncoder(w).Encode(spmv1alpha1.Provider{Id: &providerUUID, Name: "test-provider"})
+				}))
+				svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+				providerCfg.Endpoint = "https://kubevirt.example.com/api/v1/vm"
+				providerCfg.Operations = []string{"CREATE", "READ"}
+				providerCfg.Region = "eu-west"
+				providerCfg.Zone = "eu-west-1a"
+
+				registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+				Expect(err).NotTo(HaveOccurred())
+				registrar.Start(context.Background())
+				Eventually(registrar.Done()).Should(BeClosed())
+
+				var body map[string]interface{}
+				Expect(bodies).To(Receive(&body))
+				Expect(body).To(HaveKeyWithValue("endpoint", "https://kubevirt.example.com/api/v1/vm"))
+				Expect(body).To(HaveKeyWithValue("operations", ConsistOf("CREATE", "READ")))
+				Expect(body).To(HaveKeyWithValue("metadata", And(
+					HaveKeyWithValue("region_code", "eu-west"),
+					HaveKeyWithValue("zone", "eu-west-1a"),
+				)))
+			})
+
+			It("should omit them by default", func() {
+				bodies := make(chan map[string]interface{}, 1)
+				testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var body map[string]interface{}
+					Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+					bodies <- body
+					w.WriteHeader(http.StatusUnprocessableEntity)
+				}))
+				svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+				registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+				Expect(err).NotTo(HaveOccurred())
+				registrar.Start(context.Background())
+				Eventually(registrar.Done()).Should(BeClosed())
+
+				var body map[string]interface{}
+				Expect(bodies).To(Receive(&body))
+				Expect(body).NotTo(HaveKey("operations"))
+				Expect(body).NotTo(HaveKey("metadata"))
+			})
+		})
+
 		Context("when Start is called multiple times", func() {
 			It("should only start one registration goroutine", func() {
 				var attempts int32
@@ -301,4 +515,165 @@ var _ = Describe("Registrar", func() {
 			})
 		})
 	})
+
+	Describe("StartHeartbeat", func() {
+		var registrations int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&registrations, 0)
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				// Fail every other registration to check that the heartbeat carries on
+				if atomic.AddInt32(&registrations, 1)%2 == 0 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				providerUUID := validUUID
+				json.NewEncoder(w).Encode(spmv1alpha1.Provider{Id: &providerUUID, Name: "test-provider"})
+			}))
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+		})
+
+		It("should re-register on every tick despite failures", func() {
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			registrar.StartHeartbeat(ctx, 10*time.Millisecond)
+			registrar.StartHeartbeat(ctx, 10*time.Millisecond)
+
+			Eventually(func() int32 { return atomic.LoadInt32(&registrations) }).Should(BeNumerically(">=", 4))
+		})
+
+		It("should stop once the provider is deregistered", func() {
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			registrar.StartHeartbeat(context.Background(), 10*time.Millisecond)
+			Eventually(func() int32 { return atomic.LoadInt32(&registrations) }).Should(BeNumerically(">=", 1))
+			Expect(registrar.Deregister(context.Background())).To(Succeed())
+
+			stopped := atomic.LoadInt32(&registrations)
+			Consistently(func() int32 { return atomic.LoadInt32(&registrations) }, 100*time.Millisecond).Should(Equal(stopped))
+		})
+
+		It("should be disabled by a zero interval", func() {
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			registrar.StartHeartbeat(context.Background(), 0)
+			Consistently(func() int32 { return atomic.LoadInt32(&registrations) }, 50*time.Millisecond).Should(BeZero())
+		})
+	})
+
+	Describe("Deregister", func() {
+		It("should delete the provider", func() {
+			var path string
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				Expect(r.Method).To(Equal(http.MethodDelete))
+				path = r.URL.Path
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registrar.Deregister(context.Background())).To(Succeed())
+			Expect(path).To(Equal("/providers/" + validUUID))
+		})
+
+		It("should treat a provider that is already gone as deregistered", func() {
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(spmv1alpha1.Error{Title: "provider not found"})
+			}))
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registrar.Deregister(context.Background())).To(Succeed())
+		})
+
+		It("should stop a registration that is still retrying before deleting the provider", func() {
+			var registrations, deletions int32
+			registering := make(chan struct{}, 1)
+			release := make(chan struct{})
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					atomic.AddInt32(&deletions, 1)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				atomic.AddInt32(&registrations, 1)
+				select {
+				case registering <- struct{}{}:
+				default:
+				}
+				<-release
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg,
+				SetInitialBackoff(time.Millisecond),
+				SetMaxBackoff(time.Millisecond),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			registrar.Start(context.Background())
+			Eventually(registering).Should(Receive())
+
+			deregistered := make(chan error, 1)
+			go func() {
+				deregistered <- registrar.Deregister(context.Background())
+			}()
+			Eventually(registrar.Done()).Should(BeClosed())
+			close(release)
+			Eventually(deregistered).Should(Receive(BeNil()))
+			Expect(atomic.LoadInt32(&deletions)).To(Equal(int32(1)))
+
+			registered := atomic.LoadInt32(&registrations)
+			Consistently(func() int32 { return atomic.LoadInt32(&registrations) }, 100*time.Millisecond).Should(Equal(registered))
+		})
+
+		It("should not start a registration after deregistering", func() {
+			var registrations int32
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete {
+					atomic.AddInt32(&registrations, 1)
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registrar.Deregister(context.Background())).To(Succeed())
+			registrar.Start(context.Background())
+			registrar.StartHeartbeat(context.Background(), 10*time.Millisecond)
+			Consistently(func() int32 { return atomic.LoadInt32(&registrations) }, 50*time.Millisecond).Should(BeZero())
+		})
+
+		It("should report a failed deregistration", func() {
+			testServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			svcMgrCfg = &config.ServiceProviderManagerConfig{Endpoint: testServer.URL}
+
+			registrar, err := NewRegistrar(providerCfg, svcMgrCfg)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registrar.Deregister(context.Background())).To(MatchError(ContainSubstring("status 500")))
+		})
+	})
 })
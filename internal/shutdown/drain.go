@@ -0,0 +1,48 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Step is a single named stage of an ordered shutdown
+type Step struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// Drain runs the steps sequentially in the given order. All steps share a single
+// deadline of timeout; a failing step is logged and does not prevent later steps
+// from running.
+func Drain(timeout time.Duration, steps ...Step) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, step := range steps {
+		zap.S().Infow("Shutdown: stopping", "step", step.Name)
+		if err := step.Stop(ctx); err != nil {
+			zap.S().Errorw("Shutdown: failed to stop", "step", step.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WaitFor returns a Stop function that calls stop and then waits until done is
+// closed or the shutdown deadline expires.
+func WaitFor(stop func(), done <-chan struct{}) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		stop()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
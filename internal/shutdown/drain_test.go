@@ -0,0 +1,78 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestShutdown(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Shutdown Suite")
+}
+
+var _ = Describe("Drain", func() {
+	It("should run steps in order", func() {
+		var order []string
+		step := func(name string) Step {
+			return Step{Name: name, Stop: func(_ context.Context) error {
+				order = append(order, name)
+				return nil
+			}}
+		}
+
+		err := Drain(time.Second,
+			step("http server"),
+			step("watchers"),
+			step("publisher"),
+		)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(order).To(Equal([]string{"http server", "watchers", "publisher"}))
+	})
+
+	It("should continue after a failing step and report the failure", func() {
+		var ran bool
+		err := Drain(time.Second,
+			Step{Name: "broken", Stop: func(_ context.Context) error {
+				return errors.New("boom")
+			}},
+			Step{Name: "next", Stop: func(_ context.Context) error {
+				ran = true
+				return nil
+			}},
+		)
+
+		Expect(err).To(MatchError(ContainSubstring("broken: boom")))
+		Expect(ran).To(BeTrue())
+	})
+
+	Describe("WaitFor", func() {
+		It("should wait for in-flight work to finish within the timeout", func() {
+			done := make(chan struct{})
+			stop := func() {
+				go func() {
+					time.Sleep(20 * time.Millisecond)
+					close(done)
+				}()
+			}
+
+			err := Drain(time.Second, Step{Name: "server", Stop: WaitFor(stop, done)})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(done).To(BeClosed())
+		})
+
+		It("should give up when the timeout expires", func() {
+			done := make(chan struct{})
+
+			err := Drain(20*time.Millisecond, Step{Name: "stuck", Stop: WaitFor(func() {}, done)})
+
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+	})
+})
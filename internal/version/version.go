@@ -0,0 +1,6 @@
+// Package version reports the version of the provider binary.
+package version
+
+// Version is the provider version, set at build time with
+// -ldflags "-X github.com/dcm-project/kubevirt-service-provider/internal/version.Version=<version>"
+var Version = "dev"